@@ -0,0 +1,145 @@
+package arrival
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ramp is the way the arrival rate increases to its target during the ramp duration
+type Ramp string
+
+const (
+	// NoRamp starts the arrivals at the target rate
+	NoRamp Ramp = ""
+	// Linear increases the rate continuously
+	Linear Ramp = "linear"
+	// Step increases the rate in equal steps
+	Step Ramp = "step"
+)
+
+// Profile describes when users arrive, independently of how long it takes to process them
+type Profile struct {
+	// Rate is the target number of arrivals per second
+	Rate float64
+	// Ramp is how the rate goes from RampFrom to Rate during RampDuration
+	Ramp         Ramp
+	RampFrom     float64
+	RampDuration time.Duration
+	// RampSteps is the number of steps of a Step ramp
+	RampSteps int
+	// Bursts are groups of users that arrive all at once, on top of the ones arriving at the rate
+	Bursts []Burst
+}
+
+// Burst is a group of users that arrive at the same time
+type Burst struct {
+	At    time.Duration
+	Users int
+}
+
+var rateUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseRate parses a rate such as '5/s', '120/m' or '1000/h' and returns it as a number of arrivals per second
+func ParseRate(value string) (float64, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid rate '%s', must be a number of users per s, m or h eg. '5/s'", value)
+	}
+	count, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || count < 0 || math.IsInf(count, 0) {
+		return 0, fmt.Errorf("invalid rate '%s', must be a number of users per s, m or h eg. '5/s'", value)
+	}
+	unit, ok := rateUnits[parts[1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid rate '%s', must be a number of users per s, m or h eg. '5/s'", value)
+	}
+	return count / unit.Seconds(), nil
+}
+
+// ParseBurst parses a burst such as '100@5m', that is 100 users arriving 5 minutes after the start
+func ParseBurst(value string) (Burst, error) {
+	parts := strings.Split(value, "@")
+	if len(parts) != 2 {
+		return Burst{}, fmt.Errorf("invalid burst '%s', must be a number of users and a time eg. '100@5m'", value)
+	}
+	users, err := strconv.Atoi(parts[0])
+	if err != nil || users < 1 {
+		return Burst{}, fmt.Errorf("invalid burst '%s', must be a number of users and a time eg. '100@5m'", value)
+	}
+	at, err := time.ParseDuration(parts[1])
+	if err != nil || at < 0 {
+		return Burst{}, fmt.Errorf("invalid burst '%s', must be a number of users and a time eg. '100@5m'", value)
+	}
+	return Burst{At: at, Users: users}, nil
+}
+
+// Schedule returns the arrival time of each of the given number of users, as offsets from the start of the run.
+// The offsets are sorted, the bursts take their users from the same total.
+func (p Profile) Schedule(users int) []time.Duration {
+	bursts := make([]Burst, len(p.Bursts))
+	copy(bursts, p.Bursts)
+	sort.SliceStable(bursts, func(i, j int) bool {
+		return bursts[i].At < bursts[j].At
+	})
+
+	schedule := make([]time.Duration, 0, users)
+	for arrivals := 0; len(schedule) < users; {
+		next := p.arrivalTime(arrivals)
+		if len(bursts) > 0 && bursts[0].At <= next {
+			for i := 0; i < bursts[0].Users && len(schedule) < users; i++ {
+				schedule = append(schedule, bursts[0].At)
+			}
+			bursts = bursts[1:]
+			continue
+		}
+		schedule = append(schedule, next)
+		arrivals++
+	}
+	return schedule
+}
+
+// arrivalTime returns the time at which the given number of users have arrived at the rate of the profile,
+// ie. the inverse of the cumulative arrival rate
+func (p Profile) arrivalTime(arrivals int) time.Duration {
+	n := float64(arrivals)
+	switch p.Ramp {
+	case Linear:
+		d := p.RampDuration.Seconds()
+		rampArrivals := (p.RampFrom + p.Rate) / 2 * d
+		if n >= rampArrivals {
+			return p.RampDuration + seconds((n-rampArrivals)/p.Rate)
+		}
+		// solve RampFrom*t + a*t^2 = n
+		a := (p.Rate - p.RampFrom) / (2 * d)
+		if a == 0 {
+			return seconds(n / p.RampFrom)
+		}
+		return seconds((-p.RampFrom + math.Sqrt(p.RampFrom*p.RampFrom+4*a*n)) / (2 * a))
+	case Step:
+		stepDuration := p.RampDuration.Seconds() / float64(p.RampSteps)
+		elapsed := 0.0
+		for i := 0; i < p.RampSteps; i++ {
+			rate := p.RampFrom + (p.Rate-p.RampFrom)*float64(i)/float64(p.RampSteps)
+			stepArrivals := rate * stepDuration
+			if n < stepArrivals {
+				return seconds(elapsed + n/rate)
+			}
+			n -= stepArrivals
+			elapsed += stepDuration
+		}
+		return seconds(elapsed + n/p.Rate)
+	}
+	return seconds(n / p.Rate)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
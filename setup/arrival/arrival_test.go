@@ -0,0 +1,155 @@
+package arrival
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		for value, expected := range map[string]float64{
+			"5/s":    5,
+			"0.5/s":  0.5,
+			"120/m":  2,
+			"3600/h": 1,
+			"0/s":    0,
+		} {
+			t.Run(value, func(t *testing.T) {
+				// when
+				rate, err := ParseRate(value)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, expected, rate)
+			})
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		for _, value := range []string{"5", "5/d", "five/s", "-1/s", "5/s/s"} {
+			t.Run(value, func(t *testing.T) {
+				// when
+				_, err := ParseRate(value)
+
+				// then
+				require.EqualError(t, err, "invalid rate '"+value+"', must be a number of users per s, m or h eg. '5/s'")
+			})
+		}
+	})
+}
+
+func TestParseBurst(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// when
+		b, err := ParseBurst("100@5m")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Burst{At: 5 * time.Minute, Users: 100}, b)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		for _, value := range []string{"100", "0@5m", "100@soon", "100@-1m"} {
+			t.Run(value, func(t *testing.T) {
+				// when
+				_, err := ParseBurst(value)
+
+				// then
+				require.EqualError(t, err, "invalid burst '"+value+"', must be a number of users and a time eg. '100@5m'")
+			})
+		}
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	t.Run("constant rate", func(t *testing.T) {
+		// given
+		p := Profile{Rate: 5}
+
+		// when
+		schedule := p.Schedule(4)
+
+		// then
+		assert.Equal(t, []time.Duration{0, 200 * time.Millisecond, 400 * time.Millisecond, 600 * time.Millisecond}, schedule)
+	})
+
+	t.Run("linear ramp", func(t *testing.T) {
+		// given
+		// the rate goes from 0 to 2 users/s in 10s, so t^2/10 users have arrived at time t and 10 users at the end of the ramp
+		p := Profile{Rate: 2, Ramp: Linear, RampDuration: 10 * time.Second}
+
+		// when
+		schedule := p.Schedule(12)
+
+		// then
+		require.Len(t, schedule, 12)
+		assert.Equal(t, time.Duration(0), schedule[0])
+		assert.Equal(t, 3162*time.Millisecond, schedule[1].Round(time.Millisecond)) // sqrt(10)
+		assert.Equal(t, 9487*time.Millisecond, schedule[9].Round(time.Millisecond)) // sqrt(90)
+		assert.Equal(t, 10*time.Second, schedule[10])
+		assert.Equal(t, 10500*time.Millisecond, schedule[11])
+	})
+
+	t.Run("step ramp", func(t *testing.T) {
+		// given
+		// 1 user/s for 5s, then 2 users/s for 5s, then 3 users/s
+		p := Profile{Rate: 3, Ramp: Step, RampFrom: 1, RampDuration: 10 * time.Second, RampSteps: 2}
+
+		// when
+		schedule := p.Schedule(17)
+
+		// then
+		require.Len(t, schedule, 17)
+		assert.Equal(t, 4*time.Second, schedule[4])
+		assert.Equal(t, 5*time.Second, schedule[5])
+		assert.Equal(t, 5500*time.Millisecond, schedule[6])
+		assert.Equal(t, 9500*time.Millisecond, schedule[14])
+		assert.Equal(t, 10*time.Second, schedule[15])
+		assert.Equal(t, 10333*time.Millisecond, schedule[16].Round(time.Millisecond))
+	})
+
+	t.Run("step ramp from zero", func(t *testing.T) {
+		// given
+		p := Profile{Rate: 2, Ramp: Step, RampDuration: 10 * time.Second, RampSteps: 2}
+
+		// when
+		schedule := p.Schedule(2)
+
+		// then
+		// no user arrives during the first step
+		assert.Equal(t, []time.Duration{5 * time.Second, 6 * time.Second}, schedule)
+	})
+
+	t.Run("bursts", func(t *testing.T) {
+		// given
+		p := Profile{Rate: 1, Bursts: []Burst{{At: 10 * time.Second, Users: 5}, {At: 1500 * time.Millisecond, Users: 2}}}
+
+		// when
+		schedule := p.Schedule(7)
+
+		// then
+		assert.Equal(t, []time.Duration{
+			0,
+			time.Second,
+			1500 * time.Millisecond,
+			1500 * time.Millisecond,
+			2 * time.Second,
+			3 * time.Second,
+			4 * time.Second,
+		}, schedule)
+	})
+
+	t.Run("burst larger than the remaining users", func(t *testing.T) {
+		// given
+		p := Profile{Rate: 1, Bursts: []Burst{{At: 0, Users: 100}}}
+
+		// when
+		schedule := p.Schedule(3)
+
+		// then
+		assert.Equal(t, []time.Duration{0, 0, 0}, schedule)
+	})
+}
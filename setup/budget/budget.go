@@ -0,0 +1,276 @@
+package budget
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ExitCode is the exit code of the commands when a result crosses a threshold of the budget, so that CI can tell a
+// regression apart from a failed run
+const ExitCode = 3
+
+// Budget is the set of thresholds that the results of a run must stay within
+type Budget struct {
+	Thresholds []Threshold `yaml:"thresholds"`
+}
+
+// Threshold limits the value of the results of a metric, and how much they may change compared to a baseline run. It
+// applies to the results with the given name or title, aggregate (any aggregate if not set) and labels (any labels if not set).
+type Threshold struct {
+	Metric    string            `yaml:"metric"`
+	Aggregate string            `yaml:"aggregate,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+	// Max and Min are in the unit of the result
+	Max *float64 `yaml:"max,omitempty"`
+	Min *float64 `yaml:"min,omitempty"`
+	// MaxIncrease and MaxDecrease are either relative to the baseline value eg. '15%', or in the unit of the result eg. '50'
+	MaxIncrease string `yaml:"maxIncrease,omitempty"`
+	MaxDecrease string `yaml:"maxDecrease,omitempty"`
+
+	maxIncrease *limit
+	maxDecrease *limit
+}
+
+type limit struct {
+	value    float64
+	relative bool
+}
+
+func (l limit) String() string {
+	if l.relative {
+		return strconv.FormatFloat(l.value, 'f', -1, 64) + "%"
+	}
+	return strconv.FormatFloat(l.value, 'f', -1, 64)
+}
+
+func parseLimit(value string) (*limit, error) {
+	if value == "" {
+		return nil, nil
+	}
+	l := &limit{}
+	number := strings.TrimPrefix(strings.TrimSpace(value), "+")
+	if strings.HasSuffix(number, "%") {
+		l.relative = true
+		number = strings.TrimSpace(strings.TrimSuffix(number, "%"))
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return nil, fmt.Errorf("invalid limit '%s', must be a positive number optionally followed by '%%'", value)
+	}
+	l.value = v
+	return l, nil
+}
+
+// Load reads and validates the budget file at the given path
+func Load(path string) (*Budget, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read budget file '%s'", path)
+	}
+	return Parse(path, content)
+}
+
+// Parse parses and validates the given budget
+func Parse(name string, content []byte) (*Budget, error) {
+	b := &Budget{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(b); err != nil {
+		return nil, fmt.Errorf("invalid budget '%s': %s", name, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	msgs := []string{}
+	var err error
+	for i := range b.Thresholds {
+		t := &b.Thresholds[i]
+		if t.Metric == "" {
+			msgs = append(msgs, fmt.Sprintf("threshold %d: metric is required", i+1))
+			continue
+		}
+		if t.Max == nil && t.Min == nil && t.MaxIncrease == "" && t.MaxDecrease == "" {
+			msgs = append(msgs, fmt.Sprintf("threshold '%s': at least one of max, min, maxIncrease and maxDecrease is required", t.Metric))
+		}
+		if t.Max != nil && t.Min != nil && *t.Min > *t.Max {
+			msgs = append(msgs, fmt.Sprintf("threshold '%s': min must not be greater than max", t.Metric))
+		}
+		if t.maxIncrease, err = parseLimit(t.MaxIncrease); err != nil {
+			msgs = append(msgs, fmt.Sprintf("threshold '%s': maxIncrease: %s", t.Metric, err.Error()))
+		}
+		if t.maxDecrease, err = parseLimit(t.MaxDecrease); err != nil {
+			msgs = append(msgs, fmt.Sprintf("threshold '%s': maxDecrease: %s", t.Metric, err.Error()))
+		}
+	}
+	if len(msgs) > 0 {
+		return nil, fmt.Errorf("invalid budget '%s':\n%s", name, strings.Join(msgs, "\n"))
+	}
+	return b, nil
+}
+
+// Matches returns true if the threshold applies to the given result
+func (t Threshold) Matches(r results.Result) bool {
+	if t.Metric != r.Name && t.Metric != r.Title() {
+		return false
+	}
+	if t.Aggregate != "" && results.Aggregate(t.Aggregate) != r.Aggregate {
+		return false
+	}
+	for name, value := range t.Labels {
+		if r.Labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Violation is a result that changed more than allowed by a threshold of the budget
+type Violation struct {
+	Result   results.Result
+	Baseline results.Result
+	Message  string
+}
+
+// Compare returns the violations of the thresholds that apply to the result, compared to the result of the baseline run
+func (b *Budget) Compare(baseline, current results.Result) []Violation {
+	violations := []Violation{}
+	if b == nil {
+		return violations
+	}
+	delta := current.Value - baseline.Value
+	// both values are shown with the same precision, the results loaded from a JSON file may not have the one of the baseline
+	precision := baseline.Precision
+	if current.Precision > precision {
+		precision = current.Precision
+	}
+	from := strconv.FormatFloat(baseline.Value, 'f', precision, 64)
+	to := strconv.FormatFloat(current.Value, 'f', precision, 64)
+	for _, t := range b.Thresholds {
+		if !t.Matches(current) {
+			continue
+		}
+		if t.maxIncrease != nil && exceeds(*t.maxIncrease, delta, baseline.Value) {
+			violations = append(violations, Violation{
+				Result:   current,
+				Baseline: baseline,
+				Message:  fmt.Sprintf("%s increased from %s to %s, the maximum increase is %s", current.Title(), from, to, t.maxIncrease),
+			})
+		}
+		if t.maxDecrease != nil && exceeds(*t.maxDecrease, -delta, baseline.Value) {
+			violations = append(violations, Violation{
+				Result:   current,
+				Baseline: baseline,
+				Message:  fmt.Sprintf("%s decreased from %s to %s, the maximum decrease is %s", current.Title(), from, to, t.maxDecrease),
+			})
+		}
+	}
+	return violations
+}
+
+// bound is an absolute limit of a threshold, eg. '<= 3'
+type bound struct {
+	operator string
+	value    float64
+}
+
+func (b bound) String() string {
+	return fmt.Sprintf("%s %s", b.operator, strconv.FormatFloat(b.value, 'f', -1, 64))
+}
+
+func (b bound) allows(value float64) bool {
+	if b.operator == "<=" {
+		return value <= b.value
+	}
+	return value >= b.value
+}
+
+func (t Threshold) bounds() []bound {
+	bounds := []bound{}
+	if t.Max != nil {
+		bounds = append(bounds, bound{operator: "<=", value: *t.Max})
+	}
+	if t.Min != nil {
+		bounds = append(bounds, bound{operator: ">=", value: *t.Min})
+	}
+	return bounds
+}
+
+// Check returns the violations of the max and min limits of the thresholds that apply to the result
+func (b *Budget) Check(r results.Result) []Violation {
+	violations := []Violation{}
+	if b == nil {
+		return violations
+	}
+	for _, t := range b.Thresholds {
+		if !t.Matches(r) {
+			continue
+		}
+		for _, l := range t.bounds() {
+			if !l.allows(r.Value) {
+				violations = append(violations, Violation{
+					Result:  r,
+					Message: fmt.Sprintf("%s is %s, it must be %s", r.Title(), r.FormattedValue(), l),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// Verdicts returns a pass or fail result for each max and min limit of the thresholds and each result it applies to.
+// A limit that applies to none of the results fails, since the metric was expected.
+func (b *Budget) Verdicts(rs []results.Result) []results.Result {
+	verdicts := []results.Result{}
+	if b == nil {
+		return verdicts
+	}
+	for _, t := range b.Thresholds {
+		for _, l := range t.bounds() {
+			matched := false
+			for _, r := range rs {
+				if r.Verdict != results.NoVerdict || !t.Matches(r) {
+					continue
+				}
+				matched = true
+				verdict := results.Pass
+				if !l.allows(r.Value) {
+					verdict = results.Fail
+				}
+				verdicts = append(verdicts, results.Result{
+					Name:      fmt.Sprintf("Budget: %s %s", r.Title(), l),
+					Value:     r.Value,
+					Phase:     r.Phase,
+					Precision: r.Precision,
+					Verdict:   verdict,
+				})
+			}
+			if !matched {
+				name := t.Metric
+				if t.Aggregate != "" {
+					name = fmt.Sprintf("%s %s", name, t.Aggregate)
+				}
+				verdicts = append(verdicts, results.Result{Name: fmt.Sprintf("Budget: %s %s (no result)", name, l), Verdict: results.Fail})
+			}
+		}
+	}
+	return verdicts
+}
+
+// exceeds returns true if the change is over the limit, a relative limit is always exceeded by any change from a zero baseline
+func exceeds(l limit, change, baseline float64) bool {
+	if change <= 0 {
+		return false
+	}
+	if !l.relative {
+		return change > l.value
+	}
+	if baseline == 0 {
+		return true
+	}
+	return change/math.Abs(baseline)*100 > l.value
+}
@@ -0,0 +1,202 @@
+package budget
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("example budget", func(t *testing.T) {
+		// when
+		b, err := Load("budget.yaml")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, b.Thresholds, 7)
+		assert.Equal(t, "Average Time Per User - default (s)", b.Thresholds[0].Metric)
+		require.NotNil(t, b.Thresholds[0].Max)
+		assert.Equal(t, 3.0, *b.Thresholds[0].Max)
+		assert.Equal(t, "host-operator-controller-manager Memory Usage", b.Thresholds[2].Metric)
+		assert.Equal(t, &limit{value: 15, relative: true}, b.Thresholds[2].maxIncrease)
+		assert.Equal(t, &limit{value: 10}, b.Thresholds[5].maxIncrease)
+		assert.Equal(t, &limit{value: 5, relative: true}, b.Thresholds[6].maxDecrease)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		// when
+		_, err := Load("not-found.yaml")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to read budget file 'not-found.yaml'")
+	})
+
+	t.Run("invalid thresholds", func(t *testing.T) {
+		// given
+		content := `thresholds:
+- maxIncrease: 10%
+- metric: Running Time
+- metric: host-operator-controller-manager Memory Usage
+  maxIncrease: ten
+  maxDecrease: -5%
+- metric: Number of Users
+  min: 10
+  max: 5
+`
+
+		// when
+		_, err := Parse("budget.yaml", []byte(content))
+
+		// then
+		require.EqualError(t, err, `invalid budget 'budget.yaml':
+threshold 1: metric is required
+threshold 'Running Time': at least one of max, min, maxIncrease and maxDecrease is required
+threshold 'host-operator-controller-manager Memory Usage': maxIncrease: invalid limit 'ten', must be a positive number optionally followed by '%'
+threshold 'host-operator-controller-manager Memory Usage': maxDecrease: invalid limit '-5%', must be a positive number optionally followed by '%'
+threshold 'Number of Users': min must not be greater than max`)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		// when
+		_, err := Parse("budget.yaml", []byte("thresholds:\n- metric: Running Time\n  maximum: 10\n"))
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "field maximum not found")
+	})
+}
+
+func TestCompare(t *testing.T) {
+	// given
+	b, err := Parse("budget.yaml", []byte(`thresholds:
+- metric: host-operator Memory Usage
+  aggregate: max
+  maxIncrease: +15%
+- metric: etcd Instance Memory Usage
+  labels:
+    pod: etcd-1
+  maxIncrease: 100
+- metric: Achieved Signup Rate
+  maxDecrease: 5%
+`))
+	require.NoError(t, err)
+	memory := func(aggregate results.Aggregate, value float64) results.Result {
+		return results.Result{Name: "host-operator Memory Usage", Unit: results.Megabytes, Aggregate: aggregate, Value: value, Precision: 2}
+	}
+	etcd := func(pod string, value float64) results.Result {
+		return results.Result{Name: "etcd Instance Memory Usage", Unit: results.Megabytes, Aggregate: results.Average, Value: value, Labels: map[string]string{"pod": pod}, Precision: 2}
+	}
+	rate := func(value float64) results.Result {
+		return results.Result{Name: "Achieved Signup Rate", Unit: results.UsersPerSecond, Value: value, Precision: 2}
+	}
+
+	t.Run("relative increase", func(t *testing.T) {
+		// when
+		violations := b.Compare(memory(results.Max, 100), memory(results.Max, 120))
+
+		// then
+		require.Len(t, violations, 1)
+		assert.Equal(t, "Max host-operator Memory Usage (MB) increased from 100.00 to 120.00, the maximum increase is 15%", violations[0].Message)
+		assert.Empty(t, b.Compare(memory(results.Max, 100), memory(results.Max, 115)))
+		assert.Empty(t, b.Compare(memory(results.Max, 100), memory(results.Max, 50)))
+	})
+
+	t.Run("other aggregate", func(t *testing.T) {
+		// when
+		violations := b.Compare(memory(results.Average, 100), memory(results.Average, 200))
+
+		// then
+		assert.Empty(t, violations)
+	})
+
+	t.Run("absolute increase with labels", func(t *testing.T) {
+		// when
+		violations := b.Compare(etcd("etcd-1", 500), etcd("etcd-1", 650))
+
+		// then
+		require.Len(t, violations, 1)
+		assert.Equal(t, `Average etcd Instance Memory Usage {pod="etcd-1"} (MB) increased from 500.00 to 650.00, the maximum increase is 100`, violations[0].Message)
+		assert.Empty(t, b.Compare(etcd("etcd-2", 500), etcd("etcd-2", 650)))
+	})
+
+	t.Run("relative decrease", func(t *testing.T) {
+		// when
+		violations := b.Compare(rate(5), rate(4))
+
+		// then
+		require.Len(t, violations, 1)
+		assert.Equal(t, "Achieved Signup Rate (users/s) decreased from 5.00 to 4.00, the maximum decrease is 5%", violations[0].Message)
+		assert.Empty(t, b.Compare(rate(5), rate(6)))
+	})
+
+	t.Run("zero baseline", func(t *testing.T) {
+		// when
+		violations := b.Compare(memory(results.Max, 0), memory(results.Max, 1))
+
+		// then
+		assert.Len(t, violations, 1)
+	})
+
+	t.Run("no budget", func(t *testing.T) {
+		// given
+		var none *Budget
+
+		// when
+		violations := none.Compare(memory(results.Max, 100), memory(results.Max, 200))
+
+		// then
+		assert.Empty(t, violations)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	// given
+	b, err := Parse("budget.yaml", []byte(`thresholds:
+- metric: Average Time Per User - default (s)
+  max: 3
+- metric: Achieved Signup Rate
+  min: 4.5
+- metric: etcd Instance Memory Usage
+  aggregate: max
+  max: 6000
+`))
+	require.NoError(t, err)
+	timePerUser := results.Result{Name: "Time Per User - default", Unit: results.Seconds, Aggregate: results.Average, Value: 3.5, Phase: "default", Precision: 2}
+	rate := results.Result{Name: "Achieved Signup Rate", Unit: results.UsersPerSecond, Value: 5, Precision: 2}
+
+	t.Run("violations", func(t *testing.T) {
+		// when
+		violations := b.Check(timePerUser)
+
+		// then
+		require.Len(t, violations, 1)
+		assert.Equal(t, "Average Time Per User - default (s) is 3.50, it must be <= 3", violations[0].Message)
+		assert.Empty(t, b.Check(rate))
+	})
+
+	t.Run("verdicts", func(t *testing.T) {
+		// when
+		verdicts := b.Verdicts([]results.Result{timePerUser, rate})
+
+		// then
+		assert.Equal(t, []results.Result{
+			{Name: "Budget: Average Time Per User - default (s) <= 3", Value: 3.5, Phase: "default", Precision: 2, Verdict: results.Fail},
+			{Name: "Budget: Achieved Signup Rate (users/s) >= 4.5", Value: 5, Precision: 2, Verdict: results.Pass},
+			{Name: "Budget: etcd Instance Memory Usage max <= 6000 (no result)", Verdict: results.Fail},
+		}, verdicts)
+	})
+
+	t.Run("no budget", func(t *testing.T) {
+		// given
+		var none *Budget
+
+		// when
+		verdicts := none.Verdicts([]results.Result{timePerUser})
+
+		// then
+		assert.Empty(t, verdicts)
+	})
+}
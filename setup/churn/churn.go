@@ -0,0 +1,236 @@
+package churn
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// replenishInterval is how often new users are signed up to keep the population at its size
+var replenishInterval = time.Second
+
+// Config declares how the users churn
+type Config struct {
+	// Duration is how long the operations are started
+	Duration time.Duration
+	// Population is the number of users that are kept signed up, active or deactivated, by signing up new users
+	Population int
+	// Concurrency is the maximum number of operations in flight
+	Concurrency int
+	// Rates are the number of operations started per second
+	Rates map[Operation]float64
+}
+
+// DoFunc applies the operation to the given user and waits until it has converged
+type DoFunc func(ctx context.Context, op Operation, username string) (Convergence, error)
+
+// Engine starts the lifecycle operations of the users at their rates, and signs up new users to replace the ones that are
+// banned or deleted. The users that have been in their state the longest are picked first.
+type Engine struct {
+	config      Config
+	do          DoFunc
+	newUsername func(n int) string
+	stats       *Stats
+
+	mu          sync.Mutex
+	active      []string
+	deactivated []string
+	// population is the number of users that are neither banned nor deleted, including the ones being signed up
+	population int
+	// next is the number of the next new user
+	next int
+}
+
+// New returns an engine for the given active users, the new users are named with the given function from 1
+func New(config Config, active []string, newUsername func(n int) string, do DoFunc) *Engine {
+	return &Engine{
+		config:      config,
+		do:          do,
+		newUsername: newUsername,
+		stats:       newStats(),
+		active:      append([]string{}, active...),
+		population:  len(active),
+		next:        1,
+	}
+}
+
+// Stats returns the statistics of the operations
+func (e *Engine) Stats() *Stats {
+	return e.stats
+}
+
+// Run starts the operations until the duration of the churn is elapsed or until stop is closed, and then waits for the
+// operations in flight, which are given up when the context is done
+func (e *Engine) Run(ctx context.Context, stop <-chan struct{}) {
+	e.stats.start()
+	defer e.stats.end()
+
+	done := make(chan struct{})
+	go func() {
+		deadline := time.NewTimer(e.config.Duration)
+		defer deadline.Stop()
+		select {
+		case <-deadline.C:
+		case <-stop:
+		}
+		close(done)
+	}()
+
+	slots := make(chan struct{}, e.config.Concurrency)
+	var inFlight sync.WaitGroup
+	// start waits for a free slot and applies the operation in the background, it returns false once the churn is over
+	start := func(op Operation, username string) bool {
+		select {
+		case slots <- struct{}{}:
+		case <-done:
+			e.release(op, username)
+			return false
+		}
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+			defer func() { <-slots }()
+			c, err := e.do(ctx, op, username)
+			if err != nil && ctx.Err() != nil {
+				// the operation was given up, the state of the user is unknown
+				e.drop(op)
+				return
+			}
+			e.completed(op, username, c, err)
+		}()
+		return true
+	}
+
+	var starters sync.WaitGroup
+	for _, op := range Operations {
+		rate := e.config.Rates[op]
+		if rate <= 0 {
+			continue
+		}
+		starters.Add(1)
+		go func(op Operation, interval time.Duration) {
+			defer starters.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+				case <-done:
+					return
+				}
+				username, ok := e.pick(op)
+				if !ok {
+					e.stats.skip(op)
+					continue
+				}
+				if !start(op, username) {
+					return
+				}
+			}
+		}(op, time.Duration(float64(time.Second)/rate))
+	}
+	starters.Add(1)
+	go func() {
+		defer starters.Done()
+		ticker := time.NewTicker(replenishInterval)
+		defer ticker.Stop()
+		for {
+			for {
+				username, ok := e.signup()
+				if !ok {
+					break
+				}
+				if !start(Signup, username) {
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	starters.Wait()
+	inFlight.Wait()
+}
+
+// pick takes the user that has been in the source state of the operation the longest
+func (e *Engine) pick(op Operation) (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	source := &e.active
+	if op == Reactivate {
+		source = &e.deactivated
+	}
+	if len(*source) == 0 {
+		return "", false
+	}
+	username := (*source)[0]
+	*source = (*source)[1:]
+	// the banned and deleted users leave the population right away so that they are replaced without waiting for them to converge
+	if op == Ban || op == Delete {
+		e.population--
+	}
+	e.stats.observePopulation(e.population)
+	return username, true
+}
+
+// signup returns the name of a new user if the population is below its size
+func (e *Engine) signup() (string, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.population >= e.config.Population {
+		return "", false
+	}
+	e.population++
+	username := e.newUsername(e.next)
+	e.next++
+	return username, true
+}
+
+// release puts back the user of an operation that was not started
+func (e *Engine) release(op Operation, username string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch op {
+	case Signup:
+		e.population--
+	case Reactivate:
+		e.deactivated = append([]string{username}, e.deactivated...)
+	case Ban, Delete:
+		e.population++
+		e.active = append([]string{username}, e.active...)
+	default:
+		e.active = append([]string{username}, e.active...)
+	}
+}
+
+// drop removes a user whose state is unknown from the population, unless it already left it when it was picked
+func (e *Engine) drop(op Operation) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if op != Ban && op != Delete {
+		e.population--
+	}
+}
+
+// completed moves the user to the target state of the operation, a user whose operation failed is left out of the churn
+func (e *Engine) completed(op Operation, username string, c Convergence, err error) {
+	e.stats.record(op, c, err)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch {
+	case err != nil:
+		// the banned and deleted users already left the population when they were picked
+		if op != Ban && op != Delete {
+			e.population--
+		}
+	case op == Signup || op == Reactivate:
+		e.active = append(e.active, username)
+	case op == Deactivate:
+		e.deactivated = append(e.deactivated, username)
+	}
+	e.stats.observePopulation(e.population)
+}
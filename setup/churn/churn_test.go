@@ -0,0 +1,153 @@
+package churn
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine(t *testing.T) {
+	// given
+	replenishInterval = 10 * time.Millisecond
+	initial := []string{"zippy-0001", "zippy-0002", "zippy-0003", "zippy-0004", "zippy-0005"}
+	newUsername := func(n int) string {
+		return fmt.Sprintf("zippy-churn-%04d", n)
+	}
+
+	// recorder is an operation that keeps track of the operations applied to each user
+	type recorder struct {
+		mu    sync.Mutex
+		ops   map[string][]Operation
+		fails Operation
+	}
+	newRecorder := func(fails Operation) *recorder {
+		return &recorder{ops: map[string][]Operation{}, fails: fails}
+	}
+	do := func(r *recorder) DoFunc {
+		return func(ctx context.Context, op Operation, username string) (Convergence, error) {
+			r.mu.Lock()
+			r.ops[username] = append(r.ops[username], op)
+			r.mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			if op == r.fails {
+				return Convergence{}, fmt.Errorf("%s of user '%s' did not converge", op, username)
+			}
+			return Convergence{Host: time.Millisecond, Member: 2 * time.Millisecond}, nil
+		}
+	}
+	byName := func(rs []results.Result, name string) map[string]float64 {
+		values := map[string]float64{}
+		for _, r := range rs {
+			if r.Name == name {
+				values[r.Labels["operation"]] = r.Value
+			}
+		}
+		return values
+	}
+
+	t.Run("operations keep the population", func(t *testing.T) {
+		// given
+		r := newRecorder("")
+		e := New(Config{
+			Duration:    300 * time.Millisecond,
+			Population:  5,
+			Concurrency: 4,
+			Rates:       map[Operation]float64{Deactivate: 40, Reactivate: 40, Ban: 10, Delete: 10},
+		}, initial, newUsername, do(r))
+
+		// when
+		e.Run(context.TODO(), make(chan struct{}))
+
+		// then
+		rs := e.Stats().Results("soak")
+		operations := byName(rs, "Churn Operations")
+		for _, op := range []Operation{Signup, Deactivate, Reactivate, Ban, Delete} {
+			assert.Greater(t, operations[string(op)], 0.0, op)
+		}
+		assert.Equal(t, map[string]float64{"signup": 0, "deactivate": 0, "reactivate": 0, "ban": 0, "delete": 0}, byName(rs, "Churn Failures"))
+		// the banned and deleted users are replaced by new users, except the last ones if the churn ended in the meantime
+		assert.LessOrEqual(t, operations["signup"], operations["ban"]+operations["delete"])
+		for username, ops := range r.ops {
+			for i, op := range ops {
+				if op == Ban || op == Delete {
+					assert.Len(t, ops, i+1, "user '%s' had operations after %s: %v", username, op, ops)
+				}
+				if op == Reactivate {
+					assert.Equal(t, Deactivate, ops[i-1], "user '%s' was reactivated without being deactivated: %v", username, ops)
+				}
+			}
+		}
+		for _, r := range rs {
+			if r.Name == "Churn Population" {
+				if r.Aggregate == results.Max {
+					assert.Equal(t, 5.0, r.Value)
+				} else {
+					assert.GreaterOrEqual(t, r.Value, 1.0)
+				}
+			}
+		}
+		assert.Contains(t, titles(rs), `Average Churn Convergence Time {cluster="member", operation="deactivate"} (s)`)
+	})
+
+	t.Run("failed users are replaced", func(t *testing.T) {
+		// given
+		r := newRecorder(Deactivate)
+		e := New(Config{
+			Duration:    200 * time.Millisecond,
+			Population:  5,
+			Concurrency: 4,
+			Rates:       map[Operation]float64{Deactivate: 40, Reactivate: 40},
+		}, initial, newUsername, do(r))
+
+		// when
+		e.Run(context.TODO(), make(chan struct{}))
+
+		// then
+		rs := e.Stats().Results("soak")
+		failures := byName(rs, "Churn Failures")
+		assert.Greater(t, failures["deactivate"], 0.0)
+		signups := byName(rs, "Churn Operations")["signup"]
+		assert.Greater(t, signups, 0.0)
+		assert.LessOrEqual(t, signups, failures["deactivate"])
+		// no user was ever deactivated
+		assert.Greater(t, byName(rs, "Churn Skipped Operations")["reactivate"], 0.0)
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		// given
+		r := newRecorder("")
+		e := New(Config{
+			Duration:    time.Hour,
+			Population:  10,
+			Concurrency: 4,
+			Rates:       map[Operation]float64{Deactivate: 10},
+		}, initial, newUsername, do(r))
+		stop := make(chan struct{})
+		time.AfterFunc(100*time.Millisecond, func() { close(stop) })
+
+		// when
+		started := time.Now()
+		e.Run(context.TODO(), stop)
+
+		// then
+		assert.Less(t, time.Since(started), time.Second)
+		// the population grew to its size
+		assert.Equal(t, 5.0, byName(e.Stats().Results("soak"), "Churn Operations")["signup"])
+		require.Contains(t, r.ops, "zippy-churn-0005")
+	})
+}
+
+func titles(rs []results.Result) []string {
+	ts := make([]string, 0, len(rs))
+	for _, r := range rs {
+		ts = append(ts, r.Title())
+	}
+	return ts
+}
@@ -0,0 +1,225 @@
+package churn
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/condition"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/tiers"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/users"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8swait "k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Operation is a transition of the lifecycle of a user
+type Operation string
+
+const (
+	// Signup signs up a new user
+	Signup Operation = "signup"
+	// Deactivate deactivates an active user
+	Deactivate Operation = "deactivate"
+	// Reactivate reactivates a deactivated user
+	Reactivate Operation = "reactivate"
+	// Ban bans an active user with a BannedUser
+	Ban Operation = "ban"
+	// Delete deletes the UserSignup of an active user
+	Delete Operation = "delete"
+)
+
+// Operations are the operations that are started at a given rate, the signups are started to keep the population at its size
+var Operations = []Operation{Deactivate, Reactivate, Ban, Delete}
+
+// Convergence is how long it took for an operation to be reflected on the host cluster and on the member clusters
+type Convergence struct {
+	Host   time.Duration
+	Member time.Duration
+}
+
+// Lifecycle applies the operations to the users of the cluster
+type Lifecycle struct {
+	Client                   client.Client
+	HostOperatorNamespace    string
+	MemberOperatorNamespaces []string
+	// TargetCluster returns the member cluster of each new user, the host operator picks it when it is empty
+	TargetCluster func() string
+	// Tier returns the tier of the Space of each new user. It is nil when the host operator creates the Spaces in the default space
+	// tier, otherwise the lifecycle creates the Spaces of the new users and of the reactivated users in their tiers.
+	Tier func() string
+
+	mu sync.Mutex
+	// deactivated are the Spaces of the deactivated users, so that they are created again in the same tier on reactivation
+	deactivated map[string]placement
+}
+
+// placement is where the Space of a user was provisioned
+type placement struct {
+	tier          string
+	targetCluster string
+}
+
+// Do applies the operation to the given user and waits until it has converged on the host and the member clusters,
+// or until the context is done
+func (l *Lifecycle) Do(ctx context.Context, op Operation, username string) (Convergence, error) {
+	started := time.Now()
+	if err := l.apply(ctx, op, username); err != nil {
+		return Convergence{}, err
+	}
+	c := Convergence{}
+	hostConverged, memberConverged := false, false
+	err := k8swait.PollWithContext(ctx, configuration.DefaultRetryInterval, configuration.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		var err error
+		if !hostConverged {
+			if hostConverged, err = l.hostConverged(ctx, op, username); err != nil {
+				return false, err
+			}
+			c.Host = time.Since(started)
+		}
+		if !memberConverged {
+			if memberConverged, err = l.memberConverged(ctx, op, username); err != nil {
+				return false, err
+			}
+			c.Member = time.Since(started)
+		}
+		return hostConverged && memberConverged, nil
+	})
+	if err != nil {
+		return c, errors.Wrapf(err, "%s of user '%s' did not converge (host: %t, member: %t)", op, username, hostConverged, memberConverged)
+	}
+	return c, nil
+}
+
+func (l *Lifecycle) apply(ctx context.Context, op Operation, username string) error {
+	switch op {
+	case Signup:
+		if l.Tier == nil {
+			return errors.Wrapf(users.Create(ctx, l.Client, username, l.HostOperatorNamespace, l.TargetCluster()), "unable to sign up user '%s'", username)
+		}
+		targetCluster := l.TargetCluster()
+		if err := users.CreateWithoutSpace(ctx, l.Client, username, l.HostOperatorNamespace, targetCluster); err != nil {
+			return errors.Wrapf(err, "unable to sign up user '%s'", username)
+		}
+		return tiers.CreateSpace(ctx, l.Client, username, l.HostOperatorNamespace, l.Tier(), targetCluster)
+	case Deactivate:
+		if l.Tier != nil {
+			if err := l.recordPlacement(ctx, username); err != nil {
+				return err
+			}
+		}
+		return users.Deactivate(ctx, l.Client, username, l.HostOperatorNamespace)
+	case Reactivate:
+		if err := users.Reactivate(ctx, l.Client, username, l.HostOperatorNamespace); err != nil || l.Tier == nil {
+			return err
+		}
+		l.mu.Lock()
+		p, ok := l.deactivated[username]
+		delete(l.deactivated, username)
+		l.mu.Unlock()
+		if !ok {
+			return errors.Errorf("unable to reactivate user '%s', the tier of its space is unknown", username)
+		}
+		return tiers.CreateSpace(ctx, l.Client, username, l.HostOperatorNamespace, p.tier, p.targetCluster)
+	case Ban:
+		return users.Ban(ctx, l.Client, username, l.HostOperatorNamespace)
+	case Delete:
+		return errors.Wrapf(users.Delete(ctx, l.Client, username, l.HostOperatorNamespace), "unable to delete usersignup '%s'", username)
+	default:
+		return errors.Errorf("unknown operation '%s'", op)
+	}
+}
+
+// recordPlacement records the tier and the member cluster of the Space of the given user before it is deactivated
+func (l *Lifecycle) recordPlacement(ctx context.Context, username string) error {
+	space := &toolchainv1alpha1.Space{}
+	if err := l.Client.Get(ctx, types.NamespacedName{Namespace: l.HostOperatorNamespace, Name: username}, space); err != nil {
+		return errors.Wrapf(err, "unable to get space '%s'", username)
+	}
+	targetCluster := space.Spec.TargetCluster
+	if targetCluster == "" {
+		targetCluster = space.Status.TargetCluster
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.deactivated == nil {
+		l.deactivated = map[string]placement{}
+	}
+	l.deactivated[username] = placement{tier: space.Spec.TierName, targetCluster: targetCluster}
+	return nil
+}
+
+// hostConverged returns true once the Space of the user is provisioned, or once the user is deprovisioned and its UserSignup
+// reflects the operation
+func (l *Lifecycle) hostConverged(ctx context.Context, op Operation, username string) (bool, error) {
+	switch op {
+	case Signup, Reactivate:
+		space := &toolchainv1alpha1.Space{}
+		if err := l.Client.Get(ctx, types.NamespacedName{Namespace: l.HostOperatorNamespace, Name: username}, space); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return condition.IsTrueWithReason(space.Status.Conditions, toolchainv1alpha1.ConditionReady, toolchainv1alpha1.SpaceProvisionedReason), nil
+	case Deactivate, Ban:
+		reason := toolchainv1alpha1.UserSignupUserDeactivatedReason
+		if op == Ban {
+			reason = toolchainv1alpha1.UserSignupUserBannedReason
+		}
+		usersignup := &toolchainv1alpha1.UserSignup{}
+		if err := l.Client.Get(ctx, types.NamespacedName{Namespace: l.HostOperatorNamespace, Name: username}, usersignup); err != nil {
+			return false, err
+		}
+		if !condition.IsTrueWithReason(usersignup.Status.Conditions, toolchainv1alpha1.UserSignupComplete, reason) {
+			return false, nil
+		}
+		return l.notFound(ctx, &toolchainv1alpha1.Space{}, l.HostOperatorNamespace, username)
+	default:
+		if gone, err := l.notFound(ctx, &toolchainv1alpha1.UserSignup{}, l.HostOperatorNamespace, username); err != nil || !gone {
+			return false, err
+		}
+		return l.notFound(ctx, &toolchainv1alpha1.Space{}, l.HostOperatorNamespace, username)
+	}
+}
+
+// memberConverged returns true once the UserAccount and the NSTemplateSet of the user are ready on a member cluster, or once
+// they are gone from all the member clusters
+func (l *Lifecycle) memberConverged(ctx context.Context, op Operation, username string) (bool, error) {
+	for _, ns := range l.MemberOperatorNamespaces {
+		switch op {
+		case Signup, Reactivate:
+			useraccount := &toolchainv1alpha1.UserAccount{}
+			if err := l.Client.Get(ctx, types.NamespacedName{Namespace: ns, Name: username}, useraccount); err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				return false, err
+			}
+			nsTemplateSet := &toolchainv1alpha1.NSTemplateSet{}
+			if err := l.Client.Get(ctx, types.NamespacedName{Namespace: ns, Name: username}, nsTemplateSet); err != nil {
+				return false, client.IgnoreNotFound(err)
+			}
+			return condition.IsTrue(useraccount.Status.Conditions, toolchainv1alpha1.ConditionReady) &&
+				condition.IsTrue(nsTemplateSet.Status.Conditions, toolchainv1alpha1.ConditionReady), nil
+		default:
+			for _, obj := range []client.Object{&toolchainv1alpha1.UserAccount{}, &toolchainv1alpha1.NSTemplateSet{}} {
+				if gone, err := l.notFound(ctx, obj, ns, username); err != nil || !gone {
+					return false, err
+				}
+			}
+		}
+	}
+	// the user is not provisioned on any member cluster yet, or it is gone from all of them
+	return op != Signup && op != Reactivate, nil
+}
+
+func (l *Lifecycle) notFound(ctx context.Context, obj client.Object, namespace, name string) (bool, error) {
+	err := l.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
+	if k8serrors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, err
+}
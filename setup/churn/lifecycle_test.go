@@ -0,0 +1,231 @@
+package churn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/states"
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestLifecycle(t *testing.T) {
+	// given
+	configuration.DefaultRetryInterval = 10 * time.Millisecond
+	configuration.DefaultTimeout = 500 * time.Millisecond
+	hostNS := "toolchain-host-operator"
+	member1NS := "toolchain-member-operator"
+	member2NS := "toolchain-member2-operator"
+	lifecycle := func(cl client.Client) *Lifecycle {
+		return &Lifecycle{
+			Client:                   cl,
+			HostOperatorNamespace:    hostNS,
+			MemberOperatorNamespaces: []string{member1NS, member2NS},
+			TargetCluster: func() string {
+				return "member-2"
+			},
+		}
+	}
+	ready := []toolchainv1alpha1.Condition{{Type: toolchainv1alpha1.ConditionReady, Status: corev1.ConditionTrue, Reason: toolchainv1alpha1.SpaceProvisionedReason}}
+	provisioned := func(username string) []runtime.Object {
+		return []runtime.Object{
+			&toolchainv1alpha1.Space{
+				ObjectMeta: metav1.ObjectMeta{Namespace: hostNS, Name: username},
+				Status:     toolchainv1alpha1.SpaceStatus{Conditions: ready},
+			},
+			&toolchainv1alpha1.UserAccount{
+				ObjectMeta: metav1.ObjectMeta{Namespace: member2NS, Name: username},
+				Status:     toolchainv1alpha1.UserAccountStatus{Conditions: ready},
+			},
+			&toolchainv1alpha1.NSTemplateSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: member2NS, Name: username},
+				Status:     toolchainv1alpha1.NSTemplateSetStatus{Conditions: ready},
+			},
+		}
+	}
+	usersignup := func(username, completeReason string) *toolchainv1alpha1.UserSignup {
+		return &toolchainv1alpha1.UserSignup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hostNS, Name: username},
+			Status: toolchainv1alpha1.UserSignupStatus{
+				Conditions: []toolchainv1alpha1.Condition{{Type: toolchainv1alpha1.UserSignupComplete, Status: corev1.ConditionTrue, Reason: completeReason}},
+			},
+		}
+	}
+
+	t.Run("signup", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, provisioned("zippy-churn-0001")...)
+
+		// when
+		c, err := lifecycle(cl).Do(context.TODO(), Signup, "zippy-churn-0001")
+
+		// then
+		require.NoError(t, err)
+		assert.Less(t, c.Host, time.Second)
+		signup := &toolchainv1alpha1.UserSignup{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostNS, Name: "zippy-churn-0001"}, signup))
+		assert.Equal(t, "member-2", signup.Spec.TargetCluster)
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, usersignup("zippy-0001", toolchainv1alpha1.UserSignupUserDeactivatedReason))
+
+		// when
+		_, err := lifecycle(cl).Do(context.TODO(), Deactivate, "zippy-0001")
+
+		// then
+		require.NoError(t, err)
+		signup := &toolchainv1alpha1.UserSignup{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostNS, Name: "zippy-0001"}, signup))
+		assert.True(t, states.Deactivated(signup))
+	})
+
+	t.Run("reactivate", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, append(provisioned("zippy-0001"), usersignup("zippy-0001", "Provisioned"))...)
+
+		// when
+		_, err := lifecycle(cl).Do(context.TODO(), Reactivate, "zippy-0001")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("ban", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, usersignup("zippy-0001", toolchainv1alpha1.UserSignupUserBannedReason))
+
+		// when
+		_, err := lifecycle(cl).Do(context.TODO(), Ban, "zippy-0001")
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostNS, Name: "zippy-0001"}, &toolchainv1alpha1.BannedUser{}))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, usersignup("zippy-0001", "Provisioned"))
+
+		// when
+		_, err := lifecycle(cl).Do(context.TODO(), Delete, "zippy-0001")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("tier distribution", func(t *testing.T) {
+		// given
+		tierLifecycle := func(cl client.Client) *Lifecycle {
+			l := lifecycle(cl)
+			l.Tier = func() string {
+				return "baselarge"
+			}
+			return l
+		}
+		mur := func(username string) *toolchainv1alpha1.MasterUserRecord {
+			return &toolchainv1alpha1.MasterUserRecord{ObjectMeta: metav1.ObjectMeta{Namespace: hostNS, Name: username}}
+		}
+
+		t.Run("signup", func(t *testing.T) {
+			// given
+			cl := commontest.NewFakeClient(t, append(provisioned("zippy-churn-0001"), mur("zippy-churn-0001"))...)
+
+			// when
+			_, err := tierLifecycle(cl).Do(context.TODO(), Signup, "zippy-churn-0001")
+
+			// then
+			require.NoError(t, err)
+			signup := &toolchainv1alpha1.UserSignup{}
+			require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostNS, Name: "zippy-churn-0001"}, signup))
+			assert.Equal(t, "true", signup.Annotations[toolchainv1alpha1.SkipAutoCreateSpaceAnnotationKey])
+			spaceBindings := &toolchainv1alpha1.SpaceBindingList{}
+			require.NoError(t, cl.List(context.TODO(), spaceBindings, client.InNamespace(hostNS)))
+			assert.Len(t, spaceBindings.Items, 1)
+		})
+
+		t.Run("deactivate and reactivate", func(t *testing.T) {
+			// given
+			space := &toolchainv1alpha1.Space{
+				ObjectMeta: metav1.ObjectMeta{Namespace: hostNS, Name: "zippy-0001"},
+				Spec:       toolchainv1alpha1.SpaceSpec{TierName: "appstudio"},
+				Status:     toolchainv1alpha1.SpaceStatus{TargetCluster: "member-1"},
+			}
+			cl := commontest.NewFakeClient(t, space, mur("zippy-0001"), usersignup("zippy-0001", "Provisioned"))
+			l := tierLifecycle(cl)
+			require.NoError(t, l.apply(context.TODO(), Deactivate, "zippy-0001"))
+			// the host operator deletes the Space of the deactivated user
+			require.NoError(t, cl.Delete(context.TODO(), space))
+
+			// when
+			err := l.apply(context.TODO(), Reactivate, "zippy-0001")
+
+			// then
+			require.NoError(t, err)
+			reactivated := &toolchainv1alpha1.Space{}
+			require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostNS, Name: "zippy-0001"}, reactivated))
+			assert.Equal(t, "appstudio", reactivated.Spec.TierName)
+			assert.Equal(t, "member-1", reactivated.Spec.TargetCluster)
+		})
+
+		t.Run("reactivate without deactivation", func(t *testing.T) {
+			// given
+			cl := commontest.NewFakeClient(t, mur("zippy-0001"), usersignup("zippy-0001", "Provisioned"))
+
+			// when
+			err := tierLifecycle(cl).apply(context.TODO(), Reactivate, "zippy-0001")
+
+			// then
+			require.EqualError(t, err, "unable to reactivate user 'zippy-0001', the tier of its space is unknown")
+		})
+	})
+
+	t.Run("failures", func(t *testing.T) {
+
+		t.Run("member not deprovisioned", func(t *testing.T) {
+			// given
+			objs := append(provisioned("zippy-0001")[1:], usersignup("zippy-0001", toolchainv1alpha1.UserSignupUserDeactivatedReason))
+			cl := commontest.NewFakeClient(t, objs...)
+
+			// when
+			_, err := lifecycle(cl).Do(context.TODO(), Deactivate, "zippy-0001")
+
+			// then
+			require.EqualError(t, err, "deactivate of user 'zippy-0001' did not converge (host: true, member: false): timed out waiting for the condition")
+		})
+
+		t.Run("space not provisioned", func(t *testing.T) {
+			// given
+			cl := commontest.NewFakeClient(t)
+
+			// when
+			_, err := lifecycle(cl).Do(context.TODO(), Signup, "zippy-churn-0001")
+
+			// then
+			require.EqualError(t, err, "signup of user 'zippy-churn-0001' did not converge (host: false, member: false): timed out waiting for the condition")
+		})
+
+		t.Run("already banned", func(t *testing.T) {
+			// given
+			cl := commontest.NewFakeClient(t, &toolchainv1alpha1.BannedUser{ObjectMeta: metav1.ObjectMeta{Namespace: hostNS, Name: "zippy-0001"}})
+
+			// when
+			_, err := lifecycle(cl).Do(context.TODO(), Ban, "zippy-0001")
+
+			// then
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "unable to ban user 'zippy-0001'")
+		})
+	})
+}
@@ -0,0 +1,133 @@
+package churn
+
+import (
+	"sync"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+)
+
+// Stats collects the number of operations, their failures and how long they took to converge on the host and member clusters.
+// It is safe for concurrent use.
+type Stats struct {
+	mu         sync.Mutex
+	started    time.Time
+	ended      time.Time
+	operations map[Operation]int
+	failures   map[Operation]int
+	// skipped are the operations that were not started because no user was in their source state
+	skipped map[Operation]int
+	host    map[Operation]*latency.Distribution
+	member  map[Operation]*latency.Distribution
+	// minPopulation and maxPopulation are the bounds of the population observed during the churn
+	minPopulation int
+	maxPopulation int
+	observed      bool
+}
+
+func newStats() *Stats {
+	return &Stats{
+		operations: map[Operation]int{},
+		failures:   map[Operation]int{},
+		skipped:    map[Operation]int{},
+		host:       map[Operation]*latency.Distribution{},
+		member:     map[Operation]*latency.Distribution{},
+	}
+}
+
+func (s *Stats) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = time.Now()
+}
+
+func (s *Stats) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended = time.Now()
+}
+
+func (s *Stats) skip(op Operation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skipped[op]++
+}
+
+func (s *Stats) record(op Operation, c Convergence, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failures[op]++
+		return
+	}
+	s.operations[op]++
+	if _, ok := s.host[op]; !ok {
+		s.host[op] = &latency.Distribution{}
+		s.member[op] = &latency.Distribution{}
+	}
+	s.host[op].Add(c.Host)
+	s.member[op].Add(c.Member)
+}
+
+func (s *Stats) observePopulation(population int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.observed || population < s.minPopulation {
+		s.minPopulation = population
+	}
+	if !s.observed || population > s.maxPopulation {
+		s.maxPopulation = population
+	}
+	s.observed = true
+}
+
+// Results returns the number and the rate of each operation, its failures and skips, and how long it took to converge on the
+// host and member clusters, for the given phase
+func (s *Stats) Results(phase string) []results.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elapsed := s.ended.Sub(s.started)
+	if s.ended.IsZero() {
+		elapsed = time.Since(s.started)
+	}
+	churnResults := []results.Result{}
+	for _, op := range append([]Operation{Signup}, Operations...) {
+		labels := map[string]string{"operation": string(op)}
+		if s.operations[op]+s.failures[op]+s.skipped[op] == 0 {
+			continue
+		}
+		var rate float64
+		if elapsed > 0 {
+			rate = float64(s.operations[op]) / elapsed.Minutes()
+		}
+		churnResults = append(churnResults,
+			results.Result{Name: "Churn Operations", Aggregate: results.Total, Value: float64(s.operations[op]), Phase: phase, Labels: labels},
+			results.Result{Name: "Churn Rate", Unit: results.UsersPerMinute, Value: rate, Phase: phase, Labels: labels, Precision: 2},
+			results.Result{Name: "Churn Failures", Aggregate: results.Total, Value: float64(s.failures[op]), Phase: phase, Labels: labels},
+		)
+		if s.skipped[op] > 0 {
+			churnResults = append(churnResults, results.Result{Name: "Churn Skipped Operations", Aggregate: results.Total, Value: float64(s.skipped[op]), Phase: phase, Labels: labels})
+		}
+		if s.operations[op] == 0 {
+			continue
+		}
+		for _, c := range []struct {
+			cluster string
+			d       *latency.Distribution
+		}{{"host", s.host[op]}, {"member", s.member[op]}} {
+			convergence := append([]results.Result{latency.Result("Churn Convergence Time", results.Average, phase, c.d.Mean())}, c.d.Summary("Churn Convergence Time", phase)...)
+			for i := range convergence {
+				convergence[i].Labels = map[string]string{"operation": string(op), "cluster": c.cluster}
+			}
+			churnResults = append(churnResults, convergence...)
+		}
+	}
+	if s.observed {
+		churnResults = append(churnResults,
+			results.Result{Name: "Churn Population", Aggregate: results.Min, Value: float64(s.minPopulation), Phase: phase},
+			results.Result{Name: "Churn Population", Aggregate: results.Max, Value: float64(s.maxPopulation), Phase: phase},
+		)
+	}
+	return churnResults
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/failures"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/journal"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// startOnSchedule processes each user of the phase at its arrival time, whatever the number of users still being processed (open-loop).
+// The users share the given number of clients. Users that are recorded in the journal don't take an arrival slot, they are verified right away.
+// No user is started once the run is interrupted.
+func startOnSchedule(parent *sync.WaitGroup, run *interruption, term terminal.Terminal, checkpoints *journal.Journal, ledger *failures.Ledger, phase userPhase, clientCount int, schedule []time.Duration) {
+	clients := make([]client.Client, clientCount)
+	for i := range clients {
+		cl, _, _, err := cfg.NewClient(term, kubeconfig)
+		if err != nil {
+			term.Fatalf(err, "cannot create client")
+		}
+		clients[i] = cl
+	}
+
+	parent.Add(1)
+	go func() {
+		defer parent.Done()
+		var inFlight sync.WaitGroup
+		start := time.Now()
+		next := 0
+		for curUserNum := 1; curUserNum <= len(schedule) && !run.interrupted(); curUserNum++ {
+			if !checkpoints.IsCompleted(phase.name, curUserNum) {
+				arrival := start.Add(schedule[next])
+				next++
+				if !run.sleep(time.Until(arrival)) {
+					break
+				}
+				phase.arrivals.schedule(curUserNum, arrival)
+			}
+			inFlight.Add(1)
+			go func(curUserNum int) {
+				defer inFlight.Done()
+				processUser(run, term, clients[curUserNum%len(clients)], checkpoints, ledger, phase, curUserNum)
+				phase.bar.Incr()
+			}(curUserNum)
+		}
+		inFlight.Wait()
+	}()
+}
+
+// arrivalStats collects the timings of the users of an open-loop phase. The queueing delay is the time until the host operator
+// approves the UserSignup, the provisioning latency is the time from the approval until the Space is ready.
+type arrivalStats struct {
+	mu        sync.Mutex
+	scheduled map[int]time.Time
+	users     int
+	// firstCreated and lastCreated are used to compute the rate that was actually achieved
+	firstCreated, lastCreated time.Time
+	dispatchLag               *latency.Distribution
+	queueing                  *latency.Distribution
+	provisioning              *latency.Distribution
+	// phase is the name of the signups phase
+	phase string
+}
+
+func newArrivalStats(phase string) *arrivalStats {
+	return &arrivalStats{
+		phase:        phase,
+		scheduled:    map[int]time.Time{},
+		dispatchLag:  &latency.Distribution{},
+		queueing:     &latency.Distribution{},
+		provisioning: &latency.Distribution{},
+	}
+}
+
+// schedule records the time at which the given user was due to arrive
+func (s *arrivalStats) schedule(curUserNum int, arrival time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduled[curUserNum] = arrival
+}
+
+// record adds the timings of a user that was provisioned successfully
+func (s *arrivalStats) record(curUserNum int, created, approved, ready time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if scheduled, ok := s.scheduled[curUserNum]; ok {
+		s.dispatchLag.Add(created.Sub(scheduled))
+	}
+	s.queueing.Add(approved.Sub(created))
+	s.provisioning.Add(ready.Sub(approved))
+	if s.users == 0 || created.Before(s.firstCreated) {
+		s.firstCreated = created
+	}
+	if created.After(s.lastCreated) {
+		s.lastCreated = created
+	}
+	s.users++
+}
+
+func (s *arrivalStats) results() []results.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var achievedRate float64
+	if span := s.lastCreated.Sub(s.firstCreated); s.users > 1 && span > 0 {
+		achievedRate = float64(s.users-1) / span.Seconds()
+	}
+	arrivals := []results.Result{
+		{Name: "Achieved Signup Rate", Unit: results.UsersPerSecond, Value: achievedRate, Phase: s.phase, Precision: 2},
+		latency.Result("Signup Dispatch Lag", results.Average, s.phase, s.dispatchLag.Mean()),
+		latency.Result("Signup Queueing Delay", results.Average, s.phase, s.queueing.Mean()),
+	}
+	arrivals = append(arrivals, s.queueing.Summary("Signup Queueing Delay", s.phase)...)
+	arrivals = append(arrivals, latency.Result("Signup Provisioning Latency", results.Average, s.phase, s.provisioning.Mean()))
+	return append(arrivals, s.provisioning.Summary("Signup Provisioning Latency", s.phase)...)
+}
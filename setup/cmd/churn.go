@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/churn"
+	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/failures"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/members"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/scenario"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/tiers"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/users"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// soakPhase is the name under which the operations of the soak are reported
+const soakPhase = "soak"
+
+// newSoak returns the engine that churns the given users through their lifecycle, the new users are provisioned on the member
+// clusters of the distribution, and in the tiers of the tier distribution when it is set, after the users of the scenario.
+// A failed operation aborts the run, unless the failures are tolerated by the failure policy.
+func newSoak(term terminal.Terminal, cl client.Client, sc *scenario.Scenario, distribution *members.Distribution, userTiers *tiers.Distribution, ledger *failures.Ledger, usernames []string) (*churn.Engine, error) {
+	first, err := nextChurnUser(cl)
+	if err != nil {
+		return nil, err
+	}
+	var signups int64
+	lifecycle := &churn.Lifecycle{
+		Client:                   cl,
+		HostOperatorNamespace:    cfg.HostOperatorNamespace,
+		MemberOperatorNamespaces: memberNamespaces(distribution.Members()),
+		TargetCluster: func() string {
+			return distribution.TargetCluster(sc.Users + int(atomic.AddInt64(&signups, 1)))
+		},
+	}
+	if userTiers != nil {
+		var tierSignups int64
+		lifecycle.Tier = func() string {
+			return userTiers.Tier(sc.Users + int(atomic.AddInt64(&tierSignups, 1)))
+		}
+	}
+	do := func(ctx context.Context, op churn.Operation, username string) (churn.Convergence, error) {
+		started := time.Now()
+		c, err := lifecycle.Do(ctx, op, username)
+		if err != nil && ctx.Err() != nil {
+			// the operation was given up at the end of the grace period of an interrupted run
+			term.Debugf("%s of user '%s' was given up: %s", op, username, err)
+			return c, err
+		}
+		if err != nil {
+			if ledger == nil {
+				term.Fatalf(err, "%s failed", soakPhase)
+			}
+			ledger.Add(soakPhase, username, "UserSignup "+username, 1, err)
+			term.Event(terminal.Event{Type: terminal.UserFailed, Phase: soakPhase, User: username, Duration: time.Since(started), Attributes: map[string]interface{}{"operation": op, "error": err.Error()}})
+			if exceededErr := ledger.Exceeded(); exceededErr != nil {
+				term.Fatalf(exceededErr, "too many failures, aborting the run")
+			}
+			return c, err
+		}
+		term.Event(terminal.Event{Type: terminal.UserCompleted, Phase: soakPhase, User: username, Duration: time.Since(started), Attributes: map[string]interface{}{
+			"operation":         op,
+			"hostConvergence":   c.Host.Seconds(),
+			"memberConvergence": c.Member.Seconds(),
+		}})
+		return c, nil
+	}
+
+	active := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		// the users that failed to be set up are left out of the churn
+		if ledger == nil || !ledger.HasFailed(username) {
+			active = append(active, username)
+		}
+	}
+	config := churn.Config{
+		Duration:    sc.Soak.GetDuration(),
+		Population:  sc.Soak.Population,
+		Concurrency: sc.Soak.Concurrency,
+		Rates:       sc.Soak.GetRates(),
+	}
+	newUsername := func(n int) string {
+		return fmt.Sprintf("%s-churn-%04d", usernamePrefix, first+n-1)
+	}
+	return churn.New(config, active, newUsername, do), nil
+}
+
+// nextChurnUser returns the number of the first user signed up by the soak, after the ones signed up by the soak of a previous run
+func nextChurnUser(cl client.Client) (int, error) {
+	prefix := usernamePrefix + "-churn"
+	names, err := users.ListWithPrefix(cl, prefix, cfg.HostOperatorNamespace)
+	if err != nil {
+		return 0, err
+	}
+	next := 1
+	for _, name := range names {
+		if n, err := strconv.Atoi(strings.TrimPrefix(name, prefix+"-")); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return next, nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/budget"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/compare"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	budgetFile    string
+	compareFormat string
+)
+
+func newCompareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "compare <baseline results file> <results file>...",
+		Short:         "compare the results of runs with the results of a baseline run",
+		SilenceErrors: true,
+		SilenceUsage:  false,
+		Args:          cobra.MinimumNArgs(2),
+		Run:           compareResults,
+	}
+
+	cmd.Flags().StringVar(&budgetFile, "budget", "", fmt.Sprintf("path to a budget file declaring how much each metric may change compared to the baseline, the command exits with the '%d' code when a threshold is crossed, see setup/budget/budget.yaml for an example", budget.ExitCode))
+	cmd.Flags().StringVar(&compareFormat, "format", "table", "the format of the comparison: 'table' or 'markdown'")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "if 'debug' traces should be displayed in the console")
+
+	return cmd
+}
+
+func compareResults(cmd *cobra.Command, args []string) {
+	cmd.SilenceUsage = true
+	term := terminal.New(cmd.InOrStdin, cmd.OutOrStdout, verbose)
+
+	if compareFormat != "table" && compareFormat != "markdown" {
+		term.Fatalf(fmt.Errorf("must be 'table' or 'markdown'"), "invalid format '%s'", compareFormat)
+	}
+	var b *budget.Budget
+	if budgetFile != "" {
+		var err error
+		if b, err = budget.Load(budgetFile); err != nil {
+			term.Fatalf(err, "invalid budget file")
+		}
+	}
+
+	runs := make([]compare.Run, 0, len(args))
+	for _, path := range args {
+		loaded, err := results.Load(path)
+		if err != nil {
+			term.Fatalf(err, "invalid results file")
+		}
+		runs = append(runs, compare.Run{Name: filepath.Base(path), Results: loaded})
+	}
+
+	c := compare.New(runs, b)
+	if compareFormat == "markdown" {
+		fmt.Fprint(term.OutOrStdout(), c.Markdown())
+	} else {
+		fmt.Fprint(term.OutOrStdout(), c.Table())
+	}
+	if len(c.Violations) > 0 {
+		os.Exit(budget.ExitCode)
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
+)
+
+// interruptedExitCode is the exit code of a run that was interrupted by a signal, as a shell does for SIGINT
+const interruptedExitCode = 130
+
+// interruption stops a run gracefully on SIGINT or SIGTERM. On the first signal no new user is started and the users in flight
+// are given the grace period to complete. On the second signal, or at the end of the grace period, the context of the users in
+// flight is cancelled. On the third signal the process exits right away.
+type interruption struct {
+	// ctx is the context of the work of the run, it is cancelled when the users in flight must be given up
+	ctx    context.Context
+	cancel context.CancelFunc
+	// draining is closed on the first signal
+	draining chan struct{}
+	signals  chan os.Signal
+	done     chan struct{}
+	mu       sync.Mutex
+	at       time.Time
+}
+
+// notifyInterruption starts handling the signals, onInterrupt is called on the first signal and onExit before exiting on the third signal
+func notifyInterruption(term terminal.Terminal, gracePeriod time.Duration, onInterrupt, onExit func()) *interruption {
+	ctx, cancel := context.WithCancel(context.Background())
+	i := &interruption{
+		ctx:      ctx,
+		cancel:   cancel,
+		draining: make(chan struct{}),
+		signals:  make(chan os.Signal, 3),
+		done:     make(chan struct{}),
+	}
+	signal.Notify(i.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		var sig os.Signal
+		select {
+		case sig = <-i.signals:
+		case <-i.done:
+			return
+		}
+		i.mu.Lock()
+		i.at = time.Now()
+		i.mu.Unlock()
+		close(i.draining)
+		onInterrupt()
+		term.Infof("\n🛑 received %s, no new user is started and the users in flight are given %s to complete (send the signal again to stop them right away)", sig, gracePeriod)
+
+		grace := time.NewTimer(gracePeriod)
+		defer grace.Stop()
+		select {
+		case <-grace.C:
+		case <-i.signals:
+		case <-i.done:
+			return
+		}
+		term.Infof("🛑 giving up the users in flight, the partial results are being written (send the signal again to exit right away)")
+		cancel()
+
+		select {
+		case <-i.signals:
+			onExit()
+			os.Exit(interruptedExitCode)
+		case <-i.done:
+		}
+	}()
+	return i
+}
+
+// interrupted returns true once a signal was received
+func (i *interruption) interrupted() bool {
+	select {
+	case <-i.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// interruptedAt returns when the first signal was received
+func (i *interruption) interruptedAt() time.Time {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.at
+}
+
+// sleep waits for the given duration, or until a signal is received. It returns false if it was interrupted.
+func (i *interruption) sleep(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-i.draining:
+		return false
+	}
+}
+
+// stop restores the default handling of the signals
+func (i *interruption) stop() {
+	signal.Stop(i.signals)
+	close(i.done)
+	i.cancel()
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/members"
+)
+
+// newDistribution returns the distribution of the --member-distribution flag bound to the given ready member clusters. By default
+// all the users are provisioned on the member cluster of the --member-ns operator.
+func newDistribution(value string, ready []members.Member) (*members.Distribution, error) {
+	if value == "" {
+		for _, m := range ready {
+			if m.OperatorNamespace == cfg.MemberOperatorNamespace {
+				value = m.Name
+				break
+			}
+		}
+		if value == "" {
+			return nil, fmt.Errorf("no ready member cluster has its operator in the '%s' namespace", cfg.MemberOperatorNamespace)
+		}
+	}
+	distribution, err := members.ParseDistribution(value)
+	if err != nil {
+		return nil, err
+	}
+	return distribution, distribution.Bind(ready)
+}
+
+// memberNamespaces returns the namespaces of the operators of the given member clusters, starting with the --member-ns namespace
+func memberNamespaces(ms []members.Member) []string {
+	namespaces := []string{cfg.MemberOperatorNamespace}
+	for _, m := range ms {
+		if m.OperatorNamespace == "" || contains(namespaces, m.OperatorNamespace) {
+			continue
+		}
+		namespaces = append(namespaces, m.OperatorNamespace)
+	}
+	return namespaces
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
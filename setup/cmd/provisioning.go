@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+)
+
+// provisioningStats collects the provisioning time of the signups by group of users, eg. by member cluster or by tier
+type provisioningStats struct {
+	mu           sync.Mutex
+	names        []string
+	provisioning map[string]*latency.Distribution
+	// phase is the name of the signups phase
+	phase string
+	// label is the label of the results that holds the name of the group
+	label string
+}
+
+func newProvisioningStats(phase, label string) *provisioningStats {
+	return &provisioningStats{
+		phase:        phase,
+		label:        label,
+		provisioning: map[string]*latency.Distribution{},
+	}
+}
+
+// record adds the provisioning time of a user of the given group
+func (s *provisioningStats) record(group string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.provisioning[group]; !ok {
+		s.names = append(s.names, group)
+		s.provisioning[group] = &latency.Distribution{}
+	}
+	s.provisioning[group].Add(d)
+}
+
+// results returns the number of users provisioned in each group and their provisioning time, with the label of the group
+func (s *provisioningStats) results() []results.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byGroup := []results.Result{}
+	for _, name := range s.names {
+		d := s.provisioning[name]
+		groupResults := []results.Result{
+			{Name: "Provisioned Users", Value: float64(d.Count()), Phase: s.phase},
+			latency.Result("Provisioning Time Per User", results.Average, s.phase, d.Mean()),
+		}
+		groupResults = append(groupResults, d.Summary("Provisioning Time Per User", s.phase)...)
+		for i := range groupResults {
+			groupResults[i].Labels = map[string]string{s.label: name}
+		}
+		byGroup = append(byGroup, groupResults...)
+	}
+	return byGroup
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/members"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/rollout"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/scenario"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/status"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolloutPhase is the name under which the rollout of the tier update is reported
+const rolloutPhase = "tier-rollout"
+
+// rolloutTier creates a new revision of the templates of the tier of the scenario and waits until it is rolled out to the Spaces of
+// the given users. The time it took for each Space is recorded in the timings. It returns the statistics of the rollout and when
+// the tier was updated.
+func rolloutTier(run *interruption, term terminal.Terminal, cl client.Client, sc *scenario.Scenario, distribution *members.Distribution, timings *latency.Recorder, tracker *status.Tracker, usernames []string) (*rollout.Stats, time.Time) {
+	tierName := sc.TierUpdate.Tier
+	spaces := &rollout.Tracker{
+		Client:                   cl,
+		HostOperatorNamespace:    cfg.HostOperatorNamespace,
+		MemberOperatorNamespaces: memberNamespaces(distribution.Members()),
+		Interval:                 cfg.DefaultRetryInterval,
+	}
+	inTier, err := spaces.SpacesInTier(context.TODO(), tierName, usernames)
+	if err != nil {
+		term.Fatalf(err, "unable to lookup the spaces of tier '%s'", tierName)
+	}
+
+	// the revision only has to be unique, so that a later run rolls out a new revision again
+	revision := strconv.FormatInt(time.Now().Unix(), 16)
+	tier, err := rollout.Bump(context.TODO(), cl, cfg.HostOperatorNamespace, tierName, revision)
+	if err != nil {
+		term.Fatalf(err, "unable to update tier '%s'", tierName)
+	}
+	updated := time.Now()
+	term.Infof("🔀 rolling out revision '%s' of tier '%s' to %d spaces...", revision, tierName, len(inTier))
+	term.Event(terminal.Event{Type: terminal.PhaseStarted, Phase: rolloutPhase, Attributes: map[string]interface{}{"kind": rolloutPhase, "tier": tierName, "revision": revision, "users": len(inTier)}})
+	tracker.PhaseStarted(rolloutPhase, len(inTier))
+
+	spaces.Converged = func(username string, d time.Duration) {
+		timings.Record(rolloutPhase, username, updated, d)
+		tracker.UserCompleted(rolloutPhase, d)
+		term.Event(terminal.Event{Type: terminal.UserCompleted, Phase: rolloutPhase, User: username, Duration: d})
+	}
+	stats, err := spaces.Wait(run.ctx, run.draining, tier, inTier, updated, sc.TierUpdate.GetTimeout())
+	if err != nil && stats == nil {
+		term.Fatalf(err, "unable to roll out tier '%s'", tierName)
+	}
+	// the Spaces that did not converge are reported in the results rather than aborting the run
+	if err != nil {
+		term.Errorf(err, "the rollout of tier '%s' is incomplete", tierName)
+	}
+	term.Event(terminal.Event{Type: terminal.PhaseCompleted, Phase: rolloutPhase, Duration: time.Since(updated)})
+	tracker.PhaseCompleted(rolloutPhase)
+	term.Infof("🏁 done rolling out tier '%s'", tierName)
+	return stats, updated
+}
@@ -2,34 +2,53 @@ package cmd
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/codeready-toolchain/toolchain-e2e/setup/auth"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/budget"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/churn"
 	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/failures"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/idlers"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/journal"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/manifest"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/members"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics/queries"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/operators"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/proxy"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/regsvc"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/report"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/resources"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/rollout"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/scenario"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/status"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/tiers"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/timeline"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/users"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/wait"
+	"github.com/pkg/errors"
+	prometheus "github.com/prometheus/client_golang/api/prometheus/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/gosuri/uiprogress"
 	"github.com/gosuri/uitable/util/strutil"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
@@ -48,14 +67,49 @@ var (
 	idlerTimeout         string
 	token                string
 	workloads            []string
+	resumeJournal        string
+	failurePolicy        failures.Policy
+	scenarioFile         string
+	signupArrival        scenario.Arrival
+	metricsStep          string
+	queriesFile          string
+	outputs              []string
+	outputFormats        []results.Format
+	gracePeriod          time.Duration
+	logFormat            string
+	listenAddr           string
+	memberDistribution   string
+	tierDistribution     string
+	signupMode           string
+	registrationService  string
+	applyAs              string
+	tierUpdate           string
+	tierRolloutTimeout   string
+	soakDuration         string
+	soakPopulation       int
+	soakConcurrency      int
+	soakRates            []string
 )
 
-var (
-	IdlerUpdateTime         time.Duration
-	DefaultApplyTimePerUser time.Duration
-	CustomApplyTimePerUser  time.Duration
+// the signup modes of the --signup-mode flag
+const (
+	// crSignupMode creates the UserSignup resources with the client of the setup
+	crSignupMode = "cr"
+	// regsvcSignupMode signs up the users through the REST API of the registration service
+	regsvcSignupMode = "regsvc"
+)
+
+// the identities of the --apply-as flag that the templates are applied with
+const (
+	// adminApplyAs applies the templates with the client of the setup
+	adminApplyAs = "admin"
+	// userApplyAs applies the templates with a client of each user through the API proxy
+	userApplyAs = "user"
 )
 
+// defaultTemplatePath is the template applied to the users of the default phase
+const defaultTemplatePath = "setup/resources/user-workloads.yaml"
+
 // Execute the setup command to fill a cluster with as many users as requested.
 // The command uses the default `$KUBECONFIG` or `<home>/.kube/config` unless a path is specified.
 func Execute() {
@@ -71,9 +125,15 @@ func Execute() {
 	cmd.Flags().StringVar(&usernamePrefix, "username", usernamePrefix, "the prefix used for usersignup names")
 	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "absolute path to the kubeconfig file")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "if 'debug' traces should be displayed in the console")
+	cmd.Flags().StringVar(&logFormat, "log-format", string(terminal.Text), "the format of the console output: 'text', or 'json' for a JSON object per line with the phases, the completed users, the operator installations, the metrics samples and the errors of the run")
 	cmd.Flags().IntVarP(&numberOfUsers, "users", "u", 2000, "the number of user accounts to provision")
 	cmd.Flags().StringVar(&cfg.HostOperatorNamespace, "host-ns", cfg.DefaultHostNS, "the namespace of Host operator")
 	cmd.Flags().StringVar(&cfg.MemberOperatorNamespace, "member-ns", cfg.DefaultMemberNS, "the namespace of the Member operator")
+	cmd.Flags().StringVar(&signupMode, "signup-mode", crSignupMode, "how the users sign up: 'cr' to create their UserSignup resources directly, or 'regsvc' to sign up through the REST API of the registration service with tokens signed by the e2e test key and to wait until the registration service reports the signups as ready")
+	cmd.Flags().StringVar(&registrationService, "registration-service-url", "", "the URL of the registration service in the 'regsvc' signup mode (by default the host of the route of the registration service in the host operator namespace)")
+	cmd.Flags().StringVar(&applyAs, "apply-as", adminApplyAs, "who applies the templates of the users: 'admin' with the client of the setup, or 'user' with a client of each user through the API proxy of the host cluster, authenticated with a token signed by the e2e test key, to measure the round trips through the proxy and the objects rejected by RBAC or by admission webhooks")
+	cmd.Flags().StringVar(&memberDistribution, "member-distribution", "", "how the users are distributed across the member clusters: 'round-robin' across the ready member clusters, 'host' to let the host operator pick the member cluster of each user, a list of member clusters that the users are assigned to in turn eg. 'member-a,member-b', or member clusters with weights eg. 'member-a=70,member-b=30' (by default all the users are provisioned on the member cluster of --member-ns)")
+	cmd.Flags().StringVar(&tierDistribution, "tier-distribution", "", "the tiers that the spaces of the users are created in, with their percentages eg. 'base1ns=70%,baselarge=20%,appstudio=10%', the default space tier of the host operator is left as is and the results are reported by tier (by default the default space tier is set to '"+cfg.UserSpaceTier+"' for all the users)")
 	cmd.Flags().StringSliceVar(&customTemplatePaths, "template", []string{}, "the path to the OpenShift template to apply for each custom user")
 	cmd.Flags().IntVarP(&defaultTemplateUsers, cfg.DefaultTemplateUsersParam, "d", 2000, "how many users will have the default user workloads template applied")
 	cmd.Flags().IntVarP(&customTemplateUsers, cfg.CustomTemplateUsersParam, "c", 2000, "how many users will have the custom user workloads template applied")
@@ -85,7 +145,34 @@ func Execute() {
 	cmd.Flags().StringVarP(&idlerTimeout, "idler-timeout", "i", "15s", "overrides the default idler timeout")
 	cmd.Flags().StringVar(&cfg.Testname, "testname", "", "a name that is added as a suffix to the result file names")
 	cmd.Flags().StringVarP(&token, "token", "t", "", "Openshift API token")
-	cmd.Flags().StringSliceVar(&workloads, "workloads", []string{}, "workload namespace:name pairs that should have metrics collected during the setup. all values are comma-separated eg. \"--workloads service-binding-operator:service-binding-operator,rhoas-operator:rhoas-operator\"")
+	cmd.Flags().StringVar(&scenarioFile, "scenario", "", "path to a scenario file declaring the phases, users, templates, concurrency, metrics and settle duration of the run, replaces the --users, --default, --custom, --template, --skip-idler, --idler-timeout, --workloads, --metrics-step and --signup-* flags")
+	cmd.Flags().StringVar(&signupArrival.Rate, "signup-rate", "", "starts the signups at the given rate whatever the number of signups still being provisioned (open-loop) instead of with a fixed number of concurrent routines, eg. '5/s' or '120/m'")
+	cmd.Flags().StringVar(&signupArrival.Ramp, "signup-ramp", "", "how the signup rate increases to --signup-rate during --signup-ramp-duration: 'linear' or 'step'")
+	cmd.Flags().StringVar(&signupArrival.RampFrom, "signup-ramp-from", "", "the signup rate at the start of the ramp (default '0/s')")
+	cmd.Flags().StringVar(&signupArrival.RampDuration, "signup-ramp-duration", "", "the duration of the signup rate ramp eg. '10m'")
+	cmd.Flags().IntVar(&signupArrival.RampSteps, "signup-ramp-steps", 0, "the number of steps of a 'step' signup rate ramp (default 5)")
+	cmd.Flags().StringSliceVar(&signupArrival.Bursts, "signup-bursts", []string{}, "groups of signups started all at once on top of --signup-rate, as a number of users and a time since the start eg. \"--signup-bursts 100@5m,200@20m\"")
+	cmd.Flags().StringVar(&tierUpdate, "tier-update", "", "once all the users are set up, creates a new revision of the templates of the given tier eg. 'base1ns' and measures how long it takes to roll it out to the spaces of the users")
+	cmd.Flags().StringVar(&tierRolloutTimeout, "tier-rollout-timeout", "", "how long the spaces are given to converge to the new revision of the tier of --tier-update (default 30m)")
+	cmd.Flags().StringVar(&soakDuration, "soak-duration", "", "once all the users are set up, keeps them churning through their lifecycle for the given duration eg. '4h', at the rates of --soak-rates")
+	cmd.Flags().StringSliceVar(&soakRates, "soak-rates", []string{}, "the rates of the deactivate, reactivate, ban and delete operations of the soak eg. \"--soak-rates deactivate=10/m,reactivate=10/m,ban=1/m,delete=5/m\"")
+	cmd.Flags().IntVar(&soakPopulation, "soak-population", 0, "the number of users that are kept signed up during the soak, active or deactivated, by signing up new users to replace the banned and deleted ones (by default the number of users)")
+	cmd.Flags().IntVar(&soakConcurrency, "soak-concurrency", 0, "the maximum number of operations in flight during the soak (default 10)")
+	cmd.Flags().StringVar(&resumeJournal, "resume", "", "path to the checkpoint journal of an interrupted run, users already recorded as provisioned in the journal are verified and skipped")
+	cmd.Flags().IntVar(&failurePolicy.MaxFailures, "max-failures", 0, "the number of users that may fail before the run is aborted, failed users are retried, recorded in a failures file and left out of the timing results (by default the run is aborted on the first failure)")
+	cmd.Flags().Float64Var(&failurePolicy.FailureRatio, "failure-ratio", 0, "the ratio (between 0 and 1) of users that may fail before the run is aborted, see --max-failures")
+	cmd.Flags().IntVar(&failurePolicy.Retries, "failure-retries", 3, "how many times a failed user action is retried when --max-failures or --failure-ratio is set")
+	cmd.Flags().DurationVar(&failurePolicy.Backoff, "failure-backoff", 5*time.Second, "the initial wait before retrying a failed user action, doubled after each retry")
+	cmd.Flags().StringVar(&listenAddr, "listen", "", "the address of an HTTP server started during the run, eg. ':9095', that serves the progress of the phases as JSON on /status and the counters and latency histograms of the users in the Prometheus format on /metrics")
+	cmd.Flags().DurationVar(&gracePeriod, "grace-period", 30*time.Second, "how long the users in flight are given to complete when the run is interrupted by SIGINT or SIGTERM, the results of the users processed so far are then written and marked as interrupted")
+	cmd.Flags().StringVar(&metricsStep, "metrics-step", scenario.DefaultMetricsStep, "the resolution of the Prometheus range queries run over the whole setup once it is complete, use '0s' to only rely on the samples taken every 5 minutes")
+	cmd.Flags().StringSliceVar(&outputs, "output", []string{string(results.CSV)}, "the formats of the results files, any of csv, json, junit and openmetrics eg. \"--output csv,junit\"")
+	cmd.Flags().StringVar(&budgetFile, "budget", "", fmt.Sprintf("path to a budget file declaring the max and min values of the results, the verdict of each limit is added to the results and the setup exits with the '%d' code when a limit is crossed, see setup/budget/budget.yaml for an example", budget.ExitCode))
+	cmd.Flags().StringVar(&queriesFile, "queries", "", "path to a query catalogue file declaring additional PromQL queries to gather, see setup/scenario/queries.yaml for an example")
+	cmd.Flags().StringSliceVar(&workloads, "workloads", []string{}, "workloads that should have metrics collected during the setup, as kind/namespace/name triples where the kind is deployment, statefulset or daemonset, or as namespace:name pairs for deployments. all values are comma-separated eg. \"--workloads service-binding-operator:service-binding-operator,statefulset/openshift-logging/elasticsearch-cdm\"")
+
+	cmd.AddCommand(newTeardownCmd())
+	cmd.AddCommand(newCompareCmd())
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -95,53 +182,133 @@ func Execute() {
 
 func setup(cmd *cobra.Command, _ []string) { // nolint:gocyclo
 	cmd.SilenceUsage = true
-	term := terminal.New(cmd.InOrStdin, cmd.OutOrStdout, verbose)
+	term, format := newTerminal(cmd)
+	// the progress bars are only displayed on a terminal, they would garble the output of a CI job or of the json log format
+	showProgress := format == terminal.Text && isTerminal(os.Stdout)
 
 	// call cfg.Init() to initialize variables that are dependent on any flags eg. testname
 	cfg.Init(term)
+	parseOutputFormats(term)
+
+	// the run is declared either by a scenario file or by the flags, when resuming the scenario recorded by the interrupted run is used
+	var sc *scenario.Scenario
+	var scenarioContent []byte
+	var checkpoints *journal.Journal
+	var err error
+	// the budget is checked at the end of the run, it is loaded first so that an invalid budget file does not waste a run
+	var runBudget *budget.Budget
+	if budgetFile != "" {
+		if runBudget, err = budget.Load(budgetFile); err != nil {
+			term.Fatalf(err, "invalid budget file")
+		}
+	}
+	switch {
+	case resumeJournal != "":
+		// the scenario of the interrupted run is resumed as it is
+		for _, flag := range scenarioFlags {
+			if cmd.Flags().Changed(flag) {
+				term.Fatalf(fmt.Errorf("the '--%s' flag cannot be used to resume a run", flag), "invalid flags")
+			}
+		}
+		if checkpoints, err = journal.Open(resumeJournal); err != nil {
+			term.Fatalf(err, "unable to resume from journal '%s'", resumeJournal)
+		}
+		header := checkpoints.Header()
+		usernamePrefix = header.UsernamePrefix
+		resumeFlag(cmd, term, "member-distribution", &memberDistribution, header.MemberDistribution)
+		resumeFlag(cmd, term, "signup-mode", &signupMode, header.SignupMode)
+		resumeFlag(cmd, term, "apply-as", &applyAs, header.ApplyAs)
+		resumeFlag(cmd, term, "tier-distribution", &tierDistribution, header.TierDistribution)
+		if sc, scenarioContent, err = scenario.Load(header.ScenarioFile); err != nil {
+			term.Fatalf(err, "unable to load the scenario of the interrupted run")
+		}
+		term.Infof("Resuming run started at %s from journal '%s'", header.StartedAt.Format(time.RFC3339), resumeJournal)
+		term.Infof("Users already provisioned: '%d'", checkpoints.Completed(journal.Phase(sc.Phases[0].Name)))
+	case scenarioFile != "":
+		for _, flag := range scenarioFlags {
+			if cmd.Flags().Changed(flag) {
+				term.Fatalf(fmt.Errorf("the '--%s' flag cannot be used with a scenario file", flag), "invalid flags")
+			}
+		}
+		if sc, scenarioContent, err = scenario.Load(scenarioFile); err != nil {
+			term.Fatalf(err, "invalid scenario file")
+		}
+	default:
+		sc = scenarioFromFlags()
+		if queriesFile != "" {
+			// the queries of the catalogue are recorded in the scenario
+			if sc.Metrics.Queries, err = scenario.LoadQueries(queriesFile); err != nil {
+				term.Fatalf(err, "invalid query catalogue file")
+			}
+		}
+		if err := sc.Validate(); err != nil {
+			term.Fatalf(err, "invalid flags")
+		}
+		if scenarioContent, err = sc.Marshal(); err != nil {
+			term.Fatalf(err, "unable to convert the flags to a scenario")
+		}
+	}
+	// the scenario is recorded verbatim so that the run can be reproduced
+	if err := os.WriteFile(cfg.ScenarioFilepath(), scenarioContent, 0600); err != nil {
+		term.Fatalf(err, "unable to record the scenario")
+	}
 
-	term.Infof("Number of Users:           '%d'", numberOfUsers)
-	term.Infof("Default Template Users:    '%d'", defaultTemplateUsers)
-	term.Infof("Custom Template Users:     '%d'", customTemplateUsers)
+	term.Infof("Number of Users:           '%d'", sc.Users)
+	for _, p := range sc.Phases {
+		if p.Arrival != nil {
+			term.Infof("Phase '%s' (%s): %d users, arrival rate %s, %d clients", p.Name, p.Kind, p.UserCount(sc.Users), p.Arrival.Rate, p.Concurrency)
+			continue
+		}
+		term.Infof("Phase '%s' (%s): %d users, %d concurrent routines", p.Name, p.Kind, p.UserCount(sc.Users), p.Concurrency)
+	}
 	term.Infof("Host Operator Namespace:   '%s'", cfg.HostOperatorNamespace)
 	term.Infof("Member Operator Namespace: '%s'\n", cfg.MemberOperatorNamespace)
 
-	generalResultsInfo := [][]string{
-		{"Number of Users", strconv.Itoa(numberOfUsers)},
-		{"Number of Default Template Users", strconv.Itoa(defaultTemplateUsers)},
-		{"Number of Custom Template Users", strconv.Itoa(customTemplateUsers)},
+	generalResultsInfo := []results.Result{
+		{Name: "Number of Users", Value: float64(sc.Users)},
 	}
-
-	// validate params
-	if numberOfUsers < 1 {
-		term.Fatalf(fmt.Errorf("value must be more than 0"), "invalid users value '%d'", numberOfUsers)
+	for _, p := range sc.Phases {
+		if p.Kind == scenario.Templates {
+			generalResultsInfo = append(generalResultsInfo, results.Result{Name: fmt.Sprintf("Number of %s Template Users", capitalize(p.Name)), Value: float64(p.UserCount(sc.Users)), Phase: p.Name})
+		}
 	}
 
-	usersWithinBounds(term, defaultTemplateUsers, cfg.DefaultTemplateUsersParam)
-	usersWithinBounds(term, customTemplateUsers, cfg.CustomTemplateUsersParam)
-
+	// validate params
 	if operatorsLimit > len(operators.Templates) {
 		term.Fatalf(fmt.Errorf("the operators limit value must be less than or equal to '%d'", len(operators.Templates)), "invalid operators limit value '%d'", operatorsLimit)
 	}
 
-	idlerDuration, err := time.ParseDuration(idlerTimeout)
-	if err != nil {
-		term.Fatalf(err, "invalid idler-timeout value '%s'", idlerTimeout)
+	if signupMode != crSignupMode && signupMode != regsvcSignupMode {
+		term.Fatalf(fmt.Errorf("must be one of '%s' or '%s'", crSignupMode, regsvcSignupMode), "invalid signup mode '%s'", signupMode)
+	}
+
+	if applyAs != adminApplyAs && applyAs != userApplyAs {
+		term.Fatalf(fmt.Errorf("must be one of '%s' or '%s'", adminApplyAs, userApplyAs), "invalid apply-as value '%s'", applyAs)
 	}
 
-	if customTemplateUsers > 0 && len(customTemplatePaths) == 0 {
-		term.Fatalf(errors.New(""), "'%d' users are set to have custom templates applied but no custom templates were provided", customTemplateUsers)
+	if memberDistribution != "" {
+		if _, err := members.ParseDistribution(memberDistribution); err != nil {
+			term.Fatalf(err, "invalid member distribution")
+		}
 	}
 
-	for _, w := range workloads {
-		pair := strings.Split(w, ":")
-		if len(pair)%2 == 1 {
-			term.Fatalf(err, "invalid workloads values provided '%v' - values must be namespace:name pairs", workloads)
+	// userTiers is only set when the spaces of the users are created in the tiers of a distribution
+	var userTiers *tiers.Distribution
+	if tierDistribution != "" {
+		var err error
+		if userTiers, err = tiers.ParseDistribution(tierDistribution); err != nil {
+			term.Fatalf(err, "invalid tier distribution")
 		}
 	}
 
-	// add the default user-workloads.yaml file automatically
-	defaultTemplatePath := "setup/resources/user-workloads.yaml"
+	if err := failurePolicy.Validate(); err != nil {
+		term.Fatalf(err, "invalid failure policy")
+	}
+	// the ledger is nil when the run must be aborted on the first failure
+	var ledger *failures.Ledger
+	if failurePolicy.Enabled() {
+		ledger = failures.NewLedger(failurePolicy, sc.Users)
+	}
 
 	term.Infof("🕖 initializing...\n")
 	cl, config, scheme, err := cfg.NewClient(term, kubeconfig)
@@ -162,34 +329,99 @@ func setup(cmd *cobra.Command, _ []string) { // nolint:gocyclo
 	}
 
 	var templateListStr string
-	templateListStr += "\n - (default) " + defaultTemplatePath
-	for _, p := range customTemplatePaths {
-		absPath, err := filepath.Abs(p)
-		if err != nil {
-			term.Fatalf(err, "invalid template file: '%s'", absPath)
-		}
-		_, err = os.ReadFile(absPath)
-		if err != nil {
-			term.Fatalf(err, "invalid template file: '%s'", absPath)
+	for _, p := range sc.Phases {
+		for _, t := range p.Templates {
+			absPath, err := filepath.Abs(t)
+			if err != nil {
+				term.Fatalf(err, "invalid template file: '%s'", absPath)
+			}
+			templateListStr += fmt.Sprintf("\n - (%s) %s", p.Name, absPath)
 		}
-		templateListStr += "\n - (custom) " + absPath
 	}
 
 	term.Infof("📋 template list: %s\n", templateListStr)
-	if interactive && !term.PromptBoolf("👤 provision %d users on %s using the templates listed above", numberOfUsers, config.Host) {
+	if interactive && !term.PromptBoolf("👤 provision %d users on %s using the templates listed above", sc.Users, config.Host) {
 		return
 	}
 
+	if checkpoints == nil {
+		checkpoints, err = journal.Create(cfg.JournalFilepath(), journal.Header{
+			UsernamePrefix:     usernamePrefix,
+			Users:              sc.Users,
+			ScenarioFile:       cfg.ScenarioFilepath(),
+			StartedAt:          time.Now(),
+			MemberDistribution: memberDistribution,
+			SignupMode:         signupMode,
+			ApplyAs:            applyAs,
+			TierDistribution:   tierDistribution,
+		})
+		if err != nil {
+			term.Fatalf(err, "unable to create the checkpoint journal")
+		}
+	}
+	defer checkpoints.Close()
+	term.Infof("📒 checkpoint journal: %s (use --resume %[1]s to continue an interrupted run)\n", checkpoints.Path())
+
 	if err := operators.VerifySandboxOperatorsInstalled(cl); err != nil {
 		term.Fatalf(err, "ensure the sandbox host and member operators are installed successfully before running the setup")
 	}
 
+	readyMembers, err := members.Ready(context.TODO(), cl, cfg.HostOperatorNamespace)
+	if err != nil {
+		term.Fatalf(err, "unable to lookup the member clusters")
+	}
+	distribution, err := newDistribution(memberDistribution, readyMembers)
+	if err != nil {
+		term.Fatalf(err, "invalid member distribution")
+	}
+	term.Infof("Member distribution: %s", distribution)
+	// the results and the metrics are broken down by member cluster when the users may be provisioned on several member clusters
+	perMember := len(distribution.Members()) > 1 || distribution.Mode == members.Host
+
+	if userTiers != nil {
+		if err := userTiers.Bind(context.TODO(), cl, cfg.HostOperatorNamespace); err != nil {
+			term.Fatalf(err, "invalid tier distribution")
+		}
+		term.Infof("Tier distribution: %s", userTiers)
+	}
+
+	// regsvcClient is only set when the users sign up through the registration service
+	var regsvcClient *regsvc.Client
+	if signupMode == regsvcSignupMode {
+		if registrationService == "" {
+			if registrationService, err = regsvc.URL(context.TODO(), cl, cfg.HostOperatorNamespace); err != nil {
+				term.Fatalf(err, "unable to lookup the registration service")
+			}
+		}
+		regsvcClient = regsvc.New(registrationService)
+		term.Infof("Registration Service: '%s'", registrationService)
+	}
+
+	// userClients is only set when the templates are applied by the users through the API proxy
+	var userClients *proxy.Clients
+	if applyAs == userApplyAs {
+		proxyURL, err := proxy.URL(context.TODO(), cl, cfg.HostOperatorNamespace)
+		if err != nil {
+			term.Fatalf(err, "unable to lookup the API proxy")
+		}
+		userClients = proxy.NewClients(proxyURL, scheme, cl.RESTMapper())
+		term.Infof("API Proxy: '%s'", proxyURL)
+	}
+
 	// =====================
 	// begin configuration
 	// =====================
-	term.Infof("Configuring default space tier...")
-	if err := cfg.ConfigureDefaultSpaceTier(cl); err != nil {
-		term.Fatalf(err, "unable to set default space tier")
+	// record the settings changed below so that they can be restored by the teardown command
+	if err := cfg.RecordClusterConfig(cl, cfg.ClusterConfigFilepath()); err != nil {
+		term.Fatalf(err, "unable to record the cluster configuration")
+	}
+
+	// the spaces of a tier distribution are created in their tiers by the setup, so the default space tier is left as is
+	if userTiers == nil {
+		term.Infof("Configuring default space tier...")
+		if err := cfg.ConfigureDefaultSpaceTier(cl); err != nil {
+			term.Fatalf(err, "unable to set default space tier")
+		}
 	}
 
 	term.Infof("Disabling copied CSVs feature...")
@@ -200,6 +432,17 @@ func setup(cmd *cobra.Command, _ []string) { // nolint:gocyclo
 	// end configuration
 	// =====================
 
+	// record what is tested by the run next to its results, so that runs can be told comparable or not
+	runManifest := manifest.Collect(cl, effectiveFlags(cmd))
+	for _, e := range runManifest.Errors {
+		term.Infof("⚠️  run manifest is incomplete: %s", e)
+	}
+	if err := runManifest.Write(cfg.RunManifestFilepath()); err != nil {
+		term.Errorf(err, "failed to write the run manifest")
+	} else {
+		term.Infof("Run manifest: %s", cfg.RunManifestFilepath())
+	}
+
 	// =====================
 	// begin setup
 	// =====================
@@ -212,7 +455,7 @@ func setup(cmd *cobra.Command, _ []string) { // nolint:gocyclo
 		for i := 0; i < operatorsLimit; i++ {
 			templatePaths = append(templatePaths, "setup/operators/installtemplates/"+operators.Templates[i])
 		}
-		if err := operators.EnsureOperatorsInstalled(cl, scheme, templatePaths); err != nil {
+		if err := operators.EnsureOperatorsInstalled(term, cl, scheme, templatePaths); err != nil {
 			term.Fatalf(err, "failed to ensure all operators are installed")
 		}
 	}
@@ -221,22 +464,58 @@ func setup(cmd *cobra.Command, _ []string) { // nolint:gocyclo
 	term.Infof("🍿 provisioning users...")
 
 	// init the metrics gatherer
-	metricsInstance := metrics.New(term, cl, token, 5*time.Minute)
+	metricsInstance := metrics.New(term, cl, token, sc.Metrics.GetInterval(), sc.Metrics.GetStep())
 
 	prometheusClient := metrics.GetPrometheusClient(term, cl, token)
 	// add queries for each custom workload
-	for _, w := range workloads {
-		pair := strings.Split(w, ":")
-		if len(pair) != 2 {
-			term.Fatalf(err, "invalid workloads values provided '%v' - values must be namespace:name pairs", workloads)
+	monitoredWorkloads := make([]queries.Workload, 0, len(sc.Metrics.Workloads))
+	for _, value := range sc.Metrics.Workloads {
+		w, err := queries.ParseWorkload(value)
+		if err != nil {
+			term.Fatalf(err, "invalid workload provided '%s'", value)
 		}
-		if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: pair[0], Name: pair[1]}, &appsv1.Deployment{}); err != nil {
-			term.Fatalf(err, "invalid workload provided '%s'", w)
+		if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: w.Namespace, Name: w.Name}, workloadObject(w.Kind)); err != nil {
+			term.Fatalf(err, "invalid workload provided '%s'", value)
 		}
 		metricsInstance.AddQueries(
-			queries.QueryWorkloadCPUUsage(prometheusClient, pair[0], pair[1]),
-			queries.QueryWorkloadMemoryUsage(prometheusClient, pair[0], pair[1]),
+			queries.QueryWorkloadCPUUsage(prometheusClient, w),
+			queries.QueryWorkloadMemoryUsage(prometheusClient, w),
+			queries.QueryWorkloadRestarts(prometheusClient, w),
+			queries.QueryWorkloadOOMKills(prometheusClient, w),
 		)
+		monitoredWorkloads = append(monitoredWorkloads, w)
+	}
+	// add the queries of the operator of each member cluster, as long as it runs on the cluster that the metrics are gathered from
+	if perMember {
+		for _, m := range distribution.Members() {
+			memberOperator := queries.Workload{Kind: queries.Deployment, Namespace: m.OperatorNamespace, Name: cfg.MemberOperatorWorkload}
+			if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: memberOperator.Namespace, Name: memberOperator.Name}, &appsv1.Deployment{}); err != nil {
+				term.Infof("⚠️  the metrics of the operator of member cluster '%s' are not gathered: %s", m.Name, err)
+				continue
+			}
+			metricsInstance.AddQueries(
+				queries.QueryMemberOperatorCPUUsage(prometheusClient, m.Name, memberOperator),
+				queries.QueryMemberOperatorMemoryUsage(prometheusClient, m.Name, memberOperator),
+			)
+		}
+	}
+	// add the queries declared in the scenario, the queries that use the workload placeholders are added for each workload
+	placeholders := queries.Placeholders{
+		HostNamespace:   cfg.HostOperatorNamespace,
+		MemberNamespace: cfg.MemberOperatorNamespace,
+	}
+	for _, q := range sc.Metrics.Queries {
+		if !queries.UsesWorkload(q.Query) {
+			metricsInstance.AddQueries(newQuery(term, prometheusClient, q, placeholders))
+			continue
+		}
+		for _, w := range monitoredWorkloads {
+			workloadPlaceholders := placeholders
+			workloadPlaceholders.Namespace = w.Namespace
+			workloadPlaceholders.Workload = w.Name
+			workloadPlaceholders.WorkloadType = string(w.Kind)
+			metricsInstance.AddQueries(newQuery(term, prometheusClient, q, workloadPlaceholders))
+		}
 	}
 
 	// redirect stdout and stderr to files due to issue with progress bars and client go logging for messages like
@@ -253,102 +532,240 @@ func setup(cmd *cobra.Command, _ []string) { // nolint:gocyclo
 	}
 	os.Stdout = stdOutFile
 	os.Stderr = stdErrFile
-	term.AddPreFatalExitHook(func() {
+	restoreStdStreams := func() {
 		// restore stdout and stderr to originals
 		os.Stdout = tempStdout
 		os.Stderr = tempStderr
-	})
+	}
+	term.AddPreFatalExitHook(restoreStdStreams)
+
+	// tracker records the progress of the phases, it is served on the --listen address
+	tracker := status.NewTracker()
+	if listenAddr != "" {
+		statusServer, err := status.Serve(listenAddr, tracker)
+		if err != nil {
+			term.Fatalf(err, "unable to start the status server")
+		}
+		defer statusServer.Shutdown() // nolint:errcheck
+		term.Infof("📡 status of the run served on http://%[1]s/status and http://%[1]s/metrics", statusServer.Addr)
+	}
+
+	// from now on, a SIGINT or SIGTERM stops the run gracefully and its partial results are written
+	run := notifyInterruption(term, gracePeriod, tracker.Interrupted, restoreStdStreams)
+	defer run.stop()
 
 	// start gathering metrics
-	stopMetrics := metricsInstance.StartGathering()
+	stopMetrics := metricsInstance.StartGathering(run.ctx)
 
 	// gather and write results
-	resultsWriter := results.New(term)
-
+	resultsWriter := results.New(term, outputFormats...)
+
+	// arrivals is set when the signups are started at a given rate
+	var arrivals *arrivalStats
+	// byMember is the provisioning time of the signups by member cluster
+	var byMember *provisioningStats
+	// byTier is only set when the spaces are created in the tiers of a distribution, it is the provisioning time of the signups by tier
+	var byTier *provisioningStats
+	// rolloutStats is only set once the tier update is rolled out, between rolloutStarted and rolloutEnded
+	var rolloutStats *rollout.Stats
+	var rolloutStarted, rolloutEnded time.Time
+	// soakStats is only set once the soak is started
+	var soakStats *churn.Stats
+	// proxyStats are the round trips and the rejections through the API proxy of the template phases, by phase
+	proxyStats := map[string]*proxy.Stats{}
+	// verdicts are the pass or fail results of the limits of the budget
+	var verdicts []results.Result
+	// stageResults are the provisioning stages rebuilt from the cluster once all users are provisioned
+	var stageResults []results.Result
+	// timings records the time spent on each user by each phase
+	timings := latency.NewRecorder()
+	phaseLatencies := func() []results.Result {
+		latencies := []results.Result{}
+		for _, p := range sc.Phases {
+			latencies = append(latencies, timings.Distribution(p.Name).Summary(phaseLabel(sc, p), p.Name)...)
+		}
+		return latencies
+	}
 	outputResults := func() {
-		addAndOutputResults(term, resultsWriter, func() [][]string { return generalResultsInfo }, metricsInstance.ComputeResults)
+		resultFuncs := []func() []results.Result{func() []results.Result { return generalResultsInfo }, phaseLatencies}
+		if arrivals != nil {
+			resultFuncs = append(resultFuncs, arrivals.results)
+		}
+		if perMember && byMember != nil {
+			resultFuncs = append(resultFuncs, byMember.results)
+		}
+		if byTier != nil {
+			resultFuncs = append(resultFuncs, byTier.results)
+		}
+		if regsvcClient != nil {
+			resultFuncs = append(resultFuncs, func() []results.Result { return regsvcClient.Stats.Results(sc.Phases[0].Name) })
+		}
+		for _, p := range sc.Phases {
+			if stats, ok := proxyStats[p.Name]; ok {
+				phase := p.Name
+				resultFuncs = append(resultFuncs, func() []results.Result { return stats.Results(phase) })
+			}
+		}
+		if rolloutStats != nil {
+			resultFuncs = append(resultFuncs, func() []results.Result { return rolloutStats.Results(rolloutPhase) })
+		}
+		if soakStats != nil {
+			resultFuncs = append(resultFuncs, func() []results.Result { return soakStats.Results(soakPhase) })
+		}
+		resultFuncs = append(resultFuncs, func() []results.Result { return stageResults })
+		if err := timings.WriteCSV(cfg.TimingsFilepath()); err != nil {
+			term.Errorf(err, "failed to write the timings file")
+		} else {
+			term.Infof("Timings file: %s", cfg.TimingsFilepath())
+		}
+		if ledger != nil {
+			resultFuncs = append(resultFuncs, ledger.Summary)
+			if err := ledger.WriteCSV(cfg.FailuresFilepath()); err != nil {
+				term.Errorf(err, "failed to write the failures file")
+			} else {
+				term.Infof("Failures file: %s", cfg.FailuresFilepath())
+			}
+		}
+		// the aggregates are computed from the datapoints of the range queries when they succeed
+		metricsInstance.CollectRange()
+		if err := metricsInstance.WriteTimeSeries(cfg.TimeSeriesCSVFilepath(), cfg.TimeSeriesJSONFilepath()); err != nil {
+			term.Errorf(err, "failed to write the time series files")
+		} else {
+			term.Infof("Time series files: %s, %s", cfg.TimeSeriesCSVFilepath(), cfg.TimeSeriesJSONFilepath())
+		}
+		resultFuncs = append(resultFuncs, metricsInstance.ComputeResults)
+		if rolloutStats != nil {
+			// the usage of the member operators while they update the namespaces of the users
+			resultFuncs = append(resultFuncs, func() []results.Result {
+				return metricsInstance.WindowResults(rolloutPhase, "Tier Rollout", rolloutStarted, rolloutEnded, cfg.MemberOperatorWorkload)
+			})
+		}
+		if sc.Soak != nil {
+			// the growth of the memory usage over the hours of a soak tells the leaks apart from the usage of the population
+			resultFuncs = append(resultFuncs, metricsInstance.MemoryGrowth)
+		}
+		// the verdicts of the budget are computed from all the other results
+		resultFuncs = append(resultFuncs, func() []results.Result {
+			verdicts = runBudget.Verdicts(resultsWriter.All())
+			return verdicts
+		})
+		addAndOutputResults(term, resultsWriter, resultFuncs...)
+		series := metricsInstance.Series()
+		if rolloutStats != nil {
+			series = append(series, rolloutStats.Series())
+		}
+		title := fmt.Sprintf("Setup run %s%s", cfg.StartedTimestamp(), cfg.Testname)
+		if run.interrupted() {
+			title += " (interrupted)"
+		}
+		err := report.Write(cfg.ReportFilepath(), report.Data{
+			Title:   title,
+			Results: resultsWriter.All(),
+			Series:  series,
+			Spans:   timings.Spans(),
+			Flags:   runManifest.Flags,
+		})
+		if err != nil {
+			term.Errorf(err, "failed to write the report file")
+		} else {
+			term.Infof("Report file: %s", cfg.ReportFilepath())
+		}
 	}
 	// ensure metrics are dumped even if there's a fatal error
 	term.AddPreFatalExitHook(outputResults)
 
 	uip := uiprogress.New()
-	uip.Start()
+	if showProgress {
+		uip.Start()
+	}
 
-	// start the progress bars and work in go routines
+	// start the progress bars and work in go routines, each phase of the scenario processes its users concurrently
 	var wg sync.WaitGroup
-
-	concurrentUserSignups := 10
-	usersignupBar := addProgressBar(uip, "user signups", numberOfUsers)
-	signupUserFunc := func(cl client.Client, curUserNum int, username string) {
-		if err := users.Create(cl, username, cfg.HostOperatorNamespace, cfg.MemberOperatorNamespace); err != nil {
-			term.Fatalf(err, "failed to provision user '%s'", username)
+	phaseBars := make([]*userProgressBar, len(sc.Phases))
+	for i, p := range sc.Phases {
+		userCount := p.UserCount(sc.Users)
+		if userCount == 0 {
+			continue
 		}
-
-		if err := wait.ForSpace(cl, username); err != nil {
-			term.Fatalf(err, "space '%s' was not ready or not found", username)
+		phaseBars[i] = addProgressBar(uip, p.Name, userCount)
+		up := newUserPhase(p, phaseBars[i], timings, tracker, distribution, userTiers, regsvcClient, userClients, scheme)
+		// the users left out of the phase are not processed, so the status of the phase only counts the other ones
+		skipped := up.skipped(userCount)
+		term.Event(terminal.Event{Type: terminal.PhaseStarted, Phase: p.Name, Attributes: map[string]interface{}{"kind": p.Kind, "users": userCount - skipped, "skippedUsers": skipped}})
+		tracker.PhaseStarted(p.Name, userCount-skipped)
+		// the phase is complete once all its routines are done
+		var phaseWg sync.WaitGroup
+		if up.members != nil {
+			byMember = up.members
 		}
-	}
-	userSignupRoutine := userRoutine(term, usersignupBar, signupUserFunc)
-	splitToMultipleRoutines(&wg, concurrentUserSignups, userSignupRoutine)
-
-	var idlerBar *userProgressBar
-	if !skipIdlerSetup {
-		concurrentIdlerSetups := 3
-		idlerBar = addProgressBar(uip, "idler setup", numberOfUsers)
-		updateIdlerFunc := func(cl client.Client, curUserNum int, username string) {
-			// update Idlers timeout to kill workloads faster to reduce impact of memory/cpu usage during testing
-			if err := idlers.UpdateTimeout(cl, username, idlerDuration); err != nil {
-				term.Fatalf(err, "failed to update idlers for user '%s'", username)
-			}
+		if up.tiers != nil {
+			byTier = up.tiers
+		}
+		if up.proxyStats != nil {
+			proxyStats[p.Name] = up.proxyStats
 		}
-		ur := userRoutine(term, idlerBar, updateIdlerFunc)
-		splitToMultipleRoutines(&wg, concurrentIdlerSetups, ur)
+		if up.arrivals != nil {
+			arrivals = up.arrivals
+			startOnSchedule(&phaseWg, run, term, checkpoints, ledger, up, p.Concurrency, p.Arrival.GetProfile().Schedule(userCount))
+		} else {
+			splitToMultipleRoutines(&phaseWg, p.Concurrency, userRoutine(run, term, checkpoints, ledger, up))
+		}
+		wg.Add(1)
+		go func(name string, started time.Time) {
+			defer wg.Done()
+			phaseWg.Wait()
+			term.Event(terminal.Event{Type: terminal.PhaseCompleted, Phase: name, Duration: time.Since(started), Attributes: map[string]interface{}{"completedUsers": timings.Distribution(name).Count()}})
+			tracker.PhaseCompleted(name)
+		}(p.Name, time.Now())
 	}
 
-	var defaultUserSetupBar *userProgressBar
-	concurrentUserSetups := 5
-	if defaultTemplateUsers > 0 {
-		defaultUserSetupBar = addProgressBar(uip, "setup default template users", defaultTemplateUsers)
-		setupDefaultUsersFunc := func(cl client.Client, curUserNum int, username string) {
-			if curUserNum <= defaultTemplateUsers {
-				if err := resources.CreateUserResourcesFromTemplateFiles(cl, scheme, username, []string{defaultTemplatePath}); err != nil {
-					term.Fatalf(err, "failed to create default template resources for user '%s'", username)
-				}
-			}
-		}
-		ur := userRoutine(term, defaultUserSetupBar, setupDefaultUsersFunc)
-		splitToMultipleRoutines(&wg, concurrentUserSetups, ur)
+	defer stopMetrics()
+	wg.Wait()
+	if showProgress {
+		uip.Stop()
 	}
 
-	var customUserSetupBar *userProgressBar
-	if customTemplateUsers > 0 && len(customTemplatePaths) > 0 {
-		customUserSetupBar = addProgressBar(uip, "setup custom template users", customTemplateUsers)
-		setupCustomUsersFunc := func(cl client.Client, curUserNum int, username string) {
-			if curUserNum <= customTemplateUsers {
-				if err := resources.CreateUserResourcesFromTemplateFiles(cl, scheme, username, customTemplatePaths); err != nil {
-					term.Fatalf(err, "failed to create custom template resources for user '%s'", username)
-				}
-			}
-		}
-		ur := userRoutine(term, customUserSetupBar, setupCustomUsersFunc)
-		splitToMultipleRoutines(&wg, concurrentUserSetups, ur)
+	restoreStdStreams()
+
+	if run.interrupted() {
+		term.Infof("🛑 provisioning interrupted")
+	} else {
+		term.Infof("🏁 done provisioning users")
 	}
 
-	defer close(stopMetrics)
-	wg.Wait()
-	uip.Stop()
+	// the duration of each provisioning stage is computed from the timestamps recorded in the cluster rather than from the client clock
+	timelines, err := timeline.Collect(cl, cfg.HostOperatorNamespace, memberNamespaces(distribution.Members()), checkpoints.Usernames(journal.Phase(sc.Phases[0].Name)))
+	if err != nil {
+		term.Errorf(err, "failed to collect the provisioning timelines")
+	} else {
+		stageResults = timeline.Summarize(timelines)
+	}
 
-	// restore stdout and stderr to originals
-	os.Stdout = tempStdout
-	os.Stderr = tempStderr
+	// roll a new revision of the tier out to the Spaces of the users while the metrics are gathered
+	if sc.TierUpdate != nil && !run.interrupted() {
+		rolloutStats, rolloutStarted = rolloutTier(run, term, cl, sc, distribution, timings, tracker, checkpoints.Usernames(journal.Phase(sc.Phases[0].Name)))
+		rolloutEnded = time.Now()
+	}
 
-	term.Infof("🏁 done provisioning users")
+	// keep the users churning through their lifecycle while the metrics are gathered
+	if sc.Soak != nil && !run.interrupted() {
+		engine, err := newSoak(term, cl, sc, distribution, userTiers, ledger, checkpoints.Usernames(journal.Phase(sc.Phases[0].Name)))
+		if err != nil {
+			term.Fatalf(err, "unable to start the soak")
+		}
+		soakStats = engine.Stats()
+		term.Infof("🔁 churning the users for %s...", sc.Soak.GetDuration())
+		term.Event(terminal.Event{Type: terminal.PhaseStarted, Phase: soakPhase, Attributes: map[string]interface{}{"kind": soakPhase, "population": sc.Soak.Population}})
+		started := time.Now()
+		engine.Run(run.ctx, run.draining)
+		term.Event(terminal.Event{Type: terminal.PhaseCompleted, Phase: soakPhase, Duration: time.Since(started)})
+		term.Infof("🏁 done churning users")
+	}
 
 	// continue gathering metrics for some time after creating all users and resources since memory usage was observed to continue changing
-	if !skipAdditionalWait {
-		additionalMetricsDuration := 15 * time.Minute
-		term.Infof("Continuing to gather metrics for %s...", additionalMetricsDuration)
-		time.Sleep(additionalMetricsDuration)
+	if settleDuration := sc.GetSettleDuration(); !skipAdditionalWait && settleDuration > 0 && !run.interrupted() {
+		term.Infof("Continuing to gather metrics for %s...", settleDuration)
+		run.sleep(settleDuration)
 	}
 
 	// =====================
@@ -356,38 +773,373 @@ func setup(cmd *cobra.Command, _ []string) { // nolint:gocyclo
 	// =====================
 
 	totalRunningTime := time.Since(setupStartTime)
-	if idlerBar != nil {
-		IdlerUpdateTime = idlerBar.timeSpent
+	// the averages and the latencies only include the users that were successfully processed during this run
+	for _, p := range sc.Phases {
+		if p.Kind == scenario.Signups {
+			continue
+		}
+		generalResultsInfo = append(generalResultsInfo, latency.Result(phaseLabel(sc, p), results.Average, p.Name, timings.Distribution(p.Name).Mean()))
 	}
-	if defaultUserSetupBar != nil {
-		DefaultApplyTimePerUser = defaultUserSetupBar.timeSpent
+	generalResultsInfo = append(generalResultsInfo, runningTimeResult(totalRunningTime))
+	if run.interrupted() {
+		generalResultsInfo = append(generalResultsInfo, interruptedResult(run.interruptedAt().Sub(setupStartTime)))
 	}
-	if customUserSetupBar != nil {
-		CustomApplyTimePerUser = customUserSetupBar.timeSpent
+
+	outputResults()
+	if run.interrupted() {
+		term.Infof("🛑 the run was interrupted, the results only include the users processed so far (use --resume %s to continue the run)", checkpoints.Path())
+		os.Exit(interruptedExitCode)
 	}
+	exitOnFailedVerdicts(term, verdicts)
+	term.Infof("👋 have fun!")
+}
 
-	generalResultsInfo = append(generalResultsInfo,
-		[]string{"Average Idler Update Time (s)", fmt.Sprintf("%.2f", IdlerUpdateTime.Seconds()/float64(numberOfUsers))},
-		[]string{"Average Time Per User - default (s)", fmt.Sprintf("%.2f", DefaultApplyTimePerUser.Seconds()/float64(numberOfUsers))},
-		[]string{"Average Time Per User - custom (s)", fmt.Sprintf("%.2f", CustomApplyTimePerUser.Seconds()/float64(numberOfUsers))},
-		[]string{"Total Running Time (m)", fmt.Sprintf("%f", totalRunningTime.Minutes())},
+// exitOnFailedVerdicts lists the limits of the budget that were crossed and exits with the budget exit code, if any
+func exitOnFailedVerdicts(term terminal.Terminal, verdicts []results.Result) {
+	failed := 0
+	for _, v := range verdicts {
+		if v.Verdict == results.Fail {
+			term.Infof("%s: %s", v.Name, v.Verdict)
+			failed++
+		}
+	}
+	if failed == 0 {
+		return
+	}
+	term.Errorf(fmt.Errorf("%d limit(s) crossed", failed), "💥 the budget was exceeded")
+	os.Exit(budget.ExitCode)
+}
+
+// resumeFlag sets the value of the given flag to the one recorded by the interrupted run, so that the resumed run provisions the
+// remaining users the same way. The flag must not be set to another value. A flag that was not recorded by the interrupted run
+// has its default value.
+func resumeFlag(cmd *cobra.Command, term terminal.Terminal, name string, value *string, recorded string) {
+	if recorded == "" {
+		recorded = cmd.Flags().Lookup(name).DefValue
+	}
+	if cmd.Flags().Changed(name) && *value != recorded {
+		term.Fatalf(fmt.Errorf("the interrupted run was started with '--%s=%s'", name, recorded), "invalid '--%s' flag to resume the run", name)
+	}
+	*value = recorded
+}
+
+// scenarioFlags are the flags that declare the run, they are replaced by a scenario file
+var scenarioFlags = []string{"users", cfg.DefaultTemplateUsersParam, cfg.CustomTemplateUsersParam, "template", "skip-idler", "idler-timeout", "workloads",
+	"signup-rate", "signup-ramp", "signup-ramp-from", "signup-ramp-duration", "signup-ramp-steps", "signup-bursts", "metrics-step", "queries",
+	"tier-update", "tier-rollout-timeout", "soak-duration", "soak-rates", "soak-population", "soak-concurrency"}
+
+// scenarioFromFlags returns the scenario declared by the flags: the signups (at a given rate if set), the idler setup (unless skipped)
+// and the default and custom template phases
+func scenarioFromFlags() *scenario.Scenario {
+	phases := []scenario.Phase{
+		{Name: "signups", Kind: scenario.Signups},
+	}
+	if signupArrival.Rate != "" || signupArrival.Ramp != "" || len(signupArrival.Bursts) > 0 {
+		arrival := signupArrival
+		phases[0].Arrival = &arrival
+	}
+	if !skipIdlerSetup {
+		phases = append(phases, scenario.Phase{Name: "idler-setup", Kind: scenario.Idlers, IdlerTimeout: idlerTimeout})
+	}
+	defaultUsers := defaultTemplateUsers
+	customUsers := customTemplateUsers
+	phases = append(phases,
+		// add the default user-workloads.yaml file automatically
+		scenario.Phase{Name: "default", Kind: scenario.Templates, Users: &defaultUsers, Templates: []string{defaultTemplatePath}},
+		scenario.Phase{Name: "custom", Kind: scenario.Templates, Users: &customUsers, Templates: customTemplatePaths},
 	)
+	var update *scenario.TierUpdate
+	if tierUpdate != "" || tierRolloutTimeout != "" {
+		update = &scenario.TierUpdate{
+			Tier:    tierUpdate,
+			Timeout: tierRolloutTimeout,
+		}
+	}
+	var soak *scenario.Soak
+	if soakDuration != "" || len(soakRates) > 0 {
+		soak = &scenario.Soak{
+			Duration:    soakDuration,
+			Population:  soakPopulation,
+			Concurrency: soakConcurrency,
+			Rates:       map[string]string{},
+		}
+		for _, r := range soakRates {
+			op, rate, _ := strings.Cut(r, "=")
+			soak.Rates[op] = rate
+		}
+	}
+	return &scenario.Scenario{
+		Users:      numberOfUsers,
+		Phases:     phases,
+		TierUpdate: update,
+		Soak:       soak,
+		Metrics: scenario.Metrics{
+			Step:      metricsStep,
+			Workloads: workloads,
+		},
+	}
+}
 
-	outputResults()
-	term.Infof("👋 have fun!")
+// workloadObject returns an empty object of the API type of the given kind of workload
+func workloadObject(kind queries.WorkloadKind) client.Object {
+	switch kind {
+	case queries.StatefulSet:
+		return &appsv1.StatefulSet{}
+	case queries.DaemonSet:
+		return &appsv1.DaemonSet{}
+	default:
+		return &appsv1.Deployment{}
+	}
+}
+
+// newQuery returns the given query of the scenario with its placeholders replaced by their values
+func newQuery(term terminal.Terminal, prometheusClient prometheus.API, q scenario.Query, p queries.Placeholders) queries.Query {
+	name, err := queries.Render(q.Name, p)
+	if err != nil {
+		term.Fatalf(err, "invalid query name '%s'", q.Name)
+	}
+	query, err := queries.Render(q.Query, p)
+	if err != nil {
+		term.Fatalf(err, "invalid query '%s'", q.Name)
+	}
+	return queries.New(prometheusClient, name, query, queries.ResultType(q.ResultType), queries.Reduction(q.Reduction))
 }
 
-func usersWithinBounds(term terminal.Terminal, value int, templateType string) {
-	if value < 0 || value > numberOfUsers {
-		term.Fatalf(fmt.Errorf("value must be between 0 and %d", numberOfUsers), "invalid '%s' users value '%d'", templateType, value)
+// phaseLabel returns the name of the time spent per user by the given phase in the results
+func phaseLabel(sc *scenario.Scenario, p scenario.Phase) string {
+	switch p.Kind {
+	case scenario.Signups:
+		return "Provisioning Time Per User"
+	case scenario.Idlers:
+		idlerPhases := 0
+		for _, other := range sc.Phases {
+			if other.Kind == scenario.Idlers {
+				idlerPhases++
+			}
+		}
+		if idlerPhases == 1 {
+			return "Idler Update Time"
+		}
+		return fmt.Sprintf("Idler Update Time - %s", p.Name)
+	default:
+		return fmt.Sprintf("Time Per User - %s", p.Name)
+	}
+}
+
+// newUserPhase returns the action applied to each user by the given phase of the scenario, the users sign up through the
+// registration service when its client is set, their spaces are created by the setup in the tiers of the tier distribution
+// when it is set, and the templates are applied by the users through the API proxy when the user clients are set
+func newUserPhase(p scenario.Phase, bar *userProgressBar, timings *latency.Recorder, tracker *status.Tracker, distribution *members.Distribution, userTiers *tiers.Distribution, regsvcClient *regsvc.Client, userClients *proxy.Clients, scheme *runtime.Scheme) userPhase {
+	up := userPhase{
+		name:    journal.Phase(p.Name),
+		bar:     bar,
+		timings: timings,
+		tracker: tracker,
+	}
+	switch p.Kind {
+	case scenario.Signups:
+		var arrivals *arrivalStats
+		if p.Arrival != nil {
+			arrivals = newArrivalStats(p.Name)
+			up.arrivals = arrivals
+		}
+		byMember := newProvisioningStats(p.Name, "member")
+		up.members = byMember
+		var byTier *provisioningStats
+		// the UserSignups skip the creation of their Space when the setup creates it in the tier of the user
+		create, approve := users.Create, users.Approve
+		if userTiers != nil {
+			byTier = newProvisioningStats(p.Name, "tier")
+			up.tiers = byTier
+			create, approve = users.CreateWithoutSpace, users.ApproveWithoutSpace
+		}
+		up.action = func(ctx context.Context, cl client.Client, curUserNum int, username string) error {
+			created := time.Now()
+			targetCluster := distribution.TargetCluster(curUserNum)
+			var token string
+			if regsvcClient != nil {
+				var err error
+				if token, err = regsvc.Token(username); err != nil {
+					return err
+				}
+				if err := regsvcClient.Signup(ctx, token); err != nil {
+					return errors.Wrapf(err, "failed to sign up user '%s' through the registration service", username)
+				}
+				signup, err := regsvcClient.WaitUntilCreated(ctx, token)
+				if err != nil {
+					return errors.Wrapf(err, "failed to sign up user '%s' through the registration service", username)
+				}
+				// the UserSignup, Space and MasterUserRecord of the user are looked up by its username in the rest of the run
+				if signup.Name != username {
+					return fmt.Errorf("the registration service created usersignup '%s' for user '%s', it must be named after the user", signup.Name, username)
+				}
+				// the UserSignups created by the registration service are approved like the ones created by the setup
+				if err := approve(ctx, cl, username, cfg.HostOperatorNamespace, targetCluster); err != nil {
+					return errors.Wrapf(err, "failed to provision user '%s'", username)
+				}
+			} else if err := create(ctx, cl, username, cfg.HostOperatorNamespace, targetCluster); err != nil && !k8serrors.IsAlreadyExists(err) {
+				return errors.Wrapf(err, "failed to provision user '%s'", username)
+			}
+
+			// in open-loop mode, the approval of the UserSignup is awaited as well to tell the queueing delay from the provisioning latency
+			approved := created
+			if arrivals != nil {
+				if err := wait.ForUserSignupApproved(ctx, cl, username); err != nil {
+					return errors.Wrapf(err, "usersignup '%s' was not approved", username)
+				}
+				approved = time.Now()
+			}
+
+			var tier string
+			if userTiers != nil {
+				tier = userTiers.Tier(curUserNum)
+				if err := tiers.CreateSpace(ctx, cl, username, cfg.HostOperatorNamespace, tier, targetCluster); err != nil {
+					return errors.Wrapf(err, "failed to create the space of user '%s' in tier '%s'", username, tier)
+				}
+			}
+
+			if regsvcClient != nil {
+				if err := regsvcClient.WaitUntilReady(ctx, token); err != nil {
+					return errors.Wrapf(err, "signup of user '%s' was not ready", username)
+				}
+			} else if err := wait.ForSpace(ctx, cl, username); err != nil {
+				return errors.Wrapf(err, "space '%s' was not ready or not found", username)
+			}
+			ready := time.Now()
+			if arrivals != nil {
+				arrivals.record(curUserNum, created, approved, ready)
+			}
+			// the host operator picked the member cluster when the UserSignup has no target cluster
+			if targetCluster == "" {
+				var err error
+				if targetCluster, err = members.Placement(ctx, cl, cfg.HostOperatorNamespace, username); err != nil {
+					return errors.Wrapf(err, "unable to lookup the member cluster of user '%s'", username)
+				}
+			}
+			byMember.record(targetCluster, ready.Sub(created))
+			if byTier != nil {
+				byTier.record(tier, ready.Sub(created))
+			}
+			return nil
+		}
+		up.done = func(cl client.Client, curUserNum int, username string) (bool, error) {
+			return wait.HasSpaceReady(cl, username)
+		}
+		up.object = func(username string) string {
+			return "UserSignup " + username
+		}
+	case scenario.Idlers:
+		timeout := p.GetIdlerTimeout()
+		up.action = func(ctx context.Context, cl client.Client, curUserNum int, username string) error {
+			// update Idlers timeout to kill workloads faster to reduce impact of memory/cpu usage during testing
+			return errors.Wrapf(idlers.UpdateTimeout(ctx, cl, username, timeout), "failed to update idlers for user '%s'", username)
+		}
+		up.done = func(cl client.Client, curUserNum int, username string) (bool, error) {
+			return idlers.HasTimeout(cl, username, timeout)
+		}
+		up.object = func(username string) string {
+			return fmt.Sprintf("Idler %s-dev", username)
+		}
+	case scenario.Templates:
+		templatePaths := p.Templates
+		up.action = func(ctx context.Context, cl client.Client, curUserNum int, username string) error {
+			return errors.Wrapf(resources.CreateUserResourcesFromTemplateFiles(ctx, cl, scheme, username, templatePaths), "failed to create %s template resources for user '%s'", p.Name, username)
+		}
+		if userClients != nil {
+			stats := proxy.NewStats()
+			up.proxyStats = stats
+			up.action = func(ctx context.Context, cl client.Client, curUserNum int, username string) error {
+				userCl, err := userClients.For(username, stats)
+				if err != nil {
+					return err
+				}
+				err = resources.CreateUserResourcesAs(ctx, cl, userCl, scheme, username, templatePaths)
+				stats.RecordRejections(err)
+				return errors.Wrapf(err, "failed to create %s template resources as user '%s'", p.Name, username)
+			}
+		}
+		up.done = func(cl client.Client, curUserNum int, username string) (bool, error) {
+			return resources.UserResourcesExist(cl, scheme, username, templatePaths)
+		}
+		up.object = func(username string) string {
+			return fmt.Sprintf("Namespace %s-dev", username)
+		}
+	}
+	// the idlers and the templates apply to the dev namespace of the users, which the spaces of some tiers do not have
+	if userTiers != nil && (p.Kind == scenario.Idlers || p.Kind == scenario.Templates) {
+		up.skip = func(curUserNum int) bool {
+			return !userTiers.HasDevNamespace(curUserNum)
+		}
 	}
+	return up
 }
 
-func addAndOutputResults(term terminal.Terminal, resultsWriter *results.Results, r ...func() [][]string) {
-	// add header row
-	resultsWriter.AddResults([][]string{
-		{"Item", "Value"},
+// secretFlags are the flags whose values are not recorded in the run manifest
+var secretFlags = []string{"token"}
+
+// effectiveFlags returns the value of every flag of the command, including the default ones
+func effectiveFlags(cmd *cobra.Command) map[string]string {
+	flags := map[string]string{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		flags[f.Name] = f.Value.String()
+		for _, secret := range secretFlags {
+			if f.Name == secret && f.Value.String() != "" {
+				flags[f.Name] = "<redacted>"
+			}
+		}
 	})
+	return flags
+}
+
+// newTerminal returns the terminal of the --log-format flag. The json terminal keeps writing to the original stdout while stdout
+// is redirected to a file during the provisioning, so that the events of a run can be followed live.
+func newTerminal(cmd *cobra.Command) (terminal.Terminal, terminal.Format) {
+	term := terminal.New(cmd.InOrStdin, cmd.OutOrStdout, verbose)
+	format, err := terminal.ParseFormat(logFormat)
+	if err != nil {
+		term.Fatalf(err, "invalid log format")
+	}
+	if format == terminal.JSON {
+		out := cmd.OutOrStdout()
+		return terminal.NewJSON(cmd.InOrStdin, func() io.Writer { return out }, verbose), format
+	}
+	return term, format
+}
+
+// isTerminal returns true if the given file is a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseOutputFormats sets the formats of the results files from the --output flag
+func parseOutputFormats(term terminal.Terminal) {
+	var err error
+	if outputFormats, err = results.ParseFormats(outputs); err != nil {
+		term.Fatalf(err, "invalid output formats")
+	}
+}
+
+// runningTimeResult returns the total running time result, in minutes
+func runningTimeResult(d time.Duration) results.Result {
+	return results.Result{Name: "Running Time", Unit: results.Minutes, Aggregate: results.Total, Value: d.Minutes(), Precision: 6}
+}
+
+// interruptedResult returns the result that marks the results of an interrupted run, its value is the time from the start of
+// the setup until the interruption, in minutes
+func interruptedResult(d time.Duration) results.Result {
+	return results.Result{Name: "Interrupted After", Unit: results.Minutes, Value: d.Minutes(), Precision: 2}
+}
+
+// capitalize returns the given value with its first letter in upper case
+func capitalize(value string) string {
+	if value == "" {
+		return value
+	}
+	return strings.ToUpper(value[:1]) + value[1:]
+}
+
+func addAndOutputResults(term terminal.Terminal, resultsWriter *results.Results, r ...func() []results.Result) {
 	// add results
 	for _, result := range r {
 		resultsWriter.AddResults(result())
@@ -398,9 +1150,8 @@ func addAndOutputResults(term terminal.Terminal, resultsWriter *results.Results,
 }
 
 type userProgressBar struct {
-	mu        sync.Mutex
-	timeSpent time.Duration
-	bar       *uiprogress.Bar
+	mu  sync.Mutex
+	bar *uiprogress.Bar
 }
 
 func addProgressBar(uip *uiprogress.Progress, description string, total int) *userProgressBar {
@@ -420,12 +1171,6 @@ func (b *userProgressBar) Incr() (bool, int) {
 	return b.bar.Incr(), b.bar.Current()
 }
 
-func (b *userProgressBar) AddTimeSpent(d time.Duration) {
-	b.mu.Lock()
-	b.timeSpent += d
-	b.mu.Unlock()
-}
-
 func splitToMultipleRoutines(parent *sync.WaitGroup, concurrentRoutinesCount int, routine func(*sync.WaitGroup)) {
 	parent.Add(1)
 	go func() {
@@ -439,27 +1184,128 @@ func splitToMultipleRoutines(parent *sync.WaitGroup, concurrentRoutinesCount int
 	}()
 }
 
-func userRoutine(term terminal.Terminal, progressBar *userProgressBar, ua userAction) func(wg *sync.WaitGroup) {
+// userPhase is a step of the setup that is applied to each user
+type userPhase struct {
+	name    journal.Phase
+	bar     *userProgressBar
+	timings *latency.Recorder
+	tracker *status.Tracker
+	action  userAction
+	done    userCheck
+	// object returns the object that the action is applied to, it is reported when the action fails
+	object func(username string) string
+	// skip is only set when the phase does not apply to some of the users, it returns true for the users that are left out
+	skip func(curUserNum int) bool
+	// arrivals is only set when the users of the phase are started at a given rate
+	arrivals *arrivalStats
+	// members is only set for the signups, it records the provisioning time by member cluster
+	members *provisioningStats
+	// tiers is only set for the signups of a tier distribution, it records the provisioning time by tier
+	tiers *provisioningStats
+	// proxyStats is only set for the templates applied by the users through the API proxy
+	proxyStats *proxy.Stats
+}
+
+// skipped returns the number of users among the given number of users of the phase that the phase leaves out
+func (p userPhase) skipped(userCount int) int {
+	if p.skip == nil {
+		return 0
+	}
+	skipped := 0
+	for curUserNum := 1; curUserNum <= userCount; curUserNum++ {
+		if p.skip(curUserNum) {
+			skipped++
+		}
+	}
+	return skipped
+}
+
+func userRoutine(run *interruption, term terminal.Terminal, checkpoints *journal.Journal, ledger *failures.Ledger, phase userPhase) func(wg *sync.WaitGroup) {
 	return func(subgroup *sync.WaitGroup) {
 		aCl, _, _, err := cfg.NewClient(term, kubeconfig)
 		if err != nil {
 			term.Fatalf(err, "cannot create client")
 		}
 
-		hasMore, curUserNum := progressBar.Incr()
-		for hasMore {
-			username := fmt.Sprintf("%s-%04d", usernamePrefix, curUserNum)
+		// no new user is started once the run is interrupted
+		for !run.interrupted() {
+			hasMore, curUserNum := phase.bar.Incr()
+			if !hasMore {
+				break
+			}
+			processUser(run, term, aCl, checkpoints, ledger, phase, curUserNum)
+		}
+		subgroup.Done()
+	}
+}
 
-			startTime := time.Now()
+// processUser applies the action of the phase to the given user, unless it was already done by a previous run
+func processUser(run *interruption, term terminal.Terminal, cl client.Client, checkpoints *journal.Journal, ledger *failures.Ledger, phase userPhase, curUserNum int) {
+	username := fmt.Sprintf("%s-%04d", usernamePrefix, curUserNum)
 
-			ua(aCl, curUserNum, username)
+	if phase.skip != nil && phase.skip(curUserNum) {
+		term.Debugf("user '%s' is skipped for phase '%s'", username, phase.name)
+		return
+	}
 
-			timeSpent := time.Since(startTime)
-			progressBar.AddTimeSpent(timeSpent)
-			hasMore, curUserNum = progressBar.Incr()
+	// skip the users that a previous run recorded as completed, as long as the cluster confirms it
+	if checkpoints.IsCompleted(phase.name, curUserNum) {
+		completed, err := phase.done(cl, curUserNum, username)
+		if err != nil {
+			term.Fatalf(err, "failed to verify the checkpoint of user '%s' for phase '%s'", username, phase.name)
 		}
-		subgroup.Done()
+		if completed {
+			return
+		}
+		term.Debugf("user '%s' is recorded as completed for phase '%s' but is not, retrying", username, phase.name)
+	}
+
+	// a user that failed in another phase is not set up any further
+	if ledger != nil && ledger.HasFailed(username) {
+		return
+	}
+
+	startTime := time.Now()
+
+	// the ledger makes the first attempt and the retries, without a ledger the action is attempted once
+	attempts := 1
+	var err error
+	if ledger != nil {
+		attempts, err = ledger.Retry(run.ctx, func() error {
+			return phase.action(run.ctx, cl, curUserNum, username)
+		})
+	} else {
+		err = phase.action(run.ctx, cl, curUserNum, username)
+	}
+	if err != nil && run.ctx.Err() != nil {
+		// the user was given up at the end of the grace period of an interrupted run, it is left for a resumed run
+		term.Debugf("user '%s' was given up for phase '%s': %s", username, phase.name, err)
+		return
+	}
+	if err != nil {
+		if ledger == nil {
+			term.Fatalf(err, "%s failed", phase.name)
+		}
+		ledger.Add(string(phase.name), username, phase.object(username), attempts, err)
+		phase.tracker.UserFailed(string(phase.name))
+		term.Event(terminal.Event{Type: terminal.UserFailed, Phase: string(phase.name), User: username, Duration: time.Since(startTime), Attributes: map[string]interface{}{"attempts": attempts, "error": err.Error()}})
+		if exceededErr := ledger.Exceeded(); exceededErr != nil {
+			term.Fatalf(exceededErr, "too many failures, aborting the run")
+		}
+		return
+	}
+
+	duration := time.Since(startTime)
+	phase.timings.Record(string(phase.name), username, startTime, duration)
+	phase.tracker.UserCompleted(string(phase.name), duration)
+	term.Event(terminal.Event{Type: terminal.UserCompleted, Phase: string(phase.name), User: username, Duration: duration})
+	if err := checkpoints.Record(phase.name, curUserNum, username); err != nil {
+		term.Fatalf(err, "failed to record the checkpoint of user '%s' for phase '%s'", username, phase.name)
 	}
 }
 
-type userAction func(cl client.Client, curUserNum int, username string)
+// userAction applies a phase to the given user, it gives up when the context is done
+type userAction func(ctx context.Context, cl client.Client, curUserNum int, username string) error
+
+// userCheck returns true if the action of a phase is confirmed to be already done for the given user
+type userCheck func(cl client.Client, curUserNum int, username string) (bool, error)
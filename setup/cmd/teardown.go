@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/members"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/operators"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/users"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gosuri/uiprogress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	uninstallOperators bool
+	skipRestoreConfig  bool
+	clusterConfigPath  string
+	teardownTestname   string
+)
+
+func newTeardownCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "teardown",
+		Short:         "remove the users created by the setup and restore the cluster configuration",
+		SilenceErrors: true,
+		SilenceUsage:  false,
+		Args:          cobra.NoArgs,
+		Run:           teardown,
+	}
+
+	cmd.Flags().StringVar(&usernamePrefix, "username", usernamePrefix, "the prefix of the usersignup names to delete")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "absolute path to the kubeconfig file")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "if 'debug' traces should be displayed in the console")
+	cmd.Flags().StringVar(&cfg.HostOperatorNamespace, "host-ns", cfg.DefaultHostNS, "the namespace of Host operator")
+	cmd.Flags().StringVar(&cfg.MemberOperatorNamespace, "member-ns", cfg.DefaultMemberNS, "the namespace of the Member operator")
+	cmd.Flags().BoolVar(&interactive, "interactive", true, "if user is prompted to confirm all actions")
+	cmd.Flags().BoolVar(&uninstallOperators, "uninstall-operators", false, "uninstall the operators that were installed by the setup")
+	cmd.Flags().IntVar(&operatorsLimit, "operators-limit", len(operators.Templates), "can be specified to limit the number of operators to uninstall")
+	cmd.Flags().BoolVar(&skipRestoreConfig, "skip-restore-config", false, "keep the default space tier and OLM configuration set by the setup")
+	cmd.Flags().StringVar(&clusterConfigPath, "cluster-config", "", "path to the cluster configuration recorded before the first setup run (defaults to the one in the results directory)")
+	cmd.Flags().StringSliceVar(&outputs, "output", []string{string(results.CSV)}, "the formats of the results files, any of csv, json, junit and openmetrics eg. \"--output csv,junit\"")
+	cmd.Flags().StringVar(&teardownTestname, "testname", "teardown", "a name that is added as a suffix to the result file names")
+	cmd.Flags().StringVar(&logFormat, "log-format", string(terminal.Text), "the format of the console output: 'text', or 'json' for a JSON object per line with the operator removals and the errors of the teardown")
+
+	return cmd
+}
+
+func teardown(cmd *cobra.Command, _ []string) {
+	cmd.SilenceUsage = true
+	term, format := newTerminal(cmd)
+	// the progress bar is only displayed on a terminal, it would garble the output of a CI job or of the json log format
+	showProgress := format == terminal.Text && isTerminal(os.Stdout)
+
+	// call cfg.Init() to initialize variables that are dependent on any flags eg. testname
+	cfg.Testname = teardownTestname
+	cfg.Init(term)
+	parseOutputFormats(term)
+	if clusterConfigPath == "" {
+		clusterConfigPath = cfg.ClusterConfigFilepath()
+	}
+
+	if operatorsLimit > len(operators.Templates) {
+		term.Fatalf(fmt.Errorf("the operators limit value must be less than or equal to '%d'", len(operators.Templates)), "invalid operators limit value '%d'", operatorsLimit)
+	}
+
+	term.Infof("🕖 initializing...\n")
+	cl, config, scheme, err := cfg.NewClient(term, kubeconfig)
+	if err != nil {
+		term.Fatalf(err, "cannot create client")
+	}
+
+	usernames, err := users.ListWithPrefix(cl, usernamePrefix, cfg.HostOperatorNamespace)
+	if err != nil {
+		term.Fatalf(err, "unable to list the usersignups with prefix '%s'", usernamePrefix)
+	}
+
+	term.Infof("Users to delete:           '%d'", len(usernames))
+	term.Infof("Uninstall operators:       '%t'", uninstallOperators)
+	term.Infof("Restore cluster config:    '%t'", !skipRestoreConfig)
+	term.Infof("Host Operator Namespace:   '%s'", cfg.HostOperatorNamespace)
+	term.Infof("Member Operator Namespace: '%s'\n", cfg.MemberOperatorNamespace)
+
+	if interactive && !term.PromptBoolf("🗑  delete %d users with prefix '%s' from %s", len(usernames), usernamePrefix, config.Host) {
+		return
+	}
+
+	teardownStartTime := time.Now()
+
+	// =====================
+	// begin user deletion
+	// =====================
+	term.Infof("🧹 deleting users...")
+	timings := latency.NewRecorder()
+	if len(usernames) > 0 {
+		// the users may be provisioned on any member cluster, eg. with --member-distribution
+		readyMembers, err := members.Ready(context.TODO(), cl, cfg.HostOperatorNamespace)
+		if err != nil {
+			term.Fatalf(err, "unable to lookup the member clusters")
+		}
+		uip := uiprogress.New()
+		if showProgress {
+			uip.Start()
+		}
+		deleteUsers(term, cl, memberNamespaces(readyMembers), usernames, addProgressBar(uip, "user deletions", len(usernames)), timings)
+		if showProgress {
+			uip.Stop()
+		}
+	}
+	// the users banned by a soak would be banned again by a later run with the same usernames
+	banned, err := users.DeleteBanned(cl, usernamePrefix, cfg.HostOperatorNamespace)
+	if err != nil {
+		term.Fatalf(err, "unable to delete the banned users with prefix '%s'", usernamePrefix)
+	}
+	if banned > 0 {
+		term.Infof("deleted %d banned user(s)", banned)
+	}
+	deprovisioningTime := time.Since(teardownStartTime)
+	term.Infof("🏁 done deleting users")
+
+	// =====================
+	// begin operators and configuration cleanup
+	// =====================
+	if uninstallOperators {
+		term.Infof("⏳ uninstalling operators...")
+		templatePaths := []string{}
+		for i := 0; i < operatorsLimit; i++ {
+			templatePaths = append(templatePaths, "setup/operators/installtemplates/"+operators.Templates[i])
+		}
+		if err := operators.EnsureOperatorsUninstalled(term, cl, scheme, templatePaths); err != nil {
+			term.Fatalf(err, "failed to uninstall operators")
+		}
+	}
+
+	if !skipRestoreConfig {
+		term.Infof("Restoring the default space tier and OLM configuration...")
+		if _, err := os.Stat(clusterConfigPath); os.IsNotExist(err) {
+			term.Infof("no cluster configuration was recorded at '%s', nothing to restore", clusterConfigPath)
+		} else if err := cfg.RestoreClusterConfig(cl, clusterConfigPath); err != nil {
+			term.Fatalf(err, "unable to restore the cluster configuration from '%s'", clusterConfigPath)
+		}
+	}
+
+	totalRunningTime := time.Since(teardownStartTime)
+	deletedUsers := len(usernames)
+	deletions := timings.Distribution(deletionPhase)
+	var throughput float64
+	if deletedUsers > 0 {
+		throughput = float64(deletedUsers) / deprovisioningTime.Minutes()
+	}
+	if err := timings.WriteCSV(cfg.TimingsFilepath()); err != nil {
+		term.Errorf(err, "failed to write the timings file")
+	} else {
+		term.Infof("Timings file: %s", cfg.TimingsFilepath())
+	}
+	resultsWriter := results.New(term, outputFormats...)
+	addAndOutputResults(term, resultsWriter, func() []results.Result {
+		return []results.Result{
+			{Name: "Number of Deleted Users", Value: float64(deletedUsers)},
+			latency.Result("Deprovisioning Time Per User", results.Average, deletionPhase, deletions.Mean()),
+			{Name: "Deprovisioning Throughput", Unit: results.UsersPerMinute, Value: throughput, Phase: deletionPhase, Precision: 2},
+			runningTimeResult(totalRunningTime),
+		}
+	}, func() []results.Result {
+		return deletions.Summary("Deprovisioning Time Per User", deletionPhase)
+	})
+	term.Infof("👋 the cluster is clean!")
+}
+
+// deletionPhase is the name of the phase under which the deletion timings are recorded
+const deletionPhase = "user-deletions"
+
+// deleteUsers deletes the usersignups concurrently and waits until the resources provisioned for each user are gone, on the
+// member clusters of the given member operator namespaces
+func deleteUsers(term terminal.Terminal, cl client.Client, memberOperatorNamespaces []string, usernames []string, bar *userProgressBar, timings *latency.Recorder) {
+	concurrentDeletions := 10
+	toDelete := make(chan string)
+	var wg sync.WaitGroup
+	wg.Add(concurrentDeletions)
+	for i := 0; i < concurrentDeletions; i++ {
+		go func() {
+			defer wg.Done()
+			for username := range toDelete {
+				startTime := time.Now()
+				if err := deleteUser(cl, memberOperatorNamespaces, username); err != nil {
+					term.Fatalf(err, "failed to delete user '%s'", username)
+				}
+				timings.Record(deletionPhase, username, startTime, time.Since(startTime))
+				bar.Incr()
+			}
+		}()
+	}
+	for _, username := range usernames {
+		toDelete <- username
+	}
+	close(toDelete)
+	wg.Wait()
+}
+
+func deleteUser(cl client.Client, memberOperatorNamespaces []string, username string) error {
+	// look up the namespaces before they start terminating, they are not linked to the user anymore once the space is gone
+	namespaces, err := users.Namespaces(cl, username)
+	if err != nil {
+		return err
+	}
+	if err := users.Delete(context.TODO(), cl, username, cfg.HostOperatorNamespace); err != nil {
+		return err
+	}
+	if err := wait.ForSpaceDeleted(cl, username); err != nil {
+		return err
+	}
+	if err := wait.ForNSTemplateSetDeleted(cl, memberOperatorNamespaces, username); err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		if err := wait.ForNamespaceDeleted(cl, ns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
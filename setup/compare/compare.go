@@ -0,0 +1,172 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/budget"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+)
+
+// Run is the results of a run, loaded from a results file
+type Run struct {
+	Name    string
+	Results []results.Result
+}
+
+// Row is a metric lined up across the runs, a value is nil when the metric is missing from the results of a run
+type Row struct {
+	Title  string
+	Values []*results.Result
+}
+
+// Comparison is the results of the runs lined up by metric, each run is compared to the first one which is the baseline
+type Comparison struct {
+	Runs       []string
+	Rows       []Row
+	Violations []budget.Violation
+}
+
+// New lines up the results of the runs by their title and checks the runs and their changes against the thresholds of
+// the budget, which may be nil
+func New(runs []Run, b *budget.Budget) Comparison {
+	c := Comparison{Violations: []budget.Violation{}}
+	rows := map[string]int{}
+	for i, run := range runs {
+		c.Runs = append(c.Runs, run.Name)
+		for j := range run.Results {
+			r := run.Results[j]
+			// the verdicts of the budget of a run are not metrics
+			if r.Verdict != results.NoVerdict {
+				continue
+			}
+			title := r.Title()
+			index, found := rows[title]
+			if !found {
+				index = len(c.Rows)
+				rows[title] = index
+				c.Rows = append(c.Rows, Row{Title: title, Values: make([]*results.Result, len(runs))})
+			}
+			c.Rows[index].Values[i] = &r
+		}
+	}
+	for _, row := range c.Rows {
+		baseline := row.Values[0]
+		for _, current := range row.Values[1:] {
+			if current == nil {
+				continue
+			}
+			if baseline != nil {
+				c.Violations = append(c.Violations, b.Compare(*baseline, *current)...)
+			}
+			c.Violations = append(c.Violations, b.Check(*current)...)
+		}
+	}
+	return c
+}
+
+// header returns the column titles, the metric and the baseline value followed by the value and the deltas of each run
+func (c Comparison) header() []string {
+	header := []string{"Metric"}
+	for i, run := range c.Runs {
+		if i == 0 {
+			header = append(header, run)
+			continue
+		}
+		header = append(header, run, "Δ", "Δ%")
+	}
+	return header
+}
+
+func (r Row) cells() []string {
+	cells := []string{r.Title}
+	baseline := r.Values[0]
+	precision := 0
+	for _, v := range r.Values {
+		if v != nil && v.Precision > precision {
+			precision = v.Precision
+		}
+	}
+	for i, v := range r.Values {
+		value := "-"
+		if v != nil {
+			value = strconv.FormatFloat(v.Value, 'f', precision, 64)
+		}
+		if i == 0 {
+			cells = append(cells, value)
+			continue
+		}
+		delta, relative := "-", "-"
+		if v != nil && baseline != nil {
+			delta = strconv.FormatFloat(v.Value-baseline.Value, 'f', precision, 64)
+			if !strings.HasPrefix(delta, "-") {
+				delta = "+" + delta
+			}
+			relative = relativeDelta(baseline.Value, v.Value)
+		}
+		cells = append(cells, value, delta, relative)
+	}
+	return cells
+}
+
+// relativeDelta returns the change from the baseline in percent, it is not defined for a zero baseline
+func relativeDelta(baseline, value float64) string {
+	if baseline == 0 {
+		if value == 0 {
+			return "+0.00%"
+		}
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.2f%%", (value-baseline)/math.Abs(baseline)*100)
+}
+
+// Table returns the comparison as a text table followed by the budget violations
+func (c Comparison) Table() string {
+	b := &strings.Builder{}
+	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(c.header(), "\t"))
+	for _, row := range c.Rows {
+		fmt.Fprintln(w, strings.Join(row.cells(), "\t"))
+	}
+	w.Flush() // nolint:errcheck
+	if len(c.Violations) > 0 {
+		b.WriteString("\nBudget exceeded:\n")
+		for _, v := range c.Violations {
+			fmt.Fprintf(b, "- %s\n", v.Message)
+		}
+	}
+	return b.String()
+}
+
+// Markdown returns the comparison as a markdown table followed by the budget violations, eg. to paste in a pull request
+func (c Comparison) Markdown() string {
+	b := &strings.Builder{}
+	header := c.header()
+	fmt.Fprintf(b, "| %s |\n", strings.Join(escapeMarkdown(header), " | "))
+	separators := []string{":---"}
+	for range header[1:] {
+		separators = append(separators, "---:")
+	}
+	fmt.Fprintf(b, "| %s |\n", strings.Join(separators, " | "))
+	for _, row := range c.Rows {
+		fmt.Fprintf(b, "| %s |\n", strings.Join(escapeMarkdown(row.cells()), " | "))
+	}
+	if len(c.Violations) > 0 {
+		b.WriteString("\n**Budget exceeded:**\n\n")
+		for _, v := range c.Violations {
+			fmt.Fprintf(b, "- %s\n", escapeMarkdown([]string{v.Message})[0])
+		}
+	}
+	return b.String()
+}
+
+func escapeMarkdown(cells []string) []string {
+	escaped := make([]string, 0, len(cells))
+	for _, c := range cells {
+		escaped = append(escaped, strings.ReplaceAll(c, "|", `\|`))
+	}
+	return escaped
+}
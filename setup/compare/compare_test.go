@@ -0,0 +1,124 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/budget"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	baseline = Run{Name: "baseline.csv", Results: []results.Result{
+		{Name: "Number of Users", Value: 2000},
+		{Name: "host-operator Memory Usage", Unit: results.Megabytes, Aggregate: results.Max, Value: 100, Precision: 2},
+		{Name: "Provisioning Time Per User", Unit: results.Seconds, Aggregate: results.P99, Value: 0, Precision: 2},
+		{Name: "Idler Users", Value: 10},
+	}}
+	current = Run{Name: "current.json", Results: []results.Result{
+		{Name: "Number of Users", Value: 2000},
+		{Name: "host-operator Memory Usage", Unit: results.Megabytes, Aggregate: results.Max, Value: 120.5, Precision: 2},
+		{Name: "Provisioning Time Per User", Unit: results.Seconds, Aggregate: results.P99, Value: 1.5, Precision: 2},
+		{Name: "Failed Users", Value: 1},
+		{Name: "Budget: Failed Users <= 0", Value: 1, Verdict: results.Fail},
+	}}
+)
+
+func TestNew(t *testing.T) {
+	t.Run("lines up the metrics", func(t *testing.T) {
+		// when
+		c := New([]Run{baseline, current}, nil)
+
+		// then
+		assert.Equal(t, []string{"baseline.csv", "current.json"}, c.Runs)
+		titles := []string{}
+		for _, row := range c.Rows {
+			titles = append(titles, row.Title)
+		}
+		assert.Equal(t, []string{"Number of Users", "Max host-operator Memory Usage (MB)", "Provisioning Time Per User p99 (s)", "Idler Users", "Failed Users"}, titles)
+		assert.Nil(t, c.Rows[3].Values[1])
+		assert.Nil(t, c.Rows[4].Values[0])
+		assert.Empty(t, c.Violations)
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		// given
+		b, err := budget.Parse("budget.yaml", []byte("thresholds:\n- metric: host-operator Memory Usage\n  maxIncrease: 15%\n- metric: Failed Users\n  max: 0\n"))
+		require.NoError(t, err)
+
+		// when
+		c := New([]Run{baseline, current}, b)
+
+		// then
+		require.Len(t, c.Violations, 2)
+		assert.Equal(t, "Max host-operator Memory Usage (MB) increased from 100.00 to 120.50, the maximum increase is 15%", c.Violations[0].Message)
+		assert.Equal(t, "Failed Users is 1, it must be <= 0", c.Violations[1].Message)
+	})
+
+	t.Run("whole run and tier rollout window", func(t *testing.T) {
+		// given
+		b, err := budget.Parse("budget.yaml", []byte("thresholds:\n- metric: member-operator Memory Usage\n  aggregate: max\n  max: 200\n"))
+		require.NoError(t, err)
+		run := Run{Name: "current.json", Results: []results.Result{
+			{Name: "member-operator Memory Usage", Unit: results.Megabytes, Aggregate: results.Max, Value: 150, Precision: 2},
+			{Name: "member-operator Memory Usage During Tier Rollout", Unit: results.Megabytes, Aggregate: results.Max, Value: 250, Phase: "tier-rollout", Precision: 2},
+		}}
+
+		// when
+		c := New([]Run{run}, b)
+		verdicts := b.Verdicts(run.Results)
+
+		// then
+		require.Len(t, c.Rows, 2)
+		assert.Equal(t, "Max member-operator Memory Usage (MB)", c.Rows[0].Title)
+		assert.Equal(t, 150.0, c.Rows[0].Values[0].Value)
+		assert.Equal(t, "Max member-operator Memory Usage During Tier Rollout (MB)", c.Rows[1].Title)
+		assert.Equal(t, 250.0, c.Rows[1].Values[0].Value)
+		// the threshold of the whole run does not apply to the tier rollout window
+		assert.Equal(t, []results.Result{
+			{Name: "Budget: Max member-operator Memory Usage (MB) <= 200", Value: 150, Precision: 2, Verdict: results.Pass},
+		}, verdicts)
+	})
+}
+
+func TestTable(t *testing.T) {
+	// given
+	c := New([]Run{baseline, current}, nil)
+
+	// when
+	table := c.Table()
+
+	// then
+	assert.Equal(t, `Metric                               baseline.csv  current.json  Δ       Δ%
+Number of Users                      2000          2000          +0      +0.00%
+Max host-operator Memory Usage (MB)  100.00        120.50        +20.50  +20.50%
+Provisioning Time Per User p99 (s)   0.00          1.50          +1.50   n/a
+Idler Users                          10            -             -       -
+Failed Users                         -             1             -       -
+`, table)
+}
+
+func TestMarkdown(t *testing.T) {
+	// given
+	b, err := budget.Parse("budget.yaml", []byte("thresholds:\n- metric: host-operator Memory Usage\n  maxIncrease: 15%\n"))
+	require.NoError(t, err)
+	c := New([]Run{baseline, current}, b)
+
+	// when
+	markdown := c.Markdown()
+
+	// then
+	assert.Equal(t, `| Metric | baseline.csv | current.json | Δ | Δ% |
+| :--- | ---: | ---: | ---: | ---: |
+| Number of Users | 2000 | 2000 | +0 | +0.00% |
+| Max host-operator Memory Usage (MB) | 100.00 | 120.50 | +20.50 | +20.50% |
+| Provisioning Time Per User p99 (s) | 0.00 | 1.50 | +1.50 | n/a |
+| Idler Users | 10 | - | - | - |
+| Failed Users | - | 1 | - | - |
+
+**Budget exceeded:**
+
+- Max host-operator Memory Usage (MB) increased from 100.00 to 120.50, the maximum increase is 15%
+`, markdown)
+}
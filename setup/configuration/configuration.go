@@ -2,6 +2,7 @@ package configuration
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -11,12 +12,14 @@ import (
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
 
+	configv1 "github.com/openshift/api/config/v1"
 	quotav1 "github.com/openshift/api/quota/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	templatev1 "github.com/openshift/api/template/v1"
 	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
@@ -36,6 +39,8 @@ const (
 
 	CustomTemplateUsersParam  = "custom"
 	DefaultTemplateUsersParam = "default"
+
+	clusterConfigFilename = "original-cluster-config.json"
 )
 
 var (
@@ -50,8 +55,14 @@ var (
 
 	resultsDir       string
 	resultsFilepath  string
+	resultsPrefix    string
 	stdOutFilepath   string
 	stdErrFilepath   string
+	journalFilepath  string
+	failuresFilepath string
+	scenarioFilepath string
+	timingsFilepath  string
+	timeSeriesPrefix string
 	startedTimestamp = time.Now().Format("2006-01-02_15:04:05")
 )
 
@@ -67,9 +78,15 @@ func Init(term terminal.Terminal) {
 	if len(Testname) > 0 && Testname[0] != '-' {
 		Testname = "-" + Testname
 	}
-	resultsFilepath = fmt.Sprintf("%s%s%s.csv", resultsDir, startedTimestamp, Testname)
+	resultsPrefix = fmt.Sprintf("%s%s%s", resultsDir, startedTimestamp, Testname)
+	resultsFilepath = resultsPrefix + ".csv"
 	stdOutFilepath = fmt.Sprintf("%s%s%s-stdout.log", resultsDir, startedTimestamp, Testname)
 	stdErrFilepath = fmt.Sprintf("%s%s%s-stderr.log", resultsDir, startedTimestamp, Testname)
+	journalFilepath = fmt.Sprintf("%s%s%s-journal.jsonl", resultsDir, startedTimestamp, Testname)
+	failuresFilepath = fmt.Sprintf("%s%s%s-failures.csv", resultsDir, startedTimestamp, Testname)
+	scenarioFilepath = fmt.Sprintf("%s%s%s-scenario.yaml", resultsDir, startedTimestamp, Testname)
+	timingsFilepath = fmt.Sprintf("%s%s%s-timings.csv", resultsDir, startedTimestamp, Testname)
+	timeSeriesPrefix = fmt.Sprintf("%s%s%s-timeseries", resultsDir, startedTimestamp, Testname)
 }
 
 // NewClient returns a new client to the cluster defined by the current context in
@@ -110,12 +127,14 @@ func NewScheme() (*runtime.Scheme, error) {
 	builder := append(
 		runtime.SchemeBuilder{},
 		toolchainv1alpha1.AddToScheme,
+		configv1.Install,
 		quotav1.Install,
 		operatorsv1alpha1.AddToScheme,
 		operatorsv1.AddToScheme,
 		templatev1.Install,
 		routev1.Install,
 		appsv1.AddToScheme,
+		corev1.AddToScheme,
 	)
 	err := builder.AddToScheme(s)
 	return s, err
@@ -151,6 +170,77 @@ func DisableCopiedCSVs(cl client.Client) error {
 	return cl.Update(context.TODO(), olmConfig)
 }
 
+// ClusterConfig holds the cluster settings that are modified by the setup, as they were before the first setup run
+type ClusterConfig struct {
+	DefaultSpaceTier  *string `json:"defaultSpaceTier,omitempty"`
+	DisableCopiedCSVs *bool   `json:"disableCopiedCSVs,omitempty"`
+}
+
+// RecordClusterConfig saves the cluster settings that are modified by ConfigureDefaultSpaceTier and DisableCopiedCSVs to the given file,
+// so that they can be restored by RestoreClusterConfig. An existing file is kept as is, since it holds the settings from before an earlier run.
+func RecordClusterConfig(cl client.Client, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	toolchainCfg := &toolchainv1alpha1.ToolchainConfig{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "config", Namespace: HostOperatorNamespace}, toolchainCfg); err != nil {
+		return err
+	}
+	olmConfig := &operatorsv1.OLMConfig{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, olmConfig); err != nil {
+		return err
+	}
+
+	original := ClusterConfig{
+		DefaultSpaceTier: toolchainCfg.Spec.Host.Tiers.DefaultSpaceTier,
+	}
+	if olmConfig.Spec.Features != nil {
+		original.DisableCopiedCSVs = olmConfig.Spec.Features.DisableCopiedCSVs
+	}
+	content, err := json.MarshalIndent(original, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// RestoreClusterConfig reverts the cluster settings to the values saved by RecordClusterConfig and removes the file
+func RestoreClusterConfig(cl client.Client, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	original := ClusterConfig{}
+	if err := json.Unmarshal(content, &original); err != nil {
+		return err
+	}
+
+	toolchainCfg := &toolchainv1alpha1.ToolchainConfig{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "config", Namespace: HostOperatorNamespace}, toolchainCfg); err != nil {
+		return err
+	}
+	toolchainCfg.Spec.Host.Tiers.DefaultSpaceTier = original.DefaultSpaceTier
+	if err := cl.Update(context.TODO(), toolchainCfg); err != nil {
+		return err
+	}
+
+	olmConfig := &operatorsv1.OLMConfig{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, olmConfig); err != nil {
+		return err
+	}
+	if olmConfig.Spec.Features == nil {
+		olmConfig.Spec.Features = &operatorsv1.Features{}
+	}
+	olmConfig.Spec.Features.DisableCopiedCSVs = original.DisableCopiedCSVs
+	if err := cl.Update(context.TODO(), olmConfig); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
 // GetKubeconfigFile returns a file reader on (by order of match):
 // - the --kubeconfig CLI argument if it was provided
 // - the $KUBECONFIG file it the env var was set
@@ -190,6 +280,31 @@ func ResultsFilepath() string {
 	return resultsFilepath
 }
 
+// ResultsJSONFilepath returns the location of the results in JSON format
+func ResultsJSONFilepath() string {
+	return resultsPrefix + ".json"
+}
+
+// ResultsJUnitFilepath returns the location of the results in JUnit XML format
+func ResultsJUnitFilepath() string {
+	return resultsPrefix + "-junit.xml"
+}
+
+// RunManifestFilepath returns the location of the record of the cluster, the operators and the flags of the run
+func RunManifestFilepath() string {
+	return resultsPrefix + "-run-manifest.json"
+}
+
+// ReportFilepath returns the location of the HTML report of the run
+func ReportFilepath() string {
+	return resultsPrefix + "-report.html"
+}
+
+// ResultsOpenMetricsFilepath returns the location of the results in OpenMetrics text format
+func ResultsOpenMetricsFilepath() string {
+	return resultsPrefix + "-openmetrics.txt"
+}
+
 func StdOutFilepath() string {
 	return stdOutFilepath
 }
@@ -198,6 +313,37 @@ func StdErrFilepath() string {
 	return stdErrFilepath
 }
 
+func FailuresFilepath() string {
+	return failuresFilepath
+}
+
+func ScenarioFilepath() string {
+	return scenarioFilepath
+}
+
+func TimingsFilepath() string {
+	return timingsFilepath
+}
+
+// TimeSeriesCSVFilepath returns the location of the datapoints of the metrics queries in CSV format
+func TimeSeriesCSVFilepath() string {
+	return timeSeriesPrefix + ".csv"
+}
+
+// TimeSeriesJSONFilepath returns the location of the datapoints of the metrics queries in JSON format
+func TimeSeriesJSONFilepath() string {
+	return timeSeriesPrefix + ".json"
+}
+
+func JournalFilepath() string {
+	return journalFilepath
+}
+
+// ClusterConfigFilepath returns the location of the cluster settings recorded before the first setup run
+func ClusterConfigFilepath() string {
+	return resultsDir + clusterConfigFilename
+}
+
 func StartedTimestamp() string {
 	return startedTimestamp
 }
@@ -0,0 +1,74 @@
+package configuration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake" //nolint: staticcheck // not deprecated anymore: see https://github.com/kubernetes-sigs/controller-runtime/pull/1101
+)
+
+func TestRecordAndRestoreClusterConfig(t *testing.T) {
+	// given
+	HostOperatorNamespace = DefaultHostNS
+	s, err := NewScheme()
+	require.NoError(t, err)
+	originalTier := "base"
+	toolchainCfg := &toolchainv1alpha1.ToolchainConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "config",
+			Namespace: HostOperatorNamespace,
+		},
+	}
+	toolchainCfg.Spec.Host.Tiers.DefaultSpaceTier = &originalTier
+	olmConfig := &operatorsv1.OLMConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cluster",
+		},
+	}
+	spaceTier := &toolchainv1alpha1.NSTemplateTier{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      UserSpaceTier,
+			Namespace: HostOperatorNamespace,
+		},
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(toolchainCfg, olmConfig, spaceTier).Build()
+	path := filepath.Join(t.TempDir(), clusterConfigFilename)
+
+	t.Run("record and restore", func(t *testing.T) {
+		// when
+		require.NoError(t, RecordClusterConfig(cl, path))
+		require.NoError(t, ConfigureDefaultSpaceTier(cl))
+		require.NoError(t, DisableCopiedCSVs(cl))
+		// recording again must not overwrite the settings from before the first run
+		require.NoError(t, RecordClusterConfig(cl, path))
+		require.NoError(t, RestoreClusterConfig(cl, path))
+
+		// then
+		actualCfg := &toolchainv1alpha1.ToolchainConfig{}
+		require.NoError(t, cl.Get(context.TODO(), client.ObjectKeyFromObject(toolchainCfg), actualCfg))
+		require.NotNil(t, actualCfg.Spec.Host.Tiers.DefaultSpaceTier)
+		assert.Equal(t, "base", *actualCfg.Spec.Host.Tiers.DefaultSpaceTier)
+		actualOLMConfig := &operatorsv1.OLMConfig{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, actualOLMConfig))
+		require.NotNil(t, actualOLMConfig.Spec.Features)
+		assert.Nil(t, actualOLMConfig.Spec.Features.DisableCopiedCSVs)
+		assert.NoFileExists(t, path)
+	})
+
+	t.Run("restore without record", func(t *testing.T) {
+		// when
+		err := RestoreClusterConfig(cl, filepath.Join(t.TempDir(), clusterConfigFilename))
+
+		// then
+		require.Error(t, err)
+	})
+}
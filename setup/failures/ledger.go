@@ -0,0 +1,195 @@
+package failures
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+)
+
+// Policy defines how many users are allowed to fail before the whole run is aborted and how failed actions are retried.
+// A zero MaxFailures and FailureRatio means that the run is aborted on the first failure.
+type Policy struct {
+	MaxFailures  int
+	FailureRatio float64
+	Retries      int
+	Backoff      time.Duration
+}
+
+// Enabled returns true if failures should be collected rather than aborting the run
+func (p Policy) Enabled() bool {
+	return p.MaxFailures > 0 || p.FailureRatio > 0
+}
+
+// Validate checks that the policy values are within bounds
+func (p Policy) Validate() error {
+	if p.MaxFailures < 0 {
+		return fmt.Errorf("the maximum number of failures must not be negative")
+	}
+	if p.FailureRatio < 0 || p.FailureRatio > 1 {
+		return fmt.Errorf("the failure ratio must be between 0 and 1")
+	}
+	if p.Retries < 0 {
+		return fmt.Errorf("the number of retries must not be negative")
+	}
+	return nil
+}
+
+// Failure records an action that failed for a user after all retries
+type Failure struct {
+	Phase    string
+	Username string
+	Object   string
+	Attempts int
+	Time     time.Time
+	Err      error
+}
+
+// Ledger collects the failures of a run
+type Ledger struct {
+	mu          sync.Mutex
+	policy      Policy
+	totalUsers  int
+	failures    []Failure
+	failedUsers map[string]bool
+}
+
+// NewLedger returns a ledger applying the given policy to a run of totalUsers users
+func NewLedger(policy Policy, totalUsers int) *Ledger {
+	return &Ledger{
+		policy:      policy,
+		totalUsers:  totalUsers,
+		failedUsers: map[string]bool{},
+	}
+}
+
+// Retry calls the action until it succeeds, the policy's retries are exhausted or the context is done, doubling the wait
+// between attempts. It returns the number of attempts and the last error.
+func (l *Ledger) Retry(ctx context.Context, action func() error) (int, error) {
+	attempts := 1
+	err := action()
+	backoff := l.policy.Backoff
+	for err != nil && attempts <= l.policy.Retries {
+		select {
+		case <-ctx.Done():
+			return attempts, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		attempts++
+		err = action()
+	}
+	return attempts, err
+}
+
+// Add records a failure, the object defaults to the given value unless the error identifies the object that failed
+func (l *Ledger) Add(phase, username, defaultObject string, attempts int, err error) {
+	object := defaultObject
+	var objErr objectError
+	if errors.As(err, &objErr) {
+		object = objErr.ObjectRef()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures = append(l.failures, Failure{
+		Phase:    phase,
+		Username: username,
+		Object:   object,
+		Attempts: attempts,
+		Time:     time.Now(),
+		Err:      err,
+	})
+	l.failedUsers[username] = true
+}
+
+// HasFailed returns true if any action already failed for the given user
+func (l *Ledger) HasFailed(username string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.failedUsers[username]
+}
+
+// FailedUsers returns the number of distinct users with at least one failure
+func (l *Ledger) FailedUsers() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.failedUsers)
+}
+
+// Exceeded returns an error if the failed users exceed the limits of the policy
+func (l *Ledger) Exceeded() error {
+	failed := l.FailedUsers()
+	if l.policy.MaxFailures > 0 && failed > l.policy.MaxFailures {
+		return fmt.Errorf("%d users failed, the maximum is %d", failed, l.policy.MaxFailures)
+	}
+	if l.policy.FailureRatio > 0 && l.totalUsers > 0 && float64(failed)/float64(l.totalUsers) > l.policy.FailureRatio {
+		return fmt.Errorf("%d of %d users failed, the maximum ratio is %.2f", failed, l.totalUsers, l.policy.FailureRatio)
+	}
+	return nil
+}
+
+// Failures returns a copy of the recorded failures
+func (l *Ledger) Failures() []Failure {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Failure{}, l.failures...)
+}
+
+// Summary returns the number of failed users and the number of failures per phase as results
+func (l *Ledger) Summary() []results.Result {
+	failures := l.Failures()
+	perPhase := map[string]int{}
+	for _, f := range failures {
+		perPhase[f.Phase]++
+	}
+	phases := make([]string, 0, len(perPhase))
+	for phase := range perPhase {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+
+	summary := []results.Result{
+		{Name: "Number of Failed Users", Value: float64(l.FailedUsers())},
+	}
+	for _, phase := range phases {
+		summary = append(summary, results.Result{Name: fmt.Sprintf("Failures - %s", phase), Value: float64(perPhase[phase]), Phase: phase})
+	}
+	return summary
+}
+
+// WriteCSV writes all the failures to a csv file at the given path
+func (l *Ledger) WriteCSV(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rows := [][]string{
+		{"Phase", "Username", "Object", "Attempts", "Time", "Error"},
+	}
+	for _, failure := range l.Failures() {
+		rows = append(rows, []string{
+			failure.Phase,
+			failure.Username,
+			failure.Object,
+			strconv.Itoa(failure.Attempts),
+			failure.Time.Format(time.RFC3339),
+			failure.Err.Error(),
+		})
+	}
+	return csv.NewWriter(f).WriteAll(rows)
+}
+
+// objectError is implemented by errors that identify the object that failed, eg. templates.ApplyError
+type objectError interface {
+	error
+	ObjectRef() string
+}
@@ -0,0 +1,185 @@
+package failures
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		assert.False(t, Policy{}.Enabled())
+		assert.True(t, Policy{MaxFailures: 1}.Enabled())
+		assert.True(t, Policy{FailureRatio: 0.1}.Enabled())
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		require.NoError(t, Policy{MaxFailures: 10, FailureRatio: 0.5, Retries: 3}.Validate())
+		require.EqualError(t, Policy{MaxFailures: -1}.Validate(), "the maximum number of failures must not be negative")
+		require.EqualError(t, Policy{FailureRatio: 1.5}.Validate(), "the failure ratio must be between 0 and 1")
+		require.EqualError(t, Policy{Retries: -1}.Validate(), "the number of retries must not be negative")
+	})
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("succeeds after retries", func(t *testing.T) {
+		// given
+		l := NewLedger(Policy{MaxFailures: 1, Retries: 3}, 10)
+		calls := 0
+
+		// when
+		attempts, err := l.Retry(context.TODO(), func() error {
+			calls++
+			if calls < 3 {
+				return fmt.Errorf("conflict")
+			}
+			return nil
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		// given
+		l := NewLedger(Policy{MaxFailures: 1, Retries: 2}, 10)
+		calls := 0
+
+		// when
+		attempts, err := l.Retry(context.TODO(), func() error {
+			calls++
+			return fmt.Errorf("conflict")
+		})
+
+		// then
+		require.EqualError(t, err, "conflict")
+		// the first attempt and the 2 retries
+		assert.Equal(t, 3, attempts)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("context done", func(t *testing.T) {
+		// given
+		l := NewLedger(Policy{MaxFailures: 1, Retries: 3, Backoff: time.Hour}, 10)
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		calls := 0
+
+		// when
+		attempts, err := l.Retry(ctx, func() error {
+			calls++
+			return fmt.Errorf("conflict")
+		})
+
+		// then
+		require.EqualError(t, err, "conflict")
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestLedger(t *testing.T) {
+	t.Run("max failures", func(t *testing.T) {
+		// given
+		l := NewLedger(Policy{MaxFailures: 1}, 10)
+
+		// when
+		l.Add("signups", "zippy-0001", "UserSignup zippy-0001", 1, fmt.Errorf("timeout"))
+		l.Add("idler-setup", "zippy-0001", "Idler zippy-0001-dev", 1, fmt.Errorf("timeout"))
+
+		// then
+		require.NoError(t, l.Exceeded()) // the same user failed twice
+		assert.True(t, l.HasFailed("zippy-0001"))
+		assert.False(t, l.HasFailed("zippy-0002"))
+
+		// when
+		l.Add("signups", "zippy-0002", "UserSignup zippy-0002", 1, fmt.Errorf("timeout"))
+
+		// then
+		require.EqualError(t, l.Exceeded(), "2 users failed, the maximum is 1")
+	})
+
+	t.Run("failure ratio", func(t *testing.T) {
+		// given
+		l := NewLedger(Policy{FailureRatio: 0.1}, 10)
+
+		// when
+		l.Add("signups", "zippy-0001", "UserSignup zippy-0001", 1, fmt.Errorf("timeout"))
+
+		// then
+		require.NoError(t, l.Exceeded())
+
+		// when
+		l.Add("signups", "zippy-0002", "UserSignup zippy-0002", 1, fmt.Errorf("timeout"))
+
+		// then
+		require.EqualError(t, l.Exceeded(), "2 of 10 users failed, the maximum ratio is 0.10")
+	})
+
+	t.Run("object from error", func(t *testing.T) {
+		// given
+		l := NewLedger(Policy{MaxFailures: 5}, 10)
+		err := multierror.Append(nil, &testObjectError{ref: "Deployment zippy-0001-dev/nginx"})
+
+		// when
+		l.Add("default-template-users", "zippy-0001", "namespace zippy-0001-dev", 2, err)
+
+		// then
+		require.Len(t, l.Failures(), 1)
+		assert.Equal(t, "Deployment zippy-0001-dev/nginx", l.Failures()[0].Object)
+		assert.Equal(t, 2, l.Failures()[0].Attempts)
+	})
+
+	t.Run("summary and csv", func(t *testing.T) {
+		// given
+		l := NewLedger(Policy{MaxFailures: 5}, 10)
+		l.Add("signups", "zippy-0001", "UserSignup zippy-0001", 3, fmt.Errorf("timeout"))
+		l.Add("signups", "zippy-0002", "UserSignup zippy-0002", 3, fmt.Errorf("timeout"))
+		l.Add("idler-setup", "zippy-0003", "Idler zippy-0003-dev", 3, fmt.Errorf("conflict"))
+		path := filepath.Join(t.TempDir(), "failures.csv")
+
+		// when
+		summary := l.Summary()
+		err := l.WriteCSV(path)
+
+		// then
+		assert.Equal(t, []results.Result{
+			{Name: "Number of Failed Users", Value: 3},
+			{Name: "Failures - idler-setup", Value: 1, Phase: "idler-setup"},
+			{Name: "Failures - signups", Value: 2, Phase: "signups"},
+		}, summary)
+		require.NoError(t, err)
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		rows, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+		assert.Equal(t, []string{"Phase", "Username", "Object", "Attempts", "Time", "Error"}, rows[0])
+		assert.Equal(t, "Idler zippy-0003-dev", rows[3][2])
+		assert.Equal(t, "conflict", rows[3][5])
+	})
+}
+
+type testObjectError struct {
+	ref string
+}
+
+func (e *testObjectError) Error() string {
+	return "could not apply resource"
+}
+
+func (e *testObjectError) ObjectRef() string {
+	return e.ref
+}
@@ -16,25 +16,45 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func UpdateTimeout(cl client.Client, username string, timeout time.Duration) error {
+// UpdateTimeout sets the timeout of the idlers of the given user, once they are running
+func UpdateTimeout(ctx context.Context, cl client.Client, username string, timeout time.Duration) error {
 	for _, suffix := range []string{"dev"} { // TODO: hard coded suffixes, we could probably get them from the tier instead
 		idlerName := fmt.Sprintf("%s-%s", username, suffix)
-		idler, err := getIdler(cl, idlerName)
+		idler, err := getIdler(ctx, cl, idlerName)
 		if err != nil {
 			return err
 		}
 		idler.Spec.TimeoutSeconds = int32(timeout.Seconds())
-		if err = cl.Update(context.TODO(), idler); err != nil {
+		if err = cl.Update(ctx, idler); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func getIdler(cl client.Client, name string) (*toolchainv1alpha1.Idler, error) {
+// HasTimeout returns true if the idlers of the given user exist and are configured with the given timeout
+func HasTimeout(cl client.Client, username string, timeout time.Duration) (bool, error) {
+	for _, suffix := range []string{"dev"} {
+		idler := &toolchainv1alpha1.Idler{}
+		if err := cl.Get(context.TODO(), types.NamespacedName{
+			Name: fmt.Sprintf("%s-%s", username, suffix),
+		}, idler); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if idler.Spec.TimeoutSeconds != int32(timeout.Seconds()) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func getIdler(ctx context.Context, cl client.Client, name string) (*toolchainv1alpha1.Idler, error) {
 	idler := &toolchainv1alpha1.Idler{}
-	err := k8swait.Poll(cfg.DefaultRetryInterval, cfg.DefaultTimeout, func() (bool, error) {
-		err := cl.Get(context.TODO(), types.NamespacedName{
+	err := k8swait.PollWithContext(ctx, cfg.DefaultRetryInterval, cfg.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		err := cl.Get(ctx, types.NamespacedName{
 			Name: name,
 		}, idler)
 		if errors.IsNotFound(err) {
@@ -0,0 +1,198 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Phase identifies a step of the setup run that is tracked per user
+type Phase string
+
+// Header is the first line of a journal and records the parameters of the run that created it
+type Header struct {
+	UsernamePrefix string `json:"usernamePrefix"`
+	Users          int    `json:"users"`
+	// ScenarioFile is the copy of the scenario recorded in the results directory, it is used to resume the run
+	ScenarioFile string    `json:"scenarioFile"`
+	StartedAt    time.Time `json:"startedAt"`
+	// MemberDistribution is the --member-distribution flag of the run, a resumed run assigns the users to the same member clusters
+	MemberDistribution string `json:"memberDistribution,omitempty"`
+	// SignupMode is the --signup-mode flag of the run, a resumed run signs up the remaining users the same way
+	SignupMode string `json:"signupMode,omitempty"`
+	// ApplyAs is the --apply-as flag of the run, a resumed run applies the templates of the remaining users the same way
+	ApplyAs string `json:"applyAs,omitempty"`
+	// TierDistribution is the --tier-distribution flag of the run, a resumed run assigns the users to the same tiers
+	TierDistribution string `json:"tierDistribution,omitempty"`
+}
+
+// Entry records that a user has completed a phase
+type Entry struct {
+	Phase       Phase     `json:"phase"`
+	UserNumber  int       `json:"userNumber"`
+	Username    string    `json:"username"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+// Journal is an append-only checkpoint file (JSON Lines) that records the progress of a setup run so that
+// an interrupted run can be resumed
+type Journal struct {
+	mu        sync.Mutex
+	path      string
+	f         *os.File
+	header    Header
+	completed map[Phase]map[int]Entry
+}
+
+// Create creates a new journal at the given path and writes the header as its first line
+func Create(path string, header Header) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to create journal '%s'", path)
+	}
+	j := &Journal{
+		path:      path,
+		f:         f,
+		header:    header,
+		completed: map[Phase]map[int]Entry{},
+	}
+	if err := j.writeLine(header); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Open loads an existing journal and reopens it so that new entries are appended to it
+func Open(path string) (*Journal, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open journal '%s'", path)
+	}
+	defer f.Close()
+
+	j := &Journal{
+		path:      path,
+		completed: map[Phase]map[int]Entry{},
+	}
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if lineNum == 1 {
+			if err := json.Unmarshal(scanner.Bytes(), &j.header); err != nil {
+				return nil, errors.Wrapf(err, "invalid header in journal '%s'", path)
+			}
+			continue
+		}
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// the last line may be truncated if the previous run was killed while writing it
+			continue
+		}
+		j.add(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "unable to read journal '%s'", path)
+	}
+	if lineNum == 0 {
+		return nil, fmt.Errorf("journal '%s' is empty", path)
+	}
+
+	if j.f, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600); err != nil {
+		return nil, errors.Wrapf(err, "unable to open journal '%s' for writing", path)
+	}
+	return j, nil
+}
+
+// Path returns the location of the journal file
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Header returns the parameters of the run that created the journal
+func (j *Journal) Header() Header {
+	return j.header
+}
+
+// Record appends an entry stating that the given user completed the given phase
+func (j *Journal) Record(phase Phase, userNumber int, username string) error {
+	entry := Entry{
+		Phase:       phase,
+		UserNumber:  userNumber,
+		Username:    username,
+		CompletedAt: time.Now(),
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.writeLine(entry); err != nil {
+		return err
+	}
+	j.add(entry)
+	return nil
+}
+
+// IsCompleted returns true if the journal has an entry for the given user and phase
+func (j *Journal) IsCompleted(phase Phase, userNumber int) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, found := j.completed[phase][userNumber]
+	return found
+}
+
+// Completed returns the number of users that completed the given phase
+func (j *Journal) Completed(phase Phase) int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.completed[phase])
+}
+
+// Usernames returns the names of the users that completed the given phase, ordered by user number
+func (j *Journal) Usernames(phase Phase) []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries := make([]Entry, 0, len(j.completed[phase]))
+	for _, e := range j.completed[phase] {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, k int) bool {
+		return entries[i].UserNumber < entries[k].UserNumber
+	})
+	usernames := make([]string, len(entries))
+	for i, e := range entries {
+		usernames[i] = e.Username
+	}
+	return usernames
+}
+
+// Close closes the underlying file
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
+
+func (j *Journal) add(entry Entry) {
+	if _, ok := j.completed[entry.Phase]; !ok {
+		j.completed[entry.Phase] = map[int]Entry{}
+	}
+	j.completed[entry.Phase][entry.UserNumber] = entry
+}
+
+func (j *Journal) writeLine(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	// each line is written with a single call and synced so that a crash loses at most the entry being written
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return errors.Wrapf(err, "unable to write to journal '%s'", j.path)
+	}
+	return j.f.Sync()
+}
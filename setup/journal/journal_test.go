@@ -0,0 +1,116 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	signups    Phase = "signups"
+	idlerSetup Phase = "idler-setup"
+)
+
+func TestJournal(t *testing.T) {
+
+	t.Run("success", func(t *testing.T) {
+
+		t.Run("create, record and reopen", func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			j, err := Create(path, Header{UsernamePrefix: "zippy", Users: 3, ScenarioFile: "scenario.yaml", MemberDistribution: "member-a=70,member-b=30", SignupMode: "regsvc", ApplyAs: "user", TierDistribution: "base1ns=70%,appstudio=30%"})
+			require.NoError(t, err)
+
+			// when
+			require.NoError(t, j.Record(signups, 1, "zippy-0001"))
+			require.NoError(t, j.Record(signups, 2, "zippy-0002"))
+			require.NoError(t, j.Record(idlerSetup, 1, "zippy-0001"))
+			require.NoError(t, j.Close())
+			reopened, err := Open(path)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, "zippy", reopened.Header().UsernamePrefix)
+			assert.Equal(t, 3, reopened.Header().Users)
+			assert.Equal(t, "scenario.yaml", reopened.Header().ScenarioFile)
+			assert.Equal(t, "member-a=70,member-b=30", reopened.Header().MemberDistribution)
+			assert.Equal(t, "regsvc", reopened.Header().SignupMode)
+			assert.Equal(t, "user", reopened.Header().ApplyAs)
+			assert.Equal(t, "base1ns=70%,appstudio=30%", reopened.Header().TierDistribution)
+			assert.Equal(t, 2, reopened.Completed(signups))
+			assert.Equal(t, 1, reopened.Completed(idlerSetup))
+			assert.Equal(t, 0, reopened.Completed("default"))
+			assert.True(t, reopened.IsCompleted(signups, 2))
+			assert.False(t, reopened.IsCompleted(signups, 3))
+			assert.False(t, reopened.IsCompleted(idlerSetup, 2))
+			assert.Equal(t, []string{"zippy-0001", "zippy-0002"}, reopened.Usernames(signups))
+
+			// new entries are appended to the existing journal
+			require.NoError(t, reopened.Record(signups, 3, "zippy-0003"))
+			require.NoError(t, reopened.Close())
+			reopened, err = Open(path)
+			require.NoError(t, err)
+			assert.Equal(t, 3, reopened.Completed(signups))
+		})
+
+		t.Run("truncated last line is ignored", func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			j, err := Create(path, Header{UsernamePrefix: "zippy", Users: 2})
+			require.NoError(t, err)
+			require.NoError(t, j.Record(signups, 1, "zippy-0001"))
+			require.NoError(t, j.Close())
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+			require.NoError(t, err)
+			_, err = f.WriteString(`{"phase":"signups","userNum`)
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			// when
+			reopened, err := Open(path)
+
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, 1, reopened.Completed(signups))
+		})
+	})
+
+	t.Run("failures", func(t *testing.T) {
+
+		t.Run("create existing journal", func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			_, err := Create(path, Header{})
+			require.NoError(t, err)
+
+			// when
+			_, err = Create(path, Header{})
+
+			// then
+			require.Error(t, err)
+		})
+
+		t.Run("open missing journal", func(t *testing.T) {
+			// when
+			_, err := Open(filepath.Join(t.TempDir(), "missing.jsonl"))
+
+			// then
+			require.Error(t, err)
+		})
+
+		t.Run("open empty journal", func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "journal.jsonl")
+			require.NoError(t, os.WriteFile(path, []byte{}, 0600))
+
+			// when
+			_, err := Open(path)
+
+			// then
+			require.EqualError(t, err, "journal '"+path+"' is empty")
+		})
+	})
+}
@@ -0,0 +1,112 @@
+package latency
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+)
+
+// Distribution collects latencies and computes their percentiles. All the values are kept, rather than bucketed in a histogram,
+// since there is at most one value per user and per phase. It is safe for concurrent use.
+type Distribution struct {
+	mu     sync.Mutex
+	values []time.Duration
+	sorted bool
+}
+
+// Add records a latency
+func (d *Distribution) Add(value time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.values = append(d.values, value)
+	d.sorted = false
+}
+
+// Count returns the number of recorded latencies
+func (d *Distribution) Count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.values)
+}
+
+// Mean returns the average latency, it returns 0 if no latency was recorded
+func (d *Distribution) Mean() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.values) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, v := range d.values {
+		total += v
+	}
+	return total / time.Duration(len(d.values))
+}
+
+// Percentile returns the latency below which the given percentage (between 0 and 100) of the latencies fall,
+// using the nearest-rank method. It returns 0 if no latency was recorded.
+func (d *Distribution) Percentile(p float64) time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.values) == 0 {
+		return 0
+	}
+	if !d.sorted {
+		sort.Slice(d.values, func(i, j int) bool {
+			return d.values[i] < d.values[j]
+		})
+		d.sorted = true
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(d.values))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(d.values) {
+		rank = len(d.values)
+	}
+	return d.values[rank-1]
+}
+
+// Min returns the lowest recorded latency
+func (d *Distribution) Min() time.Duration {
+	return d.Percentile(0)
+}
+
+// Max returns the highest recorded latency
+func (d *Distribution) Max() time.Duration {
+	return d.Percentile(100)
+}
+
+// Summary returns the min, p50, p90, p95, p99 and max latencies in seconds of the given phase, under the given name
+func (d *Distribution) Summary(name, phase string) []results.Result {
+	summary := []results.Result{}
+	for _, p := range []struct {
+		aggregate  results.Aggregate
+		percentile float64
+	}{
+		{results.Min, 0},
+		{results.P50, 50},
+		{results.P90, 90},
+		{results.P95, 95},
+		{results.P99, 99},
+		{results.Max, 100},
+	} {
+		summary = append(summary, Result(name, p.aggregate, phase, d.Percentile(p.percentile)))
+	}
+	return summary
+}
+
+// Result returns a result for the given latency, in seconds
+func Result(name string, aggregate results.Aggregate, phase string, value time.Duration) results.Result {
+	return results.Result{
+		Name:      name,
+		Unit:      results.Seconds,
+		Aggregate: aggregate,
+		Value:     value.Seconds(),
+		Phase:     phase,
+		Precision: 2,
+	}
+}
@@ -0,0 +1,79 @@
+package latency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistribution(t *testing.T) {
+	t.Run("percentiles", func(t *testing.T) {
+		// given
+		d := &Distribution{}
+		for i := 100; i > 0; i-- {
+			d.Add(time.Duration(i) * time.Second)
+		}
+
+		// then
+		assert.Equal(t, 100, d.Count())
+		assert.Equal(t, time.Second, d.Min())
+		assert.Equal(t, 50*time.Second, d.Percentile(50))
+		assert.Equal(t, 90*time.Second, d.Percentile(90))
+		assert.Equal(t, 99*time.Second, d.Percentile(99))
+		assert.Equal(t, 100*time.Second, d.Max())
+		assert.Equal(t, 50500*time.Millisecond, d.Mean())
+	})
+
+	t.Run("few values", func(t *testing.T) {
+		// given
+		d := &Distribution{}
+		d.Add(3 * time.Second)
+		d.Add(time.Second)
+
+		// then
+		assert.Equal(t, time.Second, d.Percentile(50))
+		assert.Equal(t, 3*time.Second, d.Percentile(90))
+
+		// values added after a percentile was computed are taken into account
+		d.Add(2 * time.Second)
+		assert.Equal(t, 2*time.Second, d.Percentile(50))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		// given
+		d := &Distribution{}
+
+		// then
+		assert.Equal(t, 0, d.Count())
+		assert.Equal(t, time.Duration(0), d.Percentile(50))
+		assert.Equal(t, time.Duration(0), d.Max())
+		assert.Equal(t, time.Duration(0), d.Mean())
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		// given
+		d := &Distribution{}
+		for i := 1; i <= 100; i++ {
+			d.Add(time.Duration(i) * 100 * time.Millisecond)
+		}
+
+		// when
+		summary := d.Summary("Time Per User - default", "default")
+
+		// then
+		titles := [][]string{}
+		for _, r := range summary {
+			assert.Equal(t, "default", r.Phase)
+			titles = append(titles, []string{r.Title(), r.FormattedValue()})
+		}
+		assert.Equal(t, [][]string{
+			{"Time Per User - default min (s)", "0.10"},
+			{"Time Per User - default p50 (s)", "5.00"},
+			{"Time Per User - default p90 (s)", "9.00"},
+			{"Time Per User - default p95 (s)", "9.50"},
+			{"Time Per User - default p99 (s)", "9.90"},
+			{"Max Time Per User - default (s)", "10.00"},
+		}, titles)
+	})
+}
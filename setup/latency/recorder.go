@@ -0,0 +1,122 @@
+package latency
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Timing is the time it took to process a user in a phase
+type Timing struct {
+	Phase    string
+	Username string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Recorder collects the timing of each user and the latency distribution of each phase. It is safe for concurrent use.
+type Recorder struct {
+	mu            sync.Mutex
+	timings       []Timing
+	distributions map[string]*Distribution
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		distributions: map[string]*Distribution{},
+	}
+}
+
+// Record adds the timing of a user in the given phase
+func (r *Recorder) Record(phase, username string, start time.Time, duration time.Duration) {
+	r.mu.Lock()
+	r.timings = append(r.timings, Timing{
+		Phase:    phase,
+		Username: username,
+		Start:    start,
+		Duration: duration,
+	})
+	d := r.distribution(phase)
+	r.mu.Unlock()
+	d.Add(duration)
+}
+
+// Distribution returns the latencies recorded for the given phase
+func (r *Recorder) Distribution(phase string) *Distribution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.distribution(phase)
+}
+
+func (r *Recorder) distribution(phase string) *Distribution {
+	if _, ok := r.distributions[phase]; !ok {
+		r.distributions[phase] = &Distribution{}
+	}
+	return r.distributions[phase]
+}
+
+// WriteCSV writes the timing of each user to the given file, ordered by start time
+func (r *Recorder) WriteCSV(path string) error {
+	r.mu.Lock()
+	timings := make([]Timing, len(r.timings))
+	copy(timings, r.timings)
+	r.mu.Unlock()
+	sort.SliceStable(timings, func(i, j int) bool {
+		return timings[i].Start.Before(timings[j].Start)
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create timings file '%s'", path)
+	}
+	defer f.Close()
+	rows := [][]string{{"Phase", "Username", "Start", "Duration (s)"}}
+	for _, t := range timings {
+		rows = append(rows, []string{t.Phase, t.Username, t.Start.Format(time.RFC3339Nano), fmt.Sprintf("%.3f", t.Duration.Seconds())})
+	}
+	return csv.NewWriter(f).WriteAll(rows)
+}
+
+// Span is the time from the start of the first user to the end of the last user of a phase
+type Span struct {
+	Phase string
+	Start time.Time
+	End   time.Time
+}
+
+// Spans returns the span of each phase with recorded timings, ordered by start time
+func (r *Recorder) Spans() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byPhase := map[string]*Span{}
+	for _, t := range r.timings {
+		end := t.Start.Add(t.Duration)
+		s, found := byPhase[t.Phase]
+		if !found {
+			byPhase[t.Phase] = &Span{Phase: t.Phase, Start: t.Start, End: end}
+			continue
+		}
+		if t.Start.Before(s.Start) {
+			s.Start = t.Start
+		}
+		if end.After(s.End) {
+			s.End = end
+		}
+	}
+	spans := make([]Span, 0, len(byPhase))
+	for _, s := range byPhase {
+		spans = append(spans, *s)
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if !spans[i].Start.Equal(spans[j].Start) {
+			return spans[i].Start.Before(spans[j].Start)
+		}
+		return spans[i].Phase < spans[j].Phase
+	})
+	return spans
+}
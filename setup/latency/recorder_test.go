@@ -0,0 +1,48 @@
+package latency
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	// given
+	r := NewRecorder()
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "timings.csv")
+
+	// when
+	r.Record("signups", "zippy-0002", start.Add(time.Second), 3*time.Second)
+	r.Record("signups", "zippy-0001", start, 2*time.Second)
+	r.Record("idler-setup", "zippy-0001", start.Add(2*time.Second), 500*time.Millisecond)
+	err := r.WriteCSV(path)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.Distribution("signups").Count())
+	assert.Equal(t, 3*time.Second, r.Distribution("signups").Max())
+	assert.Equal(t, 1, r.Distribution("idler-setup").Count())
+	assert.Equal(t, 0, r.Distribution("default").Count())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Phase", "Username", "Start", "Duration (s)"},
+		{"signups", "zippy-0001", "2023-10-01T12:00:00Z", "2.000"},
+		{"signups", "zippy-0002", "2023-10-01T12:00:01Z", "3.000"},
+		{"idler-setup", "zippy-0001", "2023-10-01T12:00:02Z", "0.500"},
+	}, rows)
+	assert.Equal(t, []Span{
+		{Phase: "signups", Start: start, End: start.Add(4 * time.Second)},
+		{Phase: "idler-setup", Start: start.Add(2 * time.Second), End: start.Add(2500 * time.Millisecond)},
+	}, r.Spans())
+}
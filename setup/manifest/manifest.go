@@ -0,0 +1,222 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/operators"
+
+	configv1 "github.com/openshift/api/config/v1"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Manifest records what was tested by a run, so that the results of two runs can be told comparable or not
+type Manifest struct {
+	StartedAt       time.Time                              `json:"startedAt"`
+	GitSHA          string                                 `json:"gitSHA,omitempty"`
+	ClusterVersion  *ClusterVersion                        `json:"clusterVersion,omitempty"`
+	Operators       []Operator                             `json:"operators"`
+	SpaceTier       *SpaceTier                             `json:"spaceTier,omitempty"`
+	ToolchainConfig *toolchainv1alpha1.ToolchainConfigSpec `json:"toolchainConfig,omitempty"`
+	Nodes           []Node                                 `json:"nodes"`
+	Flags           map[string]string                      `json:"flags"`
+	// Errors are the parts of the manifest that could not be collected
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ClusterVersion is the OpenShift version of the cluster
+type ClusterVersion struct {
+	Version   string `json:"version"`
+	Channel   string `json:"channel,omitempty"`
+	ClusterID string `json:"clusterID"`
+}
+
+// Operator is a sandbox operator installed by a subscription
+type Operator struct {
+	Subscription string `json:"subscription"`
+	Namespace    string `json:"namespace"`
+	CSV          string `json:"csv"`
+	Version      string `json:"version,omitempty"`
+}
+
+// SpaceTier is the tier of the spaces of the users, its template refs include the revisions of the templates
+type SpaceTier struct {
+	Name             string            `json:"name"`
+	ClusterResources string            `json:"clusterResources,omitempty"`
+	Namespaces       []string          `json:"namespaces"`
+	SpaceRoles       map[string]string `json:"spaceRoles,omitempty"`
+}
+
+// Node is a node of the cluster with its roles and capacity
+type Node struct {
+	Name           string   `json:"name"`
+	Roles          []string `json:"roles"`
+	InstanceType   string   `json:"instanceType,omitempty"`
+	CPU            string   `json:"cpu"`
+	Memory         string   `json:"memory"`
+	KubeletVersion string   `json:"kubeletVersion"`
+}
+
+// Collect returns the manifest of a run started with the given flags. The parts that cannot be collected are recorded
+// in the errors of the manifest rather than failing the run.
+func Collect(cl client.Client, flags map[string]string) *Manifest {
+	m := &Manifest{
+		StartedAt: time.Now(),
+		GitSHA:    gitSHA(),
+		Operators: []Operator{},
+		Nodes:     []Node{},
+		Flags:     flags,
+	}
+	for _, collect := range []func(client.Client) error{m.collectClusterVersion, m.collectOperators, m.collectSpaceTier, m.collectToolchainConfig, m.collectNodes} {
+		if err := collect(cl); err != nil {
+			m.Errors = append(m.Errors, err.Error())
+		}
+	}
+	return m
+}
+
+func (m *Manifest) collectClusterVersion(cl client.Client) error {
+	cv := &configv1.ClusterVersion{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Name: "version"}, cv); err != nil {
+		return errors.Wrap(err, "unable to get the cluster version")
+	}
+	m.ClusterVersion = &ClusterVersion{
+		Version:   cv.Status.Desired.Version,
+		Channel:   cv.Spec.Channel,
+		ClusterID: string(cv.Spec.ClusterID),
+	}
+	return nil
+}
+
+func (m *Manifest) collectOperators(cl client.Client) error {
+	subs, err := operators.SandboxSubscriptions(cl)
+	if err != nil {
+		return errors.Wrap(err, "unable to list the subscriptions of the sandbox operators")
+	}
+	for _, sub := range subs {
+		o := Operator{
+			Subscription: sub.Name,
+			Namespace:    sub.Namespace,
+			CSV:          sub.Status.InstalledCSV,
+		}
+		if o.CSV != "" {
+			csv := &operatorsv1alpha1.ClusterServiceVersion{}
+			if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: sub.Namespace, Name: o.CSV}, csv); err != nil {
+				return errors.Wrapf(err, "unable to get the CSV '%s'", o.CSV)
+			}
+			o.Version = csv.Spec.Version.String()
+		}
+		m.Operators = append(m.Operators, o)
+	}
+	return nil
+}
+
+func (m *Manifest) collectSpaceTier(cl client.Client) error {
+	tier := &toolchainv1alpha1.NSTemplateTier{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: cfg.HostOperatorNamespace, Name: cfg.UserSpaceTier}, tier); err != nil {
+		return errors.Wrapf(err, "unable to get the '%s' tier", cfg.UserSpaceTier)
+	}
+	m.SpaceTier = &SpaceTier{
+		Name:       tier.Name,
+		Namespaces: []string{},
+	}
+	if tier.Spec.ClusterResources != nil {
+		m.SpaceTier.ClusterResources = tier.Spec.ClusterResources.TemplateRef
+	}
+	for _, ns := range tier.Spec.Namespaces {
+		m.SpaceTier.Namespaces = append(m.SpaceTier.Namespaces, ns.TemplateRef)
+	}
+	if len(tier.Spec.SpaceRoles) > 0 {
+		m.SpaceTier.SpaceRoles = map[string]string{}
+		for role, r := range tier.Spec.SpaceRoles {
+			m.SpaceTier.SpaceRoles[role] = r.TemplateRef
+		}
+	}
+	return nil
+}
+
+func (m *Manifest) collectToolchainConfig(cl client.Client) error {
+	toolchainCfg := &toolchainv1alpha1.ToolchainConfig{}
+	if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: cfg.HostOperatorNamespace, Name: "config"}, toolchainCfg); err != nil {
+		return errors.Wrap(err, "unable to get the ToolchainConfig")
+	}
+	m.ToolchainConfig = &toolchainCfg.Spec
+	return nil
+}
+
+func (m *Manifest) collectNodes(cl client.Client) error {
+	nodes := &corev1.NodeList{}
+	if err := cl.List(context.TODO(), nodes); err != nil {
+		return errors.Wrap(err, "unable to list the nodes")
+	}
+	for _, n := range nodes.Items {
+		roles := []string{}
+		for label := range n.Labels {
+			if role := strings.TrimPrefix(label, "node-role.kubernetes.io/"); role != label {
+				roles = append(roles, role)
+			}
+		}
+		sort.Strings(roles)
+		m.Nodes = append(m.Nodes, Node{
+			Name:           n.Name,
+			Roles:          roles,
+			InstanceType:   n.Labels[corev1.LabelInstanceTypeStable],
+			CPU:            n.Status.Capacity.Cpu().String(),
+			Memory:         n.Status.Capacity.Memory().String(),
+			KubeletVersion: n.Status.NodeInfo.KubeletVersion,
+		})
+	}
+	return nil
+}
+
+// gitSHA returns the commit of the setup binary, or of the working directory when the binary was built without the
+// version control information eg. with 'go run'. A '-dirty' suffix is added when there are uncommitted changes.
+func gitSHA() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		revision, modified := "", false
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				revision = s.Value
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if revision != "" {
+			if modified {
+				revision += "-dirty"
+			}
+			return revision
+		}
+	}
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	revision := strings.TrimSpace(string(out))
+	if status, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output(); err == nil && len(strings.TrimSpace(string(status))) > 0 {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// Write saves the manifest to the given path
+func (m *Manifest) Write(path string) error {
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(os.WriteFile(path, content, 0600), "unable to write the run manifest '%s'", path)
+}
@@ -0,0 +1,138 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/operator-framework/api/pkg/lib/version"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake" //nolint: staticcheck // not deprecated anymore: see https://github.com/kubernetes-sigs/controller-runtime/pull/1101
+)
+
+func TestCollect(t *testing.T) {
+	// given
+	cfg.HostOperatorNamespace = cfg.DefaultHostNS
+	s, err := cfg.NewScheme()
+	require.NoError(t, err)
+	clusterVersion := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Spec:       configv1.ClusterVersionSpec{ClusterID: "1234", Channel: "stable-4.13"},
+		Status:     configv1.ClusterVersionStatus{Desired: configv1.Release{Version: "4.13.4"}},
+	}
+	hostSub := &operatorsv1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "subscription-toolchain-host-operator", Namespace: cfg.DefaultHostNS},
+		Status:     operatorsv1alpha1.SubscriptionStatus{InstalledCSV: "toolchain-host-operator.v0.0.1-123"},
+	}
+	csvVersion := version.OperatorVersion{}
+	require.NoError(t, csvVersion.UnmarshalJSON([]byte(`"0.0.1-123"`)))
+	hostCSV := &operatorsv1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "toolchain-host-operator.v0.0.1-123", Namespace: cfg.DefaultHostNS},
+		Spec:       operatorsv1alpha1.ClusterServiceVersionSpec{Version: csvVersion},
+	}
+	otherSub := &operatorsv1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "kiali", Namespace: "openshift-operators"},
+	}
+	tier := &toolchainv1alpha1.NSTemplateTier{
+		ObjectMeta: metav1.ObjectMeta{Name: cfg.UserSpaceTier, Namespace: cfg.DefaultHostNS},
+		Spec: toolchainv1alpha1.NSTemplateTierSpec{
+			ClusterResources: &toolchainv1alpha1.NSTemplateTierClusterResources{TemplateRef: "base1ns-clusterresources-abcd"},
+			Namespaces:       []toolchainv1alpha1.NSTemplateTierNamespace{{TemplateRef: "base1ns-dev-1234"}},
+			SpaceRoles:       map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{"admin": {TemplateRef: "base1ns-admin-5678"}},
+		},
+	}
+	defaultTier := "base1ns"
+	toolchainCfg := &toolchainv1alpha1.ToolchainConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: cfg.DefaultHostNS},
+	}
+	toolchainCfg.Spec.Host.Tiers.DefaultSpaceTier = &defaultTier
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "worker-1",
+			Labels: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+				corev1.LabelInstanceTypeStable:   "m5.2xlarge",
+			},
+		},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("8"),
+				corev1.ResourceMemory: resource.MustParse("32Gi"),
+			},
+			NodeInfo: corev1.NodeSystemInfo{KubeletVersion: "v1.26.5"},
+		},
+	}
+	flags := map[string]string{"users": "2000", "token": "<redacted>"}
+
+	t.Run("all parts", func(t *testing.T) {
+		// given
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(clusterVersion, hostSub, hostCSV, otherSub, tier, toolchainCfg, node).Build()
+
+		// when
+		m := Collect(cl, flags)
+
+		// then
+		assert.Empty(t, m.Errors)
+		assert.Equal(t, &ClusterVersion{Version: "4.13.4", Channel: "stable-4.13", ClusterID: "1234"}, m.ClusterVersion)
+		assert.Equal(t, []Operator{{Subscription: "subscription-toolchain-host-operator", Namespace: cfg.DefaultHostNS, CSV: "toolchain-host-operator.v0.0.1-123", Version: "0.0.1-123"}}, m.Operators)
+		assert.Equal(t, &SpaceTier{
+			Name:             "base1ns",
+			ClusterResources: "base1ns-clusterresources-abcd",
+			Namespaces:       []string{"base1ns-dev-1234"},
+			SpaceRoles:       map[string]string{"admin": "base1ns-admin-5678"},
+		}, m.SpaceTier)
+		require.NotNil(t, m.ToolchainConfig)
+		assert.Equal(t, "base1ns", *m.ToolchainConfig.Host.Tiers.DefaultSpaceTier)
+		assert.Equal(t, []Node{{Name: "worker-1", Roles: []string{"worker"}, InstanceType: "m5.2xlarge", CPU: "8", Memory: "32Gi", KubeletVersion: "v1.26.5"}}, m.Nodes)
+		assert.Equal(t, flags, m.Flags)
+	})
+
+	t.Run("missing parts", func(t *testing.T) {
+		// given
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(hostSub, hostCSV, node).Build()
+
+		// when
+		m := Collect(cl, flags)
+
+		// then
+		assert.Nil(t, m.ClusterVersion)
+		assert.Nil(t, m.SpaceTier)
+		assert.Nil(t, m.ToolchainConfig)
+		assert.Len(t, m.Operators, 1)
+		assert.Len(t, m.Nodes, 1)
+		require.Len(t, m.Errors, 3)
+		assert.Contains(t, m.Errors[0], "unable to get the cluster version")
+		assert.Contains(t, m.Errors[1], "unable to get the 'base1ns' tier")
+		assert.Contains(t, m.Errors[2], "unable to get the ToolchainConfig")
+	})
+
+	t.Run("write", func(t *testing.T) {
+		// given
+		cl := fake.NewClientBuilder().WithScheme(s).WithObjects(clusterVersion, node).Build()
+		m := Collect(cl, flags)
+		path := filepath.Join(t.TempDir(), "run-manifest.json")
+
+		// when
+		err := m.Write(path)
+
+		// then
+		require.NoError(t, err)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		written := &Manifest{}
+		require.NoError(t, json.Unmarshal(content, written))
+		assert.Equal(t, m.ClusterVersion, written.ClusterVersion)
+		assert.Equal(t, m.Nodes, written.Nodes)
+		assert.Equal(t, flags, written.Flags)
+	})
+}
@@ -0,0 +1,175 @@
+package members
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Mode is how the users are distributed across the member clusters
+type Mode string
+
+const (
+	// RoundRobin assigns the users to each ready member cluster in turn
+	RoundRobin Mode = "round-robin"
+	// Host leaves the target cluster of the UserSignups empty so that the host operator picks the member cluster of each user
+	Host Mode = "host"
+	// List assigns the users in turn to the member clusters of an explicit list, eg. 'member-a,member-b'
+	List Mode = "list"
+	// Weighted assigns the users to the member clusters in proportion to their weights, eg. 'member-a=70,member-b=30'
+	Weighted Mode = "weighted"
+)
+
+// Distribution assigns the users to the member clusters, the member cluster of a user only depends on its number so that
+// a resumed run assigns the users to the same member clusters
+type Distribution struct {
+	Mode Mode
+	// names and weights are the member clusters of the List and Weighted modes, in the order of the distribution
+	names   []string
+	weights []int
+	// cycle is the sequence of member clusters that the users are assigned to in turn, it is empty in Host mode
+	cycle   []string
+	members []Member
+}
+
+// ParseDistribution parses the 'round-robin' and 'host' modes, a list of member clusters such as 'member-a,member-b' or
+// a list of weighted member clusters such as 'member-a=70,member-b=30'
+func ParseDistribution(value string) (*Distribution, error) {
+	switch Mode(value) {
+	case RoundRobin, Host:
+		return &Distribution{Mode: Mode(value)}, nil
+	}
+	d := &Distribution{}
+	for _, entry := range strings.Split(value, ",") {
+		name, weight, weighted := strings.Cut(strings.TrimSpace(entry), "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid member distribution '%s', must be 'round-robin', 'host' or a list of member clusters with optional weights eg. 'member-a=70,member-b=30'", value)
+		}
+		mode := List
+		w := 1
+		if weighted {
+			mode = Weighted
+			var err error
+			if w, err = strconv.Atoi(weight); err != nil || w <= 0 {
+				return nil, fmt.Errorf("invalid weight '%s' of member cluster '%s', must be a positive integer", weight, name)
+			}
+		}
+		if d.Mode != "" && d.Mode != mode {
+			return nil, fmt.Errorf("invalid member distribution '%s', either all or none of the member clusters must have a weight", value)
+		}
+		d.Mode = mode
+		d.names = append(d.names, name)
+		d.weights = append(d.weights, w)
+	}
+	return d, nil
+}
+
+// Bind sets the member clusters that the users are distributed across from the given ready member clusters. It returns an error
+// if a member cluster of the distribution is not ready.
+func (d *Distribution) Bind(ready []Member) error {
+	readyByName := make(map[string]Member, len(ready))
+	readyNames := make([]string, 0, len(ready))
+	for _, m := range ready {
+		readyByName[m.Name] = m
+		readyNames = append(readyNames, m.Name)
+	}
+	switch d.Mode {
+	case RoundRobin:
+		d.members = ready
+		d.cycle = readyNames
+		return nil
+	case Host:
+		d.members = ready
+		return nil
+	}
+	d.members = nil
+	weights := map[string]int{}
+	for i, name := range d.names {
+		m, ok := readyByName[name]
+		if !ok {
+			return fmt.Errorf("member cluster '%s' does not exist or is not ready, the ready member clusters are: %s", name, strings.Join(readyNames, ", "))
+		}
+		if _, seen := weights[name]; !seen {
+			d.members = append(d.members, m)
+		}
+		weights[name] += d.weights[i]
+	}
+	if d.Mode == List {
+		d.cycle = d.names
+		return nil
+	}
+	d.cycle = weightedCycle(d.members, weights)
+	return nil
+}
+
+// weightedCycle returns a sequence in which each member appears as many times as its weight, reduced by their greatest common
+// divisor. The members are interleaved as evenly as possible, with the smooth weighted round-robin of nginx.
+func weightedCycle(members []Member, weights map[string]int) []string {
+	divisor := 0
+	for _, w := range weights {
+		divisor = gcd(divisor, w)
+	}
+	total := 0
+	for name := range weights {
+		weights[name] /= divisor
+		total += weights[name]
+	}
+	cycle := make([]string, 0, total)
+	current := make([]int, len(members))
+	for len(cycle) < total {
+		for i, m := range members {
+			current[i] += weights[m.Name]
+		}
+		best := 0
+		for i := range members {
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		cycle = append(cycle, members[best].Name)
+	}
+	return cycle
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// TargetCluster returns the member cluster that the user with the given number, starting at 1, is assigned to. It is empty when
+// the host operator picks the member cluster.
+func (d *Distribution) TargetCluster(userNumber int) string {
+	if len(d.cycle) == 0 {
+		return ""
+	}
+	return d.cycle[(userNumber-1)%len(d.cycle)]
+}
+
+// Members returns the member clusters that the users are distributed across, all the ready member clusters in the
+// RoundRobin and Host modes
+func (d *Distribution) Members() []Member {
+	return d.members
+}
+
+// String returns how the users are distributed, eg. 'weighted: member-a=7, member-b=3'
+func (d *Distribution) String() string {
+	switch d.Mode {
+	case RoundRobin, Host:
+		names := make([]string, 0, len(d.members))
+		for _, m := range d.members {
+			names = append(names, m.Name)
+		}
+		return fmt.Sprintf("%s: %s", d.Mode, strings.Join(names, ", "))
+	case Weighted:
+		entries := make([]string, 0, len(d.names))
+		for i, name := range d.names {
+			entries = append(entries, fmt.Sprintf("%s=%d", name, d.weights[i]))
+		}
+		return fmt.Sprintf("%s: %s", d.Mode, strings.Join(entries, ", "))
+	default:
+		return fmt.Sprintf("%s: %s", d.Mode, strings.Join(d.names, ", "))
+	}
+}
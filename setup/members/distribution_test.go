@@ -0,0 +1,121 @@
+package members
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistribution(t *testing.T) {
+	// given
+	ready := []Member{
+		{Name: "member-a", OperatorNamespace: "toolchain-member-operator"},
+		{Name: "member-b", OperatorNamespace: "toolchain-member2-operator"},
+		{Name: "member-c", OperatorNamespace: "toolchain-member3-operator"},
+	}
+	targets := func(d *Distribution, users int) map[string]int {
+		counts := map[string]int{}
+		for n := 1; n <= users; n++ {
+			counts[d.TargetCluster(n)]++
+		}
+		return counts
+	}
+
+	t.Run("round-robin", func(t *testing.T) {
+		// given
+		d, err := ParseDistribution("round-robin")
+		require.NoError(t, err)
+
+		// when
+		err = d.Bind(ready)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, RoundRobin, d.Mode)
+		assert.Equal(t, "member-a", d.TargetCluster(1))
+		assert.Equal(t, "member-b", d.TargetCluster(2))
+		assert.Equal(t, "member-c", d.TargetCluster(3))
+		assert.Equal(t, "member-a", d.TargetCluster(4))
+		assert.Equal(t, ready, d.Members())
+		assert.Equal(t, "round-robin: member-a, member-b, member-c", d.String())
+	})
+
+	t.Run("host", func(t *testing.T) {
+		// given
+		d, err := ParseDistribution("host")
+		require.NoError(t, err)
+
+		// when
+		err = d.Bind(ready)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"": 10}, targets(d, 10))
+		assert.Equal(t, ready, d.Members())
+	})
+
+	t.Run("list", func(t *testing.T) {
+		// given
+		d, err := ParseDistribution("member-c, member-a,member-c")
+		require.NoError(t, err)
+
+		// when
+		err = d.Bind(ready)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, List, d.Mode)
+		assert.Equal(t, []string{"member-c", "member-a", "member-c", "member-c"}, []string{d.TargetCluster(1), d.TargetCluster(2), d.TargetCluster(3), d.TargetCluster(4)})
+		assert.Equal(t, []Member{ready[2], ready[0]}, d.Members())
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		// given
+		d, err := ParseDistribution("member-a=70,member-b=30")
+		require.NoError(t, err)
+
+		// when
+		err = d.Bind(ready)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Weighted, d.Mode)
+		assert.Equal(t, map[string]int{"member-a": 700, "member-b": 300}, targets(d, 1000))
+		// the members are interleaved rather than assigned in blocks
+		assert.Equal(t, map[string]int{"member-a": 7, "member-b": 3}, targets(d, 10))
+		assert.Equal(t, "member-a", d.TargetCluster(1))
+		assert.Equal(t, "member-b", d.TargetCluster(2))
+		assert.Equal(t, "weighted: member-a=70, member-b=30", d.String())
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		for value, msg := range map[string]string{
+			"":                     "invalid member distribution '', must be 'round-robin', 'host' or a list of member clusters with optional weights eg. 'member-a=70,member-b=30'",
+			"member-a,,member-b":   "invalid member distribution 'member-a,,member-b', must be 'round-robin', 'host' or a list of member clusters with optional weights eg. 'member-a=70,member-b=30'",
+			"member-a=0":           "invalid weight '0' of member cluster 'member-a', must be a positive integer",
+			"member-a=70%":         "invalid weight '70%' of member cluster 'member-a', must be a positive integer",
+			"member-a=70,member-b": "invalid member distribution 'member-a=70,member-b', either all or none of the member clusters must have a weight",
+		} {
+			t.Run(value, func(t *testing.T) {
+				// when
+				_, err := ParseDistribution(value)
+
+				// then
+				require.EqualError(t, err, msg)
+			})
+		}
+
+		t.Run("member cluster not ready", func(t *testing.T) {
+			// given
+			d, err := ParseDistribution("member-a=1,member-d=1")
+			require.NoError(t, err)
+
+			// when
+			err = d.Bind(ready)
+
+			// then
+			require.EqualError(t, err, "member cluster 'member-d' does not exist or is not ready, the ready member clusters are: member-a, member-b, member-c")
+		})
+	})
+}
@@ -0,0 +1,72 @@
+package members
+
+import (
+	"context"
+	"sort"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/testsupport/wait"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8swait "k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Member is a member cluster that the users can be provisioned on
+type Member struct {
+	// Name is the name of the ToolchainCluster of the member in the host operator namespace, it is the target cluster of a UserSignup
+	Name string
+	// OperatorNamespace is the namespace of the member operator
+	OperatorNamespace string
+}
+
+// Ready returns the member clusters whose ToolchainCluster is ready, sorted by name. It waits until at least one member cluster
+// is ready, or until the context is done.
+func Ready(ctx context.Context, cl client.Client, hostOperatorNamespace string) ([]Member, error) {
+	var ready []Member
+	err := k8swait.PollWithContext(ctx, configuration.DefaultRetryInterval, configuration.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		clusters := &toolchainv1alpha1.ToolchainClusterList{}
+		if err := cl.List(ctx, clusters, client.InNamespace(hostOperatorNamespace), client.MatchingLabels{
+			"type": "member",
+		}); err != nil {
+			return false, err
+		}
+		ready = nil
+		for _, cluster := range clusters.Items {
+			if containsClusterCondition(cluster.Status.Conditions, wait.ReadyToolchainCluster) {
+				ready = append(ready, Member{Name: cluster.Name, OperatorNamespace: cluster.Labels["namespace"]})
+			}
+		}
+		return len(ready) > 0, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to lookup the ready member clusters, ensure the sandbox setup steps are followed")
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return ready[i].Name < ready[j].Name
+	})
+	return ready, nil
+}
+
+// Placement returns the member cluster that the Space with the given name is provisioned on
+func Placement(ctx context.Context, cl client.Client, hostOperatorNamespace, space string) (string, error) {
+	sp := &toolchainv1alpha1.Space{}
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: hostOperatorNamespace, Name: space}, sp); err != nil {
+		return "", errors.Wrapf(err, "unable to get space '%s'", space)
+	}
+	return sp.Spec.TargetCluster, nil
+}
+
+func containsClusterCondition(conditions []toolchainv1alpha1.ToolchainClusterCondition, contains *toolchainv1alpha1.ToolchainClusterCondition) bool {
+	if contains == nil {
+		return true
+	}
+	for _, c := range conditions {
+		if c.Type == contains.Type {
+			return contains.Status == c.Status
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package members
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const hostOperatorNamespace = "toolchain-host-operator"
+
+func TestReady(t *testing.T) {
+	// given
+	configuration.DefaultTimeout = time.Second * 2
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t,
+			memberCluster("member-b", "toolchain-member2-operator", corev1.ConditionTrue),
+			memberCluster("member-a", "toolchain-member-operator", corev1.ConditionTrue),
+			memberCluster("member-c", "toolchain-member3-operator", corev1.ConditionFalse),
+		)
+
+		// when
+		ready, err := Ready(context.TODO(), cl, hostOperatorNamespace)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Member{
+			{Name: "member-a", OperatorNamespace: "toolchain-member-operator"},
+			{Name: "member-b", OperatorNamespace: "toolchain-member2-operator"},
+		}, ready)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+
+		t.Run("no ready member cluster", func(t *testing.T) {
+			// given
+			cl := commontest.NewFakeClient(t, memberCluster("member-c", "toolchain-member3-operator", corev1.ConditionFalse))
+
+			// when
+			_, err := Ready(context.TODO(), cl, hostOperatorNamespace)
+
+			// then
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "unable to lookup the ready member clusters, ensure the sandbox setup steps are followed")
+		})
+
+		t.Run("context done", func(t *testing.T) {
+			// given
+			cl := commontest.NewFakeClient(t) // no ToolchainCluster
+			ctx, cancel := context.WithCancel(context.TODO())
+			cancel()
+
+			// when
+			start := time.Now()
+			_, err := Ready(ctx, cl, hostOperatorNamespace)
+
+			// then
+			require.Error(t, err)
+			assert.Less(t, time.Since(start), configuration.DefaultTimeout)
+		})
+	})
+}
+
+func TestPlacement(t *testing.T) {
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, &toolchainv1alpha1.Space{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hostOperatorNamespace, Name: "user-0001"},
+			Spec:       toolchainv1alpha1.SpaceSpec{TargetCluster: "member-b"},
+		})
+
+		// when
+		member, err := Placement(context.TODO(), cl, hostOperatorNamespace, "user-0001")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "member-b", member)
+	})
+
+	t.Run("space not found", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t)
+
+		// when
+		_, err := Placement(context.TODO(), cl, hostOperatorNamespace, "user-0001")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to get space 'user-0001'")
+	})
+}
+
+func memberCluster(name, operatorNamespace string, ready corev1.ConditionStatus) runtime.Object {
+	return &toolchainv1alpha1.ToolchainCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hostOperatorNamespace,
+			Name:      name,
+			Labels: map[string]string{
+				"namespace": operatorNamespace,
+				"type":      "member",
+			},
+		},
+		Status: toolchainv1alpha1.ToolchainClusterStatus{
+			Conditions: []toolchainv1alpha1.ToolchainClusterCondition{
+				{
+					Type:   toolchainv1alpha1.ToolchainClusterReady,
+					Status: ready,
+				},
+			},
+		},
+	}
+}
@@ -1,20 +1,13 @@
 package metrics
 
-import "fmt"
-
 const MB = 1 << 20
 
-// bytesToMBString converts the given number of bytes to Megabytes and returns it as a string formatted to 2 decimal places
-func bytesToMBString(bytes float64) string {
-	return fmt.Sprintf("%.2f", float64(bytes)/MB)
-}
-
-// simple returns the provided number as a string formatted to 4 decimal places
-func simple(value float64) string {
-	return fmt.Sprintf("%.4f", value)
+// bytesToMB converts the given number of bytes to Megabytes
+func bytesToMB(bytes float64) float64 {
+	return bytes / MB
 }
 
-// percentage returns the provided number as a percentage string formatted to 2 decimal places
-func percentage(value float64) string {
-	return fmt.Sprintf("%.2f", value*100)
+// toPercentage converts the given ratio to a percentage
+func toPercentage(ratio float64) float64 {
+	return ratio * 100
 }
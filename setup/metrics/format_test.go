@@ -6,50 +6,46 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestBytesToMBString(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		t.Run("zero", func(t *testing.T) {
-			// given
-			var val float64
+func TestBytesToMB(t *testing.T) {
+	t.Run("zero", func(t *testing.T) {
+		// given
+		var val float64
 
-			// when
-			result := bytesToMBString(val)
+		// when
+		result := bytesToMB(val)
 
-			require.Equal(t, "0.00 MB", result)
-		})
+		require.Equal(t, 0.0, result)
+	})
 
-		t.Run("non-zero value", func(t *testing.T) {
-			// given
-			var val float64 = 123456789
+	t.Run("non-zero value", func(t *testing.T) {
+		// given
+		var val float64 = 123456789
 
-			// when
-			result := bytesToMBString(val)
+		// when
+		result := bytesToMB(val)
 
-			require.Equal(t, "117.74 MB", result)
-		})
+		require.InDelta(t, 117.74, result, 0.01)
 	})
 }
 
-func TestSimple(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		t.Run("zero", func(t *testing.T) {
-			// given
-			var val float64
+func TestToPercentage(t *testing.T) {
+	t.Run("zero", func(t *testing.T) {
+		// given
+		var val float64
 
-			// when
-			result := simple(val)
+		// when
+		result := toPercentage(val)
 
-			require.Equal(t, "0.0000", result)
-		})
+		require.Equal(t, 0.0, result)
+	})
 
-		t.Run("non-zero value", func(t *testing.T) {
-			// given
-			val := 123456789.123456789
+	t.Run("non-zero value", func(t *testing.T) {
+		// given
+		val := 0.1234
 
-			// when
-			result := simple(val)
+		// when
+		result := toPercentage(val)
 
-			require.Equal(t, "123456789.1235", result)
-		})
+		require.InDelta(t, 12.34, result, 0.0001)
 	})
 }
@@ -1,16 +1,21 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/codeready-toolchain/toolchain-e2e/setup/auth"
 	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics/queries"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
 	"github.com/pkg/errors"
+	prometheus "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 
 	k8sutil "k8s.io/apimachinery/pkg/util/wait"
@@ -28,51 +33,74 @@ const (
 	OSAPIServerWorkload  = "apiserver"
 )
 
+// maxRangePoints is the maximum number of points per series that Prometheus returns for a range query
+const maxRangePoints = 11000
+
 type Gatherer struct {
+	mu            sync.Mutex
 	k8sClient     client.Client
 	queryInterval time.Duration
-	mqueries      []queries.Query
-	results       map[string]aggregateResult
-	term          terminal.Terminal
+	// rangeStep is the resolution of the range queries run over the whole gathering window, range queries are disabled if it is 0
+	rangeStep time.Duration
+	mqueries  []queries.Query
+	// datapoints are the values of each series of each query, by query name
+	datapoints map[string][]Datapoint
+	// started is the start of the gathering window, rangeCollected is true once the range queries have been run
+	started        time.Time
+	rangeCollected bool
+	term           terminal.Terminal
 }
 
 type aggregateResult struct {
 	sampleCount int
 	max         float64
 	sum         float64
+	// increase is how much the series increased, for the queries that return counters
+	increase float64
 }
 
 func (r aggregateResult) avg() float64 {
+	if r.sampleCount == 0 {
+		return 0
+	}
 	return r.sum / float64(r.sampleCount)
 }
 
-// New creates a new gatherer with default queries
-func New(t terminal.Terminal, cl client.Client, token string, interval time.Duration) *Gatherer {
+// New creates a new gatherer with default queries. The queries are sampled at the given interval while gathering, and
+// run as range queries with the given step over the whole gathering window when the results are collected.
+// The samples are only used as a fallback when the step is 0 or when a range query fails.
+func New(t terminal.Terminal, cl client.Client, token string, interval, step time.Duration) *Gatherer {
 	g := &Gatherer{
 		k8sClient:     cl,
 		queryInterval: interval,
+		rangeStep:     step,
 		term:          t,
 	}
 
 	prometheusClient := GetPrometheusClient(t, cl, token)
 
+	olmOperator := queries.Workload{Kind: queries.Deployment, Namespace: OLMOperatorNamespace, Name: OLMOperatorWorkload}
+	osAPIServer := queries.Workload{Kind: queries.Deployment, Namespace: OSAPIServerNamespace, Name: OSAPIServerWorkload}
+	hostOperator := queries.Workload{Kind: queries.Deployment, Namespace: cfg.HostOperatorNamespace, Name: cfg.HostOperatorWorkload}
+	memberOperator := queries.Workload{Kind: queries.Deployment, Namespace: cfg.MemberOperatorNamespace, Name: cfg.MemberOperatorWorkload}
+
 	// Add default queries
 	g.AddQueries(
 		queries.QueryClusterCPUUtilisation(prometheusClient),
 		queries.QueryClusterMemoryUtilisation(prometheusClient),
 		queries.QueryNodeMemoryUtilisation(prometheusClient),
 		queries.QueryEtcdMemoryUsage(prometheusClient),
-		queries.QueryWorkloadCPUUsage(prometheusClient, OLMOperatorNamespace, OLMOperatorWorkload),
-		queries.QueryWorkloadMemoryUsage(prometheusClient, OLMOperatorNamespace, OLMOperatorWorkload),
+		queries.QueryWorkloadCPUUsage(prometheusClient, olmOperator),
+		queries.QueryWorkloadMemoryUsage(prometheusClient, olmOperator),
 		queries.QueryOpenshiftKubeAPIMemoryUtilisation(prometheusClient),
-		queries.QueryWorkloadCPUUsage(prometheusClient, OSAPIServerNamespace, OSAPIServerWorkload),
-		queries.QueryWorkloadMemoryUsage(prometheusClient, OSAPIServerNamespace, OSAPIServerWorkload),
-		queries.QueryWorkloadCPUUsage(prometheusClient, cfg.HostOperatorNamespace, cfg.HostOperatorWorkload),
-		queries.QueryWorkloadMemoryUsage(prometheusClient, cfg.HostOperatorNamespace, cfg.HostOperatorWorkload),
-		queries.QueryWorkloadCPUUsage(prometheusClient, cfg.MemberOperatorNamespace, cfg.MemberOperatorWorkload),
-		queries.QueryWorkloadMemoryUsage(prometheusClient, cfg.MemberOperatorNamespace, cfg.MemberOperatorWorkload),
+		queries.QueryWorkloadCPUUsage(prometheusClient, osAPIServer),
+		queries.QueryWorkloadMemoryUsage(prometheusClient, osAPIServer),
+		queries.QueryWorkloadCPUUsage(prometheusClient, hostOperator),
+		queries.QueryWorkloadMemoryUsage(prometheusClient, hostOperator),
+		queries.QueryWorkloadCPUUsage(prometheusClient, memberOperator),
+		queries.QueryWorkloadMemoryUsage(prometheusClient, memberOperator),
 	)
-	g.results = make(map[string]aggregateResult, len(g.mqueries))
+	g.datapoints = make(map[string][]Datapoint, len(g.mqueries))
 
 	return g
 }
@@ -84,7 +112,7 @@ func NewEmpty(t terminal.Terminal, cl client.Client, interval time.Duration) *Ga
 		queryInterval: interval,
 		term:          t,
 	}
-	g.results = make(map[string]aggregateResult, len(g.mqueries))
+	g.datapoints = make(map[string][]Datapoint, len(g.mqueries))
 	return g
 }
 
@@ -92,33 +120,143 @@ func (g *Gatherer) AddQueries(queries ...queries.Query) {
 	g.mqueries = append(g.mqueries, queries...)
 }
 
-func (g *Gatherer) StartGathering() chan struct{} {
+// StartGathering samples the queries at the gatherer's interval until the returned function is called or the context is done
+func (g *Gatherer) StartGathering(ctx context.Context) context.CancelFunc {
 	if len(g.mqueries) == 0 {
 		g.term.Infof("Metrics gatherer has no queries defined, skipping metrics gathering...")
-		return nil
+		return func() {}
 	}
 
-	stop := make(chan struct{})
+	g.mu.Lock()
+	g.started = time.Now()
+	g.mu.Unlock()
+	ctx, stop := context.WithCancel(ctx)
 	go func() {
-		k8sutil.Until(func() {
+		k8sutil.UntilWithContext(ctx, func(ctx context.Context) {
 			for _, q := range g.mqueries {
 				var metricsErr error
 				// added retry mechanism since temporary metrics errors have been observed, poll until the query returns a non-error result or the poll times out
-				err := k8sutil.Poll(cfg.DefaultRetryInterval, cfg.DefaultTimeout, func() (bool, error) {
+				err := k8sutil.PollWithContext(ctx, cfg.DefaultRetryInterval, cfg.DefaultTimeout, func(context.Context) (bool, error) {
 					metricsErr = g.sample(q)
 					return metricsErr == nil, nil
 				})
+				if ctx.Err() != nil {
+					// the gathering was stopped while the query was retried
+					return
+				}
 				if err != nil {
 					g.term.Fatalf(metricsErr, "metrics error")
 				}
 			}
-		}, g.queryInterval, stop)
+		}, g.queryInterval)
 	}()
 	return stop
 }
 
 func (g *Gatherer) sample(q queries.Query) error {
 	val, warnings, err := q.Execute()
+	if err := g.checkQueryError(err, warnings); err != nil {
+		return err
+	}
+
+	vector := val.(model.Vector)
+	if len(vector) == 0 {
+		return fmt.Errorf("metrics value could not be retrieved for query %s", q.Name())
+	}
+
+	datapoints := make([]Datapoint, 0, len(vector))
+	for _, v := range vector {
+		if !isNumber(v.Value) {
+			continue
+		}
+		datapoints = append(datapoints, Datapoint{
+			Query:     q.Name(),
+			Series:    v.Metric,
+			Timestamp: v.Timestamp.Time(),
+			Value:     float64(v.Value),
+		})
+	}
+	g.mu.Lock()
+	g.datapoints[q.Name()] = append(g.datapoints[q.Name()], datapoints...)
+	g.mu.Unlock()
+	g.term.Event(terminal.Event{
+		Type: terminal.MetricsSample,
+		Attributes: map[string]interface{}{
+			"query":      q.Name(),
+			"resultType": q.ResultType(),
+			"series":     len(datapoints),
+			"value":      reduce(datapoints, q.Reduction()).avg(),
+		},
+	})
+	return nil
+}
+
+// CollectRange runs each query as a range query over the gathering window and replaces the samples of the query with the
+// returned datapoints. The samples are kept for the queries whose range query fails. It does nothing if the range queries
+// are disabled or were already collected.
+func (g *Gatherer) CollectRange() {
+	g.mu.Lock()
+	if g.rangeStep == 0 || g.rangeCollected || g.started.IsZero() {
+		g.mu.Unlock()
+		return
+	}
+	g.rangeCollected = true
+	r := prometheus.Range{
+		Start: g.started,
+		End:   time.Now(),
+		Step:  g.rangeStep,
+	}
+	g.mu.Unlock()
+	// Prometheus rejects range queries that would return too many points per series
+	if minStep := r.End.Sub(r.Start) / maxRangePoints; r.Step < minStep {
+		r.Step = (minStep/time.Second + 1) * time.Second
+	}
+
+	for _, q := range g.mqueries {
+		if err := g.sampleRange(q, r); err != nil {
+			g.term.Errorf(err, "range query failed for query %s, falling back to the samples taken every %s", q.Name(), g.queryInterval)
+		}
+	}
+}
+
+func (g *Gatherer) sampleRange(q queries.Query, r prometheus.Range) error {
+	val, warnings, err := q.ExecuteRange(r)
+	if err := g.checkQueryError(err, warnings); err != nil {
+		return err
+	}
+
+	matrix, ok := val.(model.Matrix)
+	if !ok || len(matrix) == 0 {
+		return fmt.Errorf("metrics values could not be retrieved for query %s", q.Name())
+	}
+
+	datapoints := []Datapoint{}
+	for _, stream := range matrix {
+		for _, v := range stream.Values {
+			if !isNumber(v.Value) {
+				continue
+			}
+			datapoints = append(datapoints, Datapoint{
+				Query:     q.Name(),
+				Series:    stream.Metric,
+				Timestamp: v.Timestamp.Time(),
+				Value:     float64(v.Value),
+			})
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.datapoints[q.Name()] = datapoints
+	return nil
+}
+
+// isNumber returns false for the NaN and infinite values that are returned by some queries (eg. a division by zero),
+// they are left out so that they don't spoil the aggregates
+func isNumber(v model.SampleValue) bool {
+	return !math.IsNaN(float64(v)) && !math.IsInf(float64(v), 0)
+}
+
+func (g *Gatherer) checkQueryError(err error, warnings prometheus.Warnings) error {
 	if err != nil {
 		if strings.Contains(err.Error(), "client error: 403") {
 			url, tokenErr := auth.GetTokenRequestURI(g.k8sClient)
@@ -131,45 +269,289 @@ func (g *Gatherer) sample(q queries.Query) error {
 	} else if len(warnings) > 0 {
 		return errors.Wrapf(fmt.Errorf("warnings: %v", warnings), "metrics query had unexpected warnings")
 	}
+	return nil
+}
 
-	vector := val.(model.Vector)
-	if len(vector) == 0 {
-		return fmt.Errorf("metrics value could not be retrieved for query %s", q.Name())
+// aggregate computes the average and max of the datapoints of the given query. If the query returns multiple series,
+// the values of the series at the same time are combined into a single datapoint with the given reduction.
+func (g *Gatherer) aggregate(name string, reduction queries.Reduction) aggregateResult {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r := reduce(g.datapoints[name], reduction)
+	r.increase = increase(g.datapoints[name])
+	return r
+}
+
+func reduce(datapoints []Datapoint, reduction queries.Reduction) aggregateResult {
+	r := aggregateResult{}
+	for _, datapoint := range reducePoints(datapoints, reduction) {
+		r.max = math.Max(r.max, datapoint)
+		r.sum += datapoint
+		r.sampleCount++
 	}
+	return r
+}
 
-	// if a result returns multiple vector samples we'll take the average of the values to get a single datapoint for the sake of simplicity
-	var vectorSum float64
-	for _, v := range vector {
-		vectorSum += float64(v.Value)
+// reducePoints combines the values of the series at the same time into a single value with the given reduction
+func reducePoints(datapoints []Datapoint, reduction queries.Reduction) map[time.Time]float64 {
+	seriesSum := map[time.Time]float64{}
+	seriesMax := map[time.Time]float64{}
+	seriesCount := map[time.Time]int{}
+	for _, d := range datapoints {
+		if seriesCount[d.Timestamp] == 0 || d.Value > seriesMax[d.Timestamp] {
+			seriesMax[d.Timestamp] = d.Value
+		}
+		seriesSum[d.Timestamp] += d.Value
+		seriesCount[d.Timestamp]++
 	}
-	datapoint := vectorSum / float64(len(vector))
+	points := make(map[time.Time]float64, len(seriesSum))
+	for ts, sum := range seriesSum {
+		switch reduction {
+		case queries.Sum:
+			points[ts] = sum
+		case queries.Max:
+			points[ts] = seriesMax[ts]
+		default:
+			points[ts] = sum / float64(seriesCount[ts])
+		}
+	}
+	return points
+}
 
-	r := g.results[q.Name()]
-	r.max = math.Max(r.max, datapoint)
-	r.sum += datapoint
-	r.sampleCount++
-	g.results[q.Name()] = r
-	return nil
+// increase returns the sum of the increases of each series of counters, a value lower than the previous one is a reset
+// of the counter (eg. a new pod with the same labels) so the increase goes on from 0
+func increase(datapoints []Datapoint) float64 {
+	bySeries := map[model.Fingerprint][]Datapoint{}
+	for _, d := range datapoints {
+		fp := d.Series.Fingerprint()
+		bySeries[fp] = append(bySeries[fp], d)
+	}
+	total := 0.0
+	for _, series := range bySeries {
+		sort.Slice(series, func(i, j int) bool {
+			return series[i].Timestamp.Before(series[j].Timestamp)
+		})
+		for i := 1; i < len(series); i++ {
+			if delta := series[i].Value - series[i-1].Value; delta >= 0 {
+				total += delta
+			} else {
+				total += series[i].Value
+			}
+		}
+	}
+	return total
+}
+
+type seriesResult struct {
+	// labels are the labels that differ between the series of the query
+	labels model.Metric
+	aggregateResult
+}
+
+// aggregateSeries computes the average and max of each series of the given query, ordered by labels. It returns nothing
+// if the query returned a single series since its results are the same as the overall ones.
+func (g *Gatherer) aggregateSeries(name string) []seriesResult {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	bySeries := map[model.Fingerprint][]Datapoint{}
+	series := map[model.Fingerprint]model.Metric{}
+	for _, d := range g.datapoints[name] {
+		fp := d.Series.Fingerprint()
+		bySeries[fp] = append(bySeries[fp], d)
+		series[fp] = d.Series
+	}
+	if len(series) < 2 {
+		return nil
+	}
+	labels := distinctLabels(series)
+	results := make([]seriesResult, 0, len(series))
+	for fp, datapoints := range bySeries {
+		r := reduce(datapoints, queries.Avg)
+		r.increase = increase(datapoints)
+		results = append(results, seriesResult{
+			labels:          labels[fp],
+			aggregateResult: r,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].labels.String() < results[j].labels.String()
+	})
+	return results
+}
+
+// distinctLabels returns the labels of each series restricted to the label names whose values are not the same for all
+// the series, so that eg. the etcd instances are told apart by their instance and pod but not by their job
+func distinctLabels(series map[model.Fingerprint]model.Metric) map[model.Fingerprint]model.Metric {
+	values := map[model.LabelName]map[model.LabelValue]bool{}
+	for _, m := range series {
+		for name := range m {
+			values[name] = map[model.LabelValue]bool{}
+		}
+	}
+	for _, m := range series {
+		for name := range values {
+			values[name][m[name]] = true
+		}
+	}
+	labels := make(map[model.Fingerprint]model.Metric, len(series))
+	for fp, m := range series {
+		distinct := model.Metric{}
+		for name, v := range m {
+			if len(values[name]) > 1 {
+				distinct[name] = v
+			}
+		}
+		labels[fp] = distinct
+	}
+	return labels
 }
 
-// ComputeResults iterates through each query and aggregates the results
-func (g *Gatherer) ComputeResults() [][]string {
-	var tuples [][]string
+// ComputeResults iterates through each query and aggregates the results. The queries whose series are kept have
+// additional results for each series.
+func (g *Gatherer) ComputeResults() []results.Result {
+	var computed []results.Result
 	for _, q := range g.mqueries {
-		result := g.results[q.Name()]
-		switch q.ResultType() {
-		case "percentage":
-			tuples = append(tuples, []string{fmt.Sprintf("Average %s (%%)", q.Name()), percentage(result.avg())})
-			tuples = append(tuples, []string{fmt.Sprintf("Max %s (%%)", q.Name()), percentage(result.max)})
-		case "memory":
-			tuples = append(tuples, []string{fmt.Sprintf("Average %s (MB)", q.Name()), bytesToMBString(result.avg())})
-			tuples = append(tuples, []string{fmt.Sprintf("Max %s (MB)", q.Name()), bytesToMBString(result.max)})
-		case "simple":
-			tuples = append(tuples, []string{fmt.Sprintf("Average %s", q.Name()), simple(result.avg())})
-			tuples = append(tuples, []string{fmt.Sprintf("Max %s", q.Name()), simple(result.max)})
-		default:
-			g.term.Fatalf(fmt.Errorf("query %s is missing a result type", q.Name()), "invalid query")
+		computed = append(computed, g.queryResults(q, nil, g.aggregate(q.Name(), q.Reduction()))...)
+		if q.Reduction() != queries.Keep {
+			continue
+		}
+		for _, s := range g.aggregateSeries(q.Name()) {
+			labels := make(map[string]string, len(s.labels))
+			for name, value := range s.labels {
+				labels[string(name)] = string(value)
+			}
+			computed = append(computed, g.queryResults(q, labels, s.aggregateResult)...)
 		}
 	}
-	return tuples
+	return computed
+}
+
+// WindowResults returns the results of the queries whose name contains the given workload, computed from their datapoints
+// between from and to only, for the given phase, eg. the usage of an operator while it rolls out a change. The results are
+// named after the window, eg. 'member-operator Memory Usage During Tier Rollout', to tell them apart from the results of the
+// whole run.
+func (g *Gatherer) WindowResults(phase, window string, from, to time.Time, workload string) []results.Result {
+	var computed []results.Result
+	for _, q := range g.mqueries {
+		if !strings.Contains(q.Name(), workload) {
+			continue
+		}
+		g.mu.Lock()
+		var datapoints []Datapoint
+		for _, d := range g.datapoints[q.Name()] {
+			if !d.Timestamp.Before(from) && !d.Timestamp.After(to) {
+				datapoints = append(datapoints, d)
+			}
+		}
+		g.mu.Unlock()
+		r := reduce(datapoints, q.Reduction())
+		r.increase = increase(datapoints)
+		for _, result := range g.queryResults(q, nil, r) {
+			result.Name = fmt.Sprintf("%s During %s", result.Name, window)
+			result.Phase = phase
+			computed = append(computed, result)
+		}
+	}
+	return computed
+}
+
+// MemoryGrowth returns how fast the memory usage of each memory query grew over the gathering window, as the slope of the
+// linear regression of its datapoints in MB per hour, to spot the leaks during the long runs
+func (g *Gatherer) MemoryGrowth() []results.Result {
+	var growth []results.Result
+	for _, q := range g.mqueries {
+		if q.ResultType() != "memory" {
+			continue
+		}
+		g.mu.Lock()
+		points := reducePoints(g.datapoints[q.Name()], q.Reduction())
+		g.mu.Unlock()
+		perSecond, ok := slope(points)
+		if !ok {
+			continue
+		}
+		growth = append(growth, results.Result{
+			Name:      q.Name() + " Growth",
+			Unit:      results.MegabytesPerHour,
+			Value:     bytesToMB(perSecond * time.Hour.Seconds()),
+			Precision: 2,
+		})
+	}
+	return growth
+}
+
+// slope returns the slope per second of the least squares line through the given points, it returns false if there are
+// not at least two points
+func slope(points map[time.Time]float64) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	var origin time.Time
+	for ts := range points {
+		if origin.IsZero() || ts.Before(origin) {
+			origin = ts
+		}
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for ts, v := range points {
+		x := ts.Sub(origin).Seconds()
+		sumX += x
+		sumY += v
+		sumXY += x * v
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / denominator, true
+}
+
+// queryResults returns the average and the max of the query, or its total increase for a counter, in the unit of its result type
+func (g *Gatherer) queryResults(q queries.Query, labels map[string]string, r aggregateResult) []results.Result {
+	result := func(aggregate results.Aggregate, unit results.Unit, value float64, precision int) results.Result {
+		return results.Result{
+			Name:      q.Name(),
+			Unit:      unit,
+			Aggregate: aggregate,
+			Value:     value,
+			Labels:    labels,
+			Precision: precision,
+		}
+	}
+	switch q.ResultType() {
+	case "percentage":
+		return []results.Result{
+			result(results.Average, results.Percent, toPercentage(r.avg()), 2),
+			result(results.Max, results.Percent, toPercentage(r.max), 2),
+		}
+	case "memory":
+		return []results.Result{
+			result(results.Average, results.Megabytes, bytesToMB(r.avg()), 2),
+			result(results.Max, results.Megabytes, bytesToMB(r.max), 2),
+		}
+	case "simple":
+		return []results.Result{
+			result(results.Average, results.NoUnit, r.avg(), 4),
+			result(results.Max, results.NoUnit, r.max, 4),
+		}
+	case "bytes/sec":
+		return []results.Result{
+			result(results.Average, results.MegabytesPerSecond, bytesToMB(r.avg()), 2),
+			result(results.Max, results.MegabytesPerSecond, bytesToMB(r.max), 2),
+		}
+	case "count":
+		return []results.Result{
+			result(results.Average, results.NoUnit, r.avg(), 2),
+			result(results.Max, results.NoUnit, r.max, 2),
+		}
+	case "increase":
+		return []results.Result{
+			result(results.Total, results.NoUnit, r.increase, 0),
+		}
+	default:
+		g.term.Fatalf(fmt.Errorf("query %s is missing a result type", q.Name()), "invalid query")
+		return nil
+	}
 }
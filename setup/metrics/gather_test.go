@@ -1,11 +1,17 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
-	"reflect"
+	"io"
+	"math"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics/queries"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
 	"github.com/stretchr/testify/require"
 
 	"github.com/codeready-toolchain/toolchain-common/pkg/test"
@@ -43,10 +49,8 @@ func TestExecuteQueryAndProcessResult(t *testing.T) {
 		{
 			query: testQuery{
 				name: "second sample larger value",
-				initResults: aggregateResult{
-					max:         120,
-					sampleCount: 1,
-					sum:         120,
+				initDatapoints: []Datapoint{
+					{Timestamp: testTime.Add(-time.Minute).Time(), Value: 120},
 				},
 				sample: queryResult{
 					val: model.Vector{
@@ -71,10 +75,8 @@ func TestExecuteQueryAndProcessResult(t *testing.T) {
 		{
 			query: testQuery{
 				name: "second sample smaller value",
-				initResults: aggregateResult{
-					max:         125,
-					sampleCount: 1,
-					sum:         125,
+				initDatapoints: []Datapoint{
+					{Timestamp: testTime.Add(-time.Minute).Time(), Value: 125},
 				},
 				sample: queryResult{
 					val: model.Vector{
@@ -125,43 +127,326 @@ func TestExecuteQueryAndProcessResult(t *testing.T) {
 		t.Run(fmt.Sprintf("test %s", tc.query.name), func(t *testing.T) {
 			// given
 			g := &Gatherer{
-				k8sClient: test.NewFakeClient(t),
-				mqueries:  []queries.Query{tc.query},
-				results:   map[string]aggregateResult{},
+				k8sClient:  test.NewFakeClient(t),
+				mqueries:   []queries.Query{tc.query},
+				datapoints: map[string][]Datapoint{},
+				term:       terminal.New(func() io.Reader { return strings.NewReader("") }, func() io.Writer { return io.Discard }, false),
 			}
-			if !reflect.DeepEqual(tc.query.initResults, aggregateResult{}) {
-				g.results[tc.query.name] = tc.query.initResults
+			if len(tc.query.initDatapoints) > 0 {
+				g.datapoints[tc.query.name] = tc.query.initDatapoints
 			}
 
 			// when
 			err := g.sample(tc.query)
 
 			// then
-			require.Equal(t, tc.exp.resultLen, len(g.results))
+			require.Equal(t, tc.exp.resultLen, len(g.datapoints))
 			if tc.exp.err != "" {
 				require.EqualError(t, err, tc.exp.err)
 			} else {
 				require.NoError(t, err)
 			}
 			if tc.exp.resultLen > 0 {
-				require.NotNil(t, g.results[tc.query.name])
-				require.Equal(t, tc.exp.max, g.results[tc.query.name].max)
-				require.Equal(t, tc.exp.sum, g.results[tc.query.name].sum)
-				require.Equal(t, tc.exp.sampleCount, g.results[tc.query.name].sampleCount)
+				result := g.aggregate(tc.query.name, queries.Avg)
+				require.Equal(t, tc.exp.max, result.max)
+				require.Equal(t, tc.exp.sum, result.sum)
+				require.Equal(t, tc.exp.sampleCount, result.sampleCount)
 			}
 		})
 	}
 }
 
+func TestStartGathering(t *testing.T) {
+	// given
+	q := testQuery{name: "memory", sample: queryResult{val: model.Vector{
+		&model.Sample{Metric: model.Metric{"pod": "host-operator-1"}, Value: 100, Timestamp: model.Now()},
+	}}}
+	g := newTestGatherer(t, 0, q)
+	g.queryInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	// when
+	stop := g.StartGathering(ctx)
+	defer stop()
+
+	// then
+	require.Eventually(t, func() bool {
+		return len(g.datapointsOf("memory")) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		// when
+		cancel()
+		time.Sleep(50 * time.Millisecond) // let the current sample complete
+		sampled := len(g.datapointsOf("memory"))
+		time.Sleep(50 * time.Millisecond)
+
+		// then
+		require.Len(t, g.datapointsOf("memory"), sampled)
+	})
+}
+
+func TestCollectRange(t *testing.T) {
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	matrix := model.Matrix{
+		&model.SampleStream{
+			Metric: model.Metric{"pod": "host-operator-1"},
+			Values: []model.SamplePair{
+				{Timestamp: model.TimeFromUnixNano(start.UnixNano()), Value: 100},
+				{Timestamp: model.TimeFromUnixNano(start.Add(30 * time.Second).UnixNano()), Value: 300},
+			},
+		},
+		&model.SampleStream{
+			Metric: model.Metric{"pod": "host-operator-2"},
+			Values: []model.SamplePair{
+				{Timestamp: model.TimeFromUnixNano(start.UnixNano()), Value: 200},
+				{Timestamp: model.TimeFromUnixNano(start.Add(30 * time.Second).UnixNano()), Value: model.SampleValue(math.NaN())},
+			},
+		},
+	}
+	sample := []Datapoint{
+		{Query: "memory", Timestamp: start, Value: 50},
+	}
+
+	t.Run("range datapoints replace the samples", func(t *testing.T) {
+		// given
+		q := testQuery{name: "memory", rangeSample: queryResult{val: matrix}}
+		g := newTestGatherer(t, 30*time.Second, q)
+		g.datapoints["memory"] = sample
+
+		// when
+		g.CollectRange()
+
+		// then
+		require.Len(t, g.datapoints["memory"], 3) // the NaN value is left out
+		result := g.aggregate("memory", queries.Avg)
+		require.Equal(t, 2, result.sampleCount)
+		require.Equal(t, 300.0, result.max) // average of the series at each timestamp
+		require.Equal(t, 450.0, result.sum)
+	})
+
+	t.Run("samples are kept when the range query fails", func(t *testing.T) {
+		// given
+		q := testQuery{name: "memory", rangeSample: queryResult{err: fmt.Errorf("test query error")}}
+		g := newTestGatherer(t, 30*time.Second, q)
+		g.datapoints["memory"] = sample
+
+		// when
+		g.CollectRange()
+
+		// then
+		require.Equal(t, sample, g.datapoints["memory"])
+	})
+
+	t.Run("range queries disabled", func(t *testing.T) {
+		// given
+		q := testQuery{name: "memory", rangeSample: queryResult{val: matrix}}
+		g := newTestGatherer(t, 0, q)
+		g.datapoints["memory"] = sample
+
+		// when
+		g.CollectRange()
+
+		// then
+		require.Equal(t, sample, g.datapoints["memory"])
+	})
+}
+
+func TestComputeResults(t *testing.T) {
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	datapoints := []Datapoint{
+		{Query: "etcd", Series: model.Metric{"job": "etcd", "instance": "etcd-1"}, Timestamp: start, Value: 100 * MB},
+		{Query: "etcd", Series: model.Metric{"job": "etcd", "instance": "etcd-2"}, Timestamp: start, Value: 300 * MB},
+		{Query: "etcd", Series: model.Metric{"job": "etcd", "instance": "etcd-1"}, Timestamp: start.Add(time.Minute), Value: 200 * MB},
+		{Query: "etcd", Series: model.Metric{"job": "etcd", "instance": "etcd-2"}, Timestamp: start.Add(time.Minute), Value: 600 * MB},
+	}
+
+	for reduction, expected := range map[queries.Reduction][][]string{
+		queries.Sum: {
+			{"Average etcd (MB)", "600.00"},
+			{"Max etcd (MB)", "800.00"},
+		},
+		queries.Avg: {
+			{"Average etcd (MB)", "300.00"},
+			{"Max etcd (MB)", "400.00"},
+		},
+		queries.Max: {
+			{"Average etcd (MB)", "450.00"},
+			{"Max etcd (MB)", "600.00"},
+		},
+		queries.Keep: {
+			{"Average etcd (MB)", "300.00"},
+			{"Max etcd (MB)", "400.00"},
+			{`Average etcd {instance="etcd-1"} (MB)`, "150.00"},
+			{`Max etcd {instance="etcd-1"} (MB)`, "200.00"},
+			{`Average etcd {instance="etcd-2"} (MB)`, "450.00"},
+			{`Max etcd {instance="etcd-2"} (MB)`, "600.00"},
+		},
+	} {
+		t.Run(string(reduction), func(t *testing.T) {
+			// given
+			g := newTestGatherer(t, 0, testQuery{name: "etcd", reduction: reduction})
+			g.datapoints["etcd"] = datapoints
+
+			// when
+			results := titles(g.ComputeResults())
+
+			// then
+			require.Equal(t, expected, results)
+		})
+	}
+
+	t.Run("result types", func(t *testing.T) {
+		// given
+		g := newTestGatherer(t, 0,
+			testQuery{name: "etcd writes", resultType: "bytes/sec", reduction: queries.Sum},
+			testQuery{name: "etcd objects", resultType: "count", reduction: queries.Sum})
+		g.datapoints["etcd writes"] = datapoints
+		g.datapoints["etcd objects"] = []Datapoint{
+			{Query: "etcd objects", Timestamp: start, Value: 1000},
+			{Query: "etcd objects", Timestamp: start.Add(time.Minute), Value: 1001},
+		}
+
+		// when
+		results := titles(g.ComputeResults())
+
+		// then
+		require.Equal(t, [][]string{
+			{"Average etcd writes (MB/s)", "600.00"},
+			{"Max etcd writes (MB/s)", "800.00"},
+			{"Average etcd objects", "1000.50"},
+			{"Max etcd objects", "1001.00"},
+		}, results)
+	})
+
+	t.Run("counters", func(t *testing.T) {
+		// given
+		g := newTestGatherer(t, 0, testQuery{name: "restarts", resultType: "increase", reduction: queries.Keep})
+		g.datapoints["restarts"] = []Datapoint{
+			{Query: "restarts", Series: model.Metric{"pod": "operator-1"}, Timestamp: start, Value: 2},
+			{Query: "restarts", Series: model.Metric{"pod": "operator-1"}, Timestamp: start.Add(2 * time.Minute), Value: 5},
+			{Query: "restarts", Series: model.Metric{"pod": "operator-1"}, Timestamp: start.Add(time.Minute), Value: 3},
+			{Query: "restarts", Series: model.Metric{"pod": "operator-2"}, Timestamp: start, Value: 4},
+			// the counter was reset
+			{Query: "restarts", Series: model.Metric{"pod": "operator-2"}, Timestamp: start.Add(time.Minute), Value: 1},
+		}
+
+		// when
+		results := titles(g.ComputeResults())
+
+		// then
+		require.Equal(t, [][]string{
+			{"Total restarts", "4"},
+			{`Total restarts {pod="operator-1"}`, "3"},
+			{`Total restarts {pod="operator-2"}`, "1"},
+		}, results)
+	})
+
+	t.Run("single series is not reported twice", func(t *testing.T) {
+		// given
+		g := newTestGatherer(t, 0, testQuery{name: "etcd", reduction: queries.Keep})
+		g.datapoints["etcd"] = datapoints[:1]
+
+		// when
+		results := titles(g.ComputeResults())
+
+		// then
+		require.Equal(t, [][]string{
+			{"Average etcd (MB)", "100.00"},
+			{"Max etcd (MB)", "100.00"},
+		}, results)
+	})
+}
+
+func TestMemoryGrowth(t *testing.T) {
+	// given
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	g := newTestGatherer(t, 0,
+		testQuery{name: "host-operator Memory Usage", resultType: "memory", reduction: queries.Keep},
+		testQuery{name: "member-operator Memory Usage", resultType: "memory", reduction: queries.Keep},
+		testQuery{name: "host-operator CPU Usage", resultType: "simple", reduction: queries.Keep})
+	for i, v := range []float64{100, 130, 110, 140, 120, 150} {
+		// a sawtooth that grows by about 7MB every half hour
+		ts := start.Add(time.Duration(i) * 30 * time.Minute)
+		g.datapoints["host-operator Memory Usage"] = append(g.datapoints["host-operator Memory Usage"], Datapoint{Query: "host-operator Memory Usage", Timestamp: ts, Value: v * MB})
+		g.datapoints["host-operator CPU Usage"] = append(g.datapoints["host-operator CPU Usage"], Datapoint{Query: "host-operator CPU Usage", Timestamp: ts, Value: v})
+	}
+	// a single sample has no trend
+	g.datapoints["member-operator Memory Usage"] = []Datapoint{{Query: "member-operator Memory Usage", Timestamp: start, Value: 100 * MB}}
+
+	// when
+	results := titles(g.MemoryGrowth())
+
+	// then
+	require.Equal(t, [][]string{
+		{"host-operator Memory Usage Growth (MB/h)", "14.29"},
+	}, results)
+}
+
+func TestWindowResults(t *testing.T) {
+	// given
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	g := newTestGatherer(t, 0,
+		testQuery{name: "member-operator Memory Usage", resultType: "memory", reduction: queries.Sum},
+		testQuery{name: "host-operator Memory Usage", resultType: "memory", reduction: queries.Sum})
+	for i, v := range []float64{100, 200, 300, 400} {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		g.datapoints["member-operator Memory Usage"] = append(g.datapoints["member-operator Memory Usage"], Datapoint{Query: "member-operator Memory Usage", Timestamp: ts, Value: v * MB})
+		g.datapoints["host-operator Memory Usage"] = append(g.datapoints["host-operator Memory Usage"], Datapoint{Query: "host-operator Memory Usage", Timestamp: ts, Value: v * MB})
+	}
+
+	// when
+	windowResults := g.WindowResults("tier-rollout", "Tier Rollout", start.Add(time.Minute), start.Add(2*time.Minute), "member-operator")
+
+	// then
+	require.Equal(t, [][]string{
+		{"Average member-operator Memory Usage During Tier Rollout (MB)", "250.00"},
+		{"Max member-operator Memory Usage During Tier Rollout (MB)", "300.00"},
+	}, titles(windowResults))
+	for _, r := range windowResults {
+		require.Equal(t, "tier-rollout", r.Phase)
+	}
+}
+
+// titles returns the title and the formatted value of each result
+func titles(computed []results.Result) [][]string {
+	rows := [][]string{}
+	for _, r := range computed {
+		rows = append(rows, []string{r.Title(), r.FormattedValue()})
+	}
+	return rows
+}
+
+func newTestGatherer(t *testing.T, step time.Duration, q ...queries.Query) *Gatherer {
+	return &Gatherer{
+		k8sClient:  test.NewFakeClient(t),
+		mqueries:   q,
+		rangeStep:  step,
+		started:    time.Now().Add(-time.Hour),
+		datapoints: map[string][]Datapoint{},
+		term:       terminal.New(func() io.Reader { return strings.NewReader("") }, func() io.Writer { return io.Discard }, false),
+	}
+}
+
+// datapointsOf returns the datapoints of the given query while the gatherer may still be sampling
+func (g *Gatherer) datapointsOf(name string) []Datapoint {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.datapoints[name]
+}
+
 type testcase struct {
 	query testQuery
 	exp   expected
 }
 
 type testQuery struct {
-	name        string
-	initResults aggregateResult
-	sample      queryResult
+	name           string
+	initDatapoints []Datapoint
+	sample         queryResult
+	rangeSample    queryResult
+	reduction      queries.Reduction
+	resultType     string
 }
 
 type expected struct {
@@ -187,6 +472,18 @@ func (q testQuery) Execute() (model.Value, prometheus.Warnings, error) {
 	return result.val, result.warn, result.err
 }
 
+func (q testQuery) ExecuteRange(_ prometheus.Range) (model.Value, prometheus.Warnings, error) {
+	result := q.rangeSample
+	return result.val, result.warn, result.err
+}
+
 func (q testQuery) ResultType() string {
-	return "memory"
+	if q.resultType == "" {
+		return "memory"
+	}
+	return q.resultType
+}
+
+func (q testQuery) Reduction() queries.Reduction {
+	return q.reduction
 }
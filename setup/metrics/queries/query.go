@@ -15,12 +15,33 @@ const (
 	Percentage ResultType = "percentage"
 	Memory     ResultType = "memory"
 	Simple     ResultType = "simple"
+	// BytesPerSecond is a rate of bytes, eg. the network traffic or the etcd writes
+	BytesPerSecond ResultType = "bytes/sec"
+	// Count is a number of things, eg. the number of objects stored in etcd
+	Count ResultType = "count"
+	// Increase is a counter, its result is how much it increased during the run
+	Increase ResultType = "increase"
+)
+
+// Reduction is how the series returned by a query are combined into a single value at each point in time
+type Reduction string
+
+const (
+	Sum Reduction = "sum"
+	Avg Reduction = "avg"
+	Max Reduction = "max"
+	// Keep averages the series for the overall results, and also reports the results of each series
+	Keep Reduction = "keep"
 )
 
 type Query interface {
 	Name() string
+	// Execute returns the current value of the query, as a vector
 	Execute() (model.Value, prometheus.Warnings, error)
+	// ExecuteRange returns the values of the query over the given range, as a matrix
+	ExecuteRange(r prometheus.Range) (model.Value, prometheus.Warnings, error)
 	ResultType() string
+	Reduction() Reduction
 }
 
 type BaseQuery struct {
@@ -28,6 +49,7 @@ type BaseQuery struct {
 	name       string
 	query      string
 	resultType ResultType
+	reduction  Reduction
 }
 
 func (b BaseQuery) Name() string {
@@ -38,10 +60,32 @@ func (b *BaseQuery) Execute() (model.Value, prometheus.Warnings, error) {
 	return b.apiClient.Query(context.TODO(), b.query, time.Now())
 }
 
+func (b *BaseQuery) ExecuteRange(r prometheus.Range) (model.Value, prometheus.Warnings, error) {
+	return b.apiClient.QueryRange(context.TODO(), b.query, r)
+}
+
 func (b BaseQuery) ResultType() string {
 	return string(b.resultType)
 }
 
+func (b BaseQuery) Reduction() Reduction {
+	if b.reduction == "" {
+		return Avg
+	}
+	return b.reduction
+}
+
+// New returns a query for the given PromQL expression, the series are averaged if the reduction is empty
+func New(apiClient prometheus.API, name, query string, resultType ResultType, reduction Reduction) *BaseQuery {
+	return &BaseQuery{
+		apiClient:  apiClient,
+		name:       name,
+		query:      query,
+		resultType: resultType,
+		reduction:  reduction,
+	}
+}
+
 func QueryOpenshiftKubeAPIMemoryUtilisation(apiClient prometheus.API) *BaseQuery {
 	return &BaseQuery{
 		apiClient:  apiClient,
@@ -57,6 +101,7 @@ func QueryEtcdMemoryUsage(apiClient prometheus.API) *BaseQuery {
 		name:       "etcd Instance Memory Usage",
 		query:      `process_resident_memory_bytes{job="etcd"}`,
 		resultType: Memory,
+		reduction:  Keep,
 	}
 }
 
@@ -78,34 +123,76 @@ func QueryClusterMemoryUtilisation(apiClient prometheus.API) *BaseQuery {
 	}
 }
 
-func QueryWorkloadCPUUsage(apiClient prometheus.API, namespace, name string) *BaseQuery {
+func QueryWorkloadCPUUsage(apiClient prometheus.API, w Workload) *BaseQuery {
 	query := fmt.Sprintf(`sum(
-		node_namespace_pod_container:container_cpu_usage_seconds_total:sum_irate{cluster="", namespace="%[1]s"}
-	  * on(namespace,pod)
-		group_left(workload, workload_type) namespace_workload_pod:kube_pod_owner:relabel{cluster="", namespace="%[1]s", workload="%[2]s", workload_type="deployment"}
-	) by (pod)`, namespace, name)
+		%s
+	) by (pod)`, w.podsOf(fmt.Sprintf(`node_namespace_pod_container:container_cpu_usage_seconds_total:sum_irate{cluster="", namespace="%s"}`, w.Namespace)))
 	return &BaseQuery{
 		apiClient:  apiClient,
-		name:       fmt.Sprintf("%s CPU Usage", name),
+		name:       fmt.Sprintf("%s CPU Usage", w.Name),
 		query:      query,
 		resultType: Simple,
+		reduction:  Keep,
 	}
 }
 
-func QueryWorkloadMemoryUsage(apiClient prometheus.API, namespace, name string) *BaseQuery {
+func QueryWorkloadMemoryUsage(apiClient prometheus.API, w Workload) *BaseQuery {
 	query := fmt.Sprintf(`sum(
-		container_memory_working_set_bytes{cluster="", namespace="%[1]s", container!="", image!=""}
-	  * on(namespace,pod)
-		group_left(workload, workload_type) namespace_workload_pod:kube_pod_owner:relabel{cluster="", namespace="%[1]s", workload="%[2]s", workload_type="deployment"}
-	) by (pod)`, namespace, name)
+		%s
+	) by (pod)`, w.podsOf(fmt.Sprintf(`container_memory_working_set_bytes{cluster="", namespace="%s", container!="", image!=""}`, w.Namespace)))
 	return &BaseQuery{
 		apiClient:  apiClient,
-		name:       fmt.Sprintf("%s Memory Usage", name),
+		name:       fmt.Sprintf("%s Memory Usage", w.Name),
 		query:      query,
 		resultType: Memory,
+		reduction:  Keep,
 	}
 }
 
+// QueryWorkloadRestarts returns the number of container restarts of the pods of the workload
+func QueryWorkloadRestarts(apiClient prometheus.API, w Workload) *BaseQuery {
+	query := fmt.Sprintf(`sum(
+		%s
+	) by (pod)`, w.podsOf(fmt.Sprintf(`kube_pod_container_status_restarts_total{namespace="%s"}`, w.Namespace)))
+	return &BaseQuery{
+		apiClient:  apiClient,
+		name:       fmt.Sprintf("%s Restarts", w.Name),
+		query:      query,
+		resultType: Increase,
+		reduction:  Keep,
+	}
+}
+
+// QueryWorkloadOOMKills returns the number of containers of the pods of the workload that were killed because they ran out of memory
+func QueryWorkloadOOMKills(apiClient prometheus.API, w Workload) *BaseQuery {
+	query := fmt.Sprintf(`sum(
+		%s
+	) by (pod)`, w.podsOf(fmt.Sprintf(`container_oom_events_total{namespace="%s", container!=""}`, w.Namespace)))
+	return &BaseQuery{
+		apiClient:  apiClient,
+		name:       fmt.Sprintf("%s OOMKills", w.Name),
+		query:      query,
+		resultType: Increase,
+		reduction:  Keep,
+	}
+}
+
+// QueryMemberOperatorCPUUsage returns the CPU usage of the operator workload of the given member cluster, named after the member
+// cluster so that the operators of several member clusters can be told apart
+func QueryMemberOperatorCPUUsage(apiClient prometheus.API, member string, w Workload) *BaseQuery {
+	q := QueryWorkloadCPUUsage(apiClient, w)
+	q.name = fmt.Sprintf("%s %s", member, q.name)
+	return q
+}
+
+// QueryMemberOperatorMemoryUsage returns the memory usage of the operator workload of the given member cluster, named after
+// the member cluster
+func QueryMemberOperatorMemoryUsage(apiClient prometheus.API, member string, w Workload) *BaseQuery {
+	q := QueryWorkloadMemoryUsage(apiClient, w)
+	q.name = fmt.Sprintf("%s %s", member, q.name)
+	return q
+}
+
 func QueryNodeMemoryUtilisation(apiClient prometheus.API) *BaseQuery {
 	query := `1 - sum (node_memory_MemAvailable_bytes * on(instance) (group by(instance)(label_replace(kube_node_role{role="master"}, "instance", "$1", "node", "(.*)"))))/
 	sum (node_memory_MemTotal_bytes * on(instance) (group by(instance)(label_replace(kube_node_role{role="master"}, "instance", "$1", "node", "(.*)"))))`
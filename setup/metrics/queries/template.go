@@ -0,0 +1,47 @@
+package queries
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Placeholders are the values that can be used in the name and the PromQL expression of a user-defined query,
+// eg. namespace="{{.HostNamespace}}"
+type Placeholders struct {
+	HostNamespace   string
+	MemberNamespace string
+	// Namespace, Workload and WorkloadType are the namespace, the name and the kind (eg. deployment) of a monitored workload,
+	// the queries that use them are run once for each workload
+	Namespace    string
+	Workload     string
+	WorkloadType string
+}
+
+// Render replaces the placeholders of the given text with their values
+func Render(text string, p Placeholders) (string, error) {
+	t, err := template.New("query").Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid placeholder")
+	}
+	result := &bytes.Buffer{}
+	if err := t.Execute(result, p); err != nil {
+		return "", errors.Wrapf(err, "invalid placeholder")
+	}
+	return result.String(), nil
+}
+
+// UsesWorkload returns true if the given text contains the Namespace, the Workload or the WorkloadType placeholder
+func UsesWorkload(text string) bool {
+	// the text depends on the workload if it is rendered differently for two workloads
+	first, err := Render(text, Placeholders{Namespace: "namespace-1", Workload: "workload-1", WorkloadType: string(Deployment)})
+	if err != nil {
+		return false
+	}
+	second, err := Render(text, Placeholders{Namespace: "namespace-2", Workload: "workload-2", WorkloadType: string(StatefulSet)})
+	if err != nil {
+		return false
+	}
+	return first != second
+}
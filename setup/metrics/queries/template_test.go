@@ -0,0 +1,44 @@
+package queries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	p := Placeholders{
+		HostNamespace:   "toolchain-host-operator",
+		MemberNamespace: "toolchain-member-operator",
+		Namespace:       "my-ns",
+		Workload:        "my-operator",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// when
+		query, err := Render(`sum(kube_pod_container_status_restarts_total{namespace=~"{{.HostNamespace}}|{{.MemberNamespace}}"})`, p)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, `sum(kube_pod_container_status_restarts_total{namespace=~"toolchain-host-operator|toolchain-member-operator"})`, query)
+	})
+
+	t.Run("unknown placeholder", func(t *testing.T) {
+		// when
+		_, err := Render(`up{namespace="{{.Unknown}}"}`, p)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid placeholder")
+	})
+}
+
+func TestUsesWorkload(t *testing.T) {
+	assert.True(t, UsesWorkload(`{{.Workload}} Restarts`))
+	assert.True(t, UsesWorkload(`up{namespace="{{ .Namespace }}"}`))
+	assert.True(t, UsesWorkload(`kube_pod_owner{owner_kind="{{.WorkloadType}}"}`))
+	assert.False(t, UsesWorkload(`up{namespace="{{.HostNamespace}}"}`))
+	assert.False(t, UsesWorkload(`up{job="etcd"}`))
+	assert.False(t, UsesWorkload(`{{.Unknown}}`))
+}
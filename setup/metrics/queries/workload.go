@@ -0,0 +1,55 @@
+package queries
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WorkloadKind is the kind of a workload, as set in the workload_type label of the pods owned by the workload
+type WorkloadKind string
+
+const (
+	Deployment  WorkloadKind = "deployment"
+	StatefulSet WorkloadKind = "statefulset"
+	DaemonSet   WorkloadKind = "daemonset"
+)
+
+var workloadKinds = []WorkloadKind{Deployment, StatefulSet, DaemonSet}
+
+// Workload is a workload whose pods are monitored
+type Workload struct {
+	Kind      WorkloadKind
+	Namespace string
+	Name      string
+}
+
+// ParseWorkload parses a kind/namespace/name triple, eg. 'statefulset/openshift-logging/elasticsearch'. The kind is case
+// insensitive. A namespace:name pair is a Deployment.
+func ParseWorkload(value string) (Workload, error) {
+	if pair := strings.Split(value, ":"); len(pair) == 2 && pair[0] != "" && pair[1] != "" {
+		return Workload{Kind: Deployment, Namespace: pair[0], Name: pair[1]}, nil
+	}
+	triple := strings.Split(value, "/")
+	if len(triple) != 3 || triple[1] == "" || triple[2] == "" {
+		return Workload{}, fmt.Errorf("invalid workload '%s', must be a kind/namespace/name triple or a namespace:name pair for a deployment", value)
+	}
+	kind := WorkloadKind(strings.ToLower(triple[0]))
+	for _, k := range workloadKinds {
+		if kind == k {
+			return Workload{Kind: kind, Namespace: triple[1], Name: triple[2]}, nil
+		}
+	}
+	return Workload{}, fmt.Errorf("invalid workload '%s', unknown kind '%s', must be one of deployment, statefulset or daemonset", value, triple[0])
+}
+
+func (w Workload) String() string {
+	return fmt.Sprintf("%s/%s/%s", w.Kind, w.Namespace, w.Name)
+}
+
+// podsOf joins the given metric, that has namespace and pod labels, with the pods of the workload
+func (w Workload) podsOf(metric string) string {
+	return fmt.Sprintf(`%[1]s
+	  * on(namespace,pod)
+		group_left(workload, workload_type) namespace_workload_pod:kube_pod_owner:relabel{cluster="", namespace="%[2]s", workload="%[3]s", workload_type="%[4]s"}`,
+		metric, w.Namespace, w.Name, w.Kind)
+}
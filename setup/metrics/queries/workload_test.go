@@ -0,0 +1,77 @@
+package queries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkload(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		for value, expected := range map[string]Workload{
+			"my-ns:my-operator":                           {Kind: Deployment, Namespace: "my-ns", Name: "my-operator"},
+			"deployment/my-ns/my-operator":                {Kind: Deployment, Namespace: "my-ns", Name: "my-operator"},
+			"StatefulSet/openshift-logging/elasticsearch": {Kind: StatefulSet, Namespace: "openshift-logging", Name: "elasticsearch"},
+			"daemonset/openshift-logging/collector":       {Kind: DaemonSet, Namespace: "openshift-logging", Name: "collector"},
+		} {
+			t.Run(value, func(t *testing.T) {
+				// when
+				w, err := ParseWorkload(value)
+
+				// then
+				require.NoError(t, err)
+				assert.Equal(t, expected, w)
+			})
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		for value, msg := range map[string]string{
+			"my-operator":           "invalid workload 'my-operator', must be a kind/namespace/name triple or a namespace:name pair for a deployment",
+			"deployment/my-ns/":     "invalid workload 'deployment/my-ns/', must be a kind/namespace/name triple or a namespace:name pair for a deployment",
+			"job/my-ns/my-operator": "invalid workload 'job/my-ns/my-operator', unknown kind 'job', must be one of deployment, statefulset or daemonset",
+		} {
+			t.Run(value, func(t *testing.T) {
+				// when
+				_, err := ParseWorkload(value)
+
+				// then
+				require.EqualError(t, err, msg)
+			})
+		}
+	})
+}
+
+func TestWorkloadQueries(t *testing.T) {
+	// given
+	w := Workload{Kind: StatefulSet, Namespace: "openshift-logging", Name: "elasticsearch"}
+
+	for _, q := range []*BaseQuery{
+		QueryWorkloadCPUUsage(nil, w),
+		QueryWorkloadMemoryUsage(nil, w),
+		QueryWorkloadRestarts(nil, w),
+		QueryWorkloadOOMKills(nil, w),
+	} {
+		t.Run(q.Name(), func(t *testing.T) {
+			// then
+			assert.Contains(t, q.query, `namespace="openshift-logging", workload="elasticsearch", workload_type="statefulset"`)
+			assert.Equal(t, Keep, q.Reduction())
+		})
+	}
+}
+
+func TestMemberOperatorQueries(t *testing.T) {
+	// given
+	w := Workload{Kind: Deployment, Namespace: "toolchain-member2-operator", Name: "member-operator-controller-manager"}
+
+	// when
+	cpu := QueryMemberOperatorCPUUsage(nil, "member-b", w)
+	memory := QueryMemberOperatorMemoryUsage(nil, "member-b", w)
+
+	// then
+	assert.Equal(t, "member-b member-operator-controller-manager CPU Usage", cpu.Name())
+	assert.Contains(t, cpu.query, `namespace="toolchain-member2-operator"`)
+	assert.Equal(t, "member-b member-operator-controller-manager Memory Usage", memory.Name())
+	assert.Equal(t, Memory, memory.resultType)
+}
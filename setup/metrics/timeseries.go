@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics/queries"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+)
+
+// Datapoint is the value of a series of a query at a given time
+type Datapoint struct {
+	Query     string       `json:"query"`
+	Series    model.Metric `json:"series"`
+	Timestamp time.Time    `json:"timestamp"`
+	Value     float64      `json:"value"`
+}
+
+// Datapoints returns all the datapoints gathered so far, ordered by time, query and series
+func (g *Gatherer) Datapoints() []Datapoint {
+	g.mu.Lock()
+	all := []Datapoint{}
+	for _, q := range g.mqueries {
+		all = append(all, g.datapoints[q.Name()]...)
+	}
+	g.mu.Unlock()
+	sort.SliceStable(all, func(i, j int) bool {
+		if !all[i].Timestamp.Equal(all[j].Timestamp) {
+			return all[i].Timestamp.Before(all[j].Timestamp)
+		}
+		if all[i].Query != all[j].Query {
+			return all[i].Query < all[j].Query
+		}
+		return all[i].Series.String() < all[j].Series.String()
+	})
+	return all
+}
+
+// WriteTimeSeries writes all the datapoints in long format, one row per timestamp, query and series, to the given CSV and JSON files
+func (g *Gatherer) WriteTimeSeries(csvPath, jsonPath string) error {
+	datapoints := g.Datapoints()
+
+	csvFile, err := os.Create(csvPath)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create time series file '%s'", csvPath)
+	}
+	defer csvFile.Close()
+	rows := [][]string{{"Timestamp", "Query", "Series", "Value"}}
+	for _, d := range datapoints {
+		rows = append(rows, []string{d.Timestamp.UTC().Format(time.RFC3339), d.Query, d.Series.String(), strconv.FormatFloat(d.Value, 'f', -1, 64)})
+	}
+	if err := csv.NewWriter(csvFile).WriteAll(rows); err != nil {
+		return errors.Wrapf(err, "unable to write time series file '%s'", csvPath)
+	}
+
+	content, err := json.Marshal(datapoints)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(os.WriteFile(jsonPath, content, 0600), "unable to write time series file '%s'", jsonPath)
+}
+
+// Point is a value of a line of a chart
+type Point struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Line is the values of a query over time, or of one of its series
+type Line struct {
+	Label  string
+	Points []Point
+}
+
+// Series is the values of a query over time in the unit of its results, with a line per series for the queries whose
+// series are kept and a single line of the reduced series for the other queries
+type Series struct {
+	Name  string
+	Unit  results.Unit
+	Lines []Line
+}
+
+// Series returns the datapoints of each query as lines, ordered by time
+func (g *Gatherer) Series() []Series {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	all := make([]Series, 0, len(g.mqueries))
+	for _, q := range g.mqueries {
+		unit, convert := seriesUnit(q.ResultType())
+		s := Series{Name: q.Name(), Unit: unit}
+		datapoints := g.datapoints[q.Name()]
+		if q.Reduction() == queries.Keep {
+			bySeries := map[model.Fingerprint][]Datapoint{}
+			series := map[model.Fingerprint]model.Metric{}
+			for _, d := range datapoints {
+				fp := d.Series.Fingerprint()
+				bySeries[fp] = append(bySeries[fp], d)
+				series[fp] = d.Series
+			}
+			labels := distinctLabels(series)
+			for fp, seriesDatapoints := range bySeries {
+				label := q.Name()
+				if len(labels[fp]) > 0 {
+					label = labels[fp].String()
+				}
+				s.Lines = append(s.Lines, newLine(label, reducePoints(seriesDatapoints, queries.Avg), convert))
+			}
+			sort.Slice(s.Lines, func(i, j int) bool {
+				return s.Lines[i].Label < s.Lines[j].Label
+			})
+		} else if len(datapoints) > 0 {
+			s.Lines = append(s.Lines, newLine(q.Name(), reducePoints(datapoints, q.Reduction()), convert))
+		}
+		all = append(all, s)
+	}
+	return all
+}
+
+func newLine(label string, points map[time.Time]float64, convert func(float64) float64) Line {
+	l := Line{Label: label, Points: make([]Point, 0, len(points))}
+	for ts, v := range points {
+		l.Points = append(l.Points, Point{Timestamp: ts, Value: convert(v)})
+	}
+	sort.Slice(l.Points, func(i, j int) bool {
+		return l.Points[i].Timestamp.Before(l.Points[j].Timestamp)
+	})
+	return l
+}
+
+// seriesUnit returns the unit of the results of the given result type, and the conversion of the values to this unit
+func seriesUnit(resultType string) (results.Unit, func(float64) float64) {
+	identity := func(v float64) float64 { return v }
+	switch resultType {
+	case "percentage":
+		return results.Percent, toPercentage
+	case "memory":
+		return results.Megabytes, bytesToMB
+	case "bytes/sec":
+		return results.MegabytesPerSecond, bytesToMB
+	default:
+		return results.NoUnit, identity
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics/queries"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTimeSeries(t *testing.T) {
+	// given
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	g := newTestGatherer(t, time.Minute, testQuery{name: "memory"}, testQuery{name: "cpu"})
+	g.datapoints["memory"] = []Datapoint{
+		{Query: "memory", Series: model.Metric{"pod": "b"}, Timestamp: start.Add(time.Minute), Value: 2},
+		{Query: "memory", Series: model.Metric{"pod": "a"}, Timestamp: start.Add(time.Minute), Value: 1},
+	}
+	g.datapoints["cpu"] = []Datapoint{
+		{Query: "cpu", Series: model.Metric{}, Timestamp: start, Value: 0.5},
+	}
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "timeseries.csv")
+	jsonPath := filepath.Join(dir, "timeseries.json")
+
+	// when
+	err := g.WriteTimeSeries(csvPath, jsonPath)
+
+	// then
+	require.NoError(t, err)
+	f, err := os.Open(csvPath)
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Timestamp", "Query", "Series", "Value"},
+		{"2023-10-01T12:00:00Z", "cpu", "{}", "0.5"},
+		{"2023-10-01T12:01:00Z", "memory", `{pod="a"}`, "1"},
+		{"2023-10-01T12:01:00Z", "memory", `{pod="b"}`, "2"},
+	}, rows)
+
+	content, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	datapoints := []Datapoint{}
+	require.NoError(t, json.Unmarshal(content, &datapoints))
+	require.Len(t, datapoints, 3)
+	assert.Equal(t, "memory", datapoints[1].Query)
+	assert.Equal(t, model.LabelValue("a"), datapoints[1].Series["pod"])
+}
+
+func TestSeries(t *testing.T) {
+	// given
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	g := newTestGatherer(t, time.Minute,
+		testQuery{name: "memory", reduction: queries.Sum},
+		testQuery{name: "etcd", reduction: queries.Keep},
+		testQuery{name: "cpu", resultType: "percentage"},
+		testQuery{name: "empty", resultType: "simple"})
+	g.datapoints["memory"] = []Datapoint{
+		{Query: "memory", Series: model.Metric{"pod": "a"}, Timestamp: start.Add(time.Minute), Value: MB},
+		{Query: "memory", Series: model.Metric{"pod": "b"}, Timestamp: start.Add(time.Minute), Value: 2 * MB},
+		{Query: "memory", Series: model.Metric{"pod": "a"}, Timestamp: start, Value: MB},
+	}
+	g.datapoints["etcd"] = []Datapoint{
+		{Query: "etcd", Series: model.Metric{"job": "etcd", "pod": "etcd-2"}, Timestamp: start, Value: 3 * MB},
+		{Query: "etcd", Series: model.Metric{"job": "etcd", "pod": "etcd-1"}, Timestamp: start, Value: 4 * MB},
+	}
+	g.datapoints["cpu"] = []Datapoint{
+		{Query: "cpu", Series: model.Metric{}, Timestamp: start, Value: 0.5},
+	}
+
+	// when
+	series := g.Series()
+
+	// then
+	assert.Equal(t, []Series{
+		{Name: "memory", Unit: results.Megabytes, Lines: []Line{
+			{Label: "memory", Points: []Point{{Timestamp: start, Value: 1}, {Timestamp: start.Add(time.Minute), Value: 3}}},
+		}},
+		{Name: "etcd", Unit: results.Megabytes, Lines: []Line{
+			{Label: `{pod="etcd-1"}`, Points: []Point{{Timestamp: start, Value: 4}}},
+			{Label: `{pod="etcd-2"}`, Points: []Point{{Timestamp: start, Value: 3}}},
+		}},
+		{Name: "cpu", Unit: results.Percent, Lines: []Line{
+			{Label: "cpu", Points: []Point{{Timestamp: start, Value: 50}}},
+		}},
+		{Name: "empty", Unit: results.NoUnit},
+	}, series)
+}
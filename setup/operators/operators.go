@@ -8,12 +8,15 @@ import (
 
 	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/templates"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/wait"
 
 	ctemplate "github.com/codeready-toolchain/toolchain-common/pkg/template"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -46,14 +49,14 @@ var Templates = []string{
 var csvTimeout = 10 * time.Second
 
 func VerifySandboxOperatorsInstalled(cl client.Client) error {
-	subs := &v1alpha1.SubscriptionList{}
-	if err := cl.List(context.TODO(), subs); err != nil {
+	subs, err := SandboxSubscriptions(cl)
+	if err != nil {
 		return err
 	}
 
 	foundHost := false
 	foundMember := false
-	for _, sub := range subs.Items {
+	for _, sub := range subs {
 		if strings.HasPrefix(sub.Name, hostSubscriptionName) {
 			foundHost = true
 		} else if strings.HasPrefix(sub.Name, memberSubscriptionName) {
@@ -66,7 +69,24 @@ func VerifySandboxOperatorsInstalled(cl client.Client) error {
 	return fmt.Errorf("the sandbox host and/or member operators were not found")
 }
 
-func EnsureOperatorsInstalled(cl client.Client, s *runtime.Scheme, templatePaths []string) error {
+// SandboxSubscriptions returns the subscriptions of the sandbox host and member operators
+func SandboxSubscriptions(cl client.Client) ([]v1alpha1.Subscription, error) {
+	subs := &v1alpha1.SubscriptionList{}
+	if err := cl.List(context.TODO(), subs); err != nil {
+		return nil, err
+	}
+	sandboxSubs := []v1alpha1.Subscription{}
+	for _, sub := range subs.Items {
+		if strings.HasPrefix(sub.Name, hostSubscriptionName) || strings.HasPrefix(sub.Name, memberSubscriptionName) {
+			sandboxSubs = append(sandboxSubs, sub)
+		}
+	}
+	return sandboxSubs, nil
+}
+
+// EnsureOperatorsInstalled applies the given templates and waits until the CSV of each Subscription has succeeded, the progress of
+// each installation is reported as events to the terminal
+func EnsureOperatorsInstalled(term terminal.Terminal, cl client.Client, s *runtime.Scheme, templatePaths []string) error {
 	for _, templatePath := range templatePaths {
 
 		tmpl, err := templates.GetTemplateFromFile(templatePath)
@@ -98,6 +118,11 @@ func EnsureOperatorsInstalled(cl client.Client, s *runtime.Scheme, templatePaths
 		}
 
 		startTime := time.Now()
+		term.Event(terminal.Event{
+			Type:       terminal.OperatorInstall,
+			Message:    fmt.Sprintf("Installing operator with subscription '%s'", subscriptionResource.GetName()),
+			Attributes: operatorAttributes(subscriptionResource, "", "started"),
+		})
 
 		// wait for operator installation to succeed
 		var csverr error
@@ -118,7 +143,12 @@ func EnsureOperatorsInstalled(cl client.Client, s *runtime.Scheme, templatePaths
 
 			if len(lastCSVs) == 0 || currentCSV != lastCSVs[len(lastCSVs)-1] { // subscription's current CSV has changed
 				lastCSVs = append(lastCSVs, currentCSV)
-				fmt.Printf("CurrentCSV of subscription: '%s'\n", currentCSV)
+				term.Event(terminal.Event{
+					Type:       terminal.OperatorInstall,
+					Message:    fmt.Sprintf("CurrentCSV of subscription: '%s'", currentCSV),
+					Duration:   time.Since(startTime),
+					Attributes: operatorAttributes(subscriptionResource, currentCSV, "installing"),
+				})
 			}
 
 			// wait for the CurrentCSV to reach Succeeded status
@@ -134,7 +164,7 @@ func EnsureOperatorsInstalled(cl client.Client, s *runtime.Scheme, templatePaths
 			return currentCSV == lastCSVs[len(lastCSVs)-1] // return true only if the CurrentCSV has not changed. ie. no upgrade needed
 		})
 		if len(lastCSVs) > 1 {
-			fmt.Printf("\nATTENTION! Update subscription '%s' StartingCSV to %s to speed up future installations\n\n", subscriptionResource.GetName(), lastCSVs[len(lastCSVs)-1])
+			term.Infof("\nATTENTION! Update subscription '%s' StartingCSV to %s to speed up future installations\n", subscriptionResource.GetName(), lastCSVs[len(lastCSVs)-1])
 		}
 		installDuration := time.Since(startTime)
 		if csverr != nil {
@@ -144,8 +174,73 @@ func EnsureOperatorsInstalled(cl client.Client, s *runtime.Scheme, templatePaths
 			return errors.Wrapf(err, "failed to verify installation of operator with subscription '%s' after %s", subscriptionResource.GetName(), installDuration.String())
 		}
 
-		fmt.Printf("Verified installation of operator with subscription '%s' completed in %s\n\n", subscriptionResource.GetName(), installDuration.String())
+		term.Event(terminal.Event{
+			Type:       terminal.OperatorInstall,
+			Message:    fmt.Sprintf("Verified installation of operator with subscription '%s' completed in %s", subscriptionResource.GetName(), installDuration.String()),
+			Duration:   installDuration,
+			Attributes: operatorAttributes(subscriptionResource, currentCSV, "installed"),
+		})
 	}
 
 	return nil
 }
+
+// operatorAttributes returns the attributes of the events of the installation of the operator of the given Subscription
+func operatorAttributes(subscription runtimeclient.Object, csv, status string) map[string]interface{} {
+	attributes := map[string]interface{}{
+		"subscription": subscription.GetName(),
+		"namespace":    subscription.GetNamespace(),
+		"status":       status,
+	}
+	if csv != "" {
+		attributes["csv"] = csv
+	}
+	return attributes
+}
+
+// EnsureOperatorsUninstalled removes the operators installed from the given templates: the CSV installed by each Subscription
+// and all the objects of the template, in reverse order. Objects that are already gone are ignored.
+func EnsureOperatorsUninstalled(term terminal.Terminal, cl client.Client, s *runtime.Scheme, templatePaths []string) error {
+	for _, templatePath := range templatePaths {
+		tmpl, err := templates.GetTemplateFromFile(templatePath)
+		if err != nil {
+			return errors.Wrapf(err, "invalid template file: '%s'", templatePath)
+		}
+
+		processor := ctemplate.NewProcessor(s)
+		objsToDelete, err := processor.Process(tmpl.DeepCopy(), map[string]string{})
+		if err != nil {
+			return err
+		}
+
+		attributes := map[string]interface{}{"status": "removed"}
+		for i := len(objsToDelete) - 1; i >= 0; i-- {
+			obj := objsToDelete[i]
+			if obj.GetObjectKind().GroupVersionKind().Kind == "Subscription" {
+				// the CSV is not removed by OLM when the subscription is deleted
+				sub := &v1alpha1.Subscription{}
+				if err := cl.Get(context.TODO(), types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, sub); err != nil && !k8serrors.IsNotFound(err) {
+					return err
+				}
+				attributes = operatorAttributes(obj, sub.Status.InstalledCSV, "removed")
+				if sub.Status.InstalledCSV != "" {
+					csv := &v1alpha1.ClusterServiceVersion{}
+					csv.SetNamespace(obj.GetNamespace())
+					csv.SetName(sub.Status.InstalledCSV)
+					if err := cl.Delete(context.TODO(), csv); err != nil && !k8serrors.IsNotFound(err) {
+						return errors.Wrapf(err, "failed to delete CSV '%s'", csv.GetName())
+					}
+				}
+			}
+			if err := cl.Delete(context.TODO(), obj); err != nil && !k8serrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to delete %s '%s'", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName())
+			}
+		}
+		term.Event(terminal.Event{
+			Type:       terminal.OperatorUninstall,
+			Message:    fmt.Sprintf("Removed operator installed with template '%s'", templatePath),
+			Attributes: attributes,
+		})
+	}
+	return nil
+}
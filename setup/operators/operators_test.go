@@ -1,18 +1,25 @@
 package operators
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/test"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -21,6 +28,7 @@ func TestEnsureOperatorsInstalled(t *testing.T) {
 	csvTimeout = time.Millisecond
 	scheme, err := configuration.NewScheme()
 	require.NoError(t, err)
+	term := terminal.New(func() io.Reader { return strings.NewReader("") }, func() io.Writer { return io.Discard }, false)
 
 	t.Run("success", func(t *testing.T) {
 		t.Run("operator not installed", func(t *testing.T) {
@@ -40,11 +48,22 @@ func TestEnsureOperatorsInstalled(t *testing.T) {
 				return cl.Client.Get(ctx, key, obj, opts...)
 			}
 
+			events := &bytes.Buffer{}
+			term := terminal.NewJSON(func() io.Reader { return strings.NewReader("") }, func() io.Writer { return events }, false)
+
 			// when
-			err = EnsureOperatorsInstalled(cl, scheme, []string{"installtemplates/kiali.yaml"})
+			err = EnsureOperatorsInstalled(term, cl, scheme, []string{"installtemplates/kiali.yaml"})
 
 			// then
 			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(events.String()), "\n")
+			require.Len(t, lines, 3)
+			for i, status := range []string{"started", "installing", "installed"} {
+				event := map[string]interface{}{}
+				require.NoError(t, json.Unmarshal([]byte(lines[i]), &event))
+				assert.Equal(t, "operator-install", event["event"])
+				assert.Equal(t, status, event["status"])
+			}
 		})
 	})
 
@@ -63,7 +82,7 @@ func TestEnsureOperatorsInstalled(t *testing.T) {
 			}
 
 			// when
-			err := EnsureOperatorsInstalled(cl, scheme, []string{"installtemplates/kiali.yaml"})
+			err := EnsureOperatorsInstalled(term, cl, scheme, []string{"installtemplates/kiali.yaml"})
 
 			// then
 			require.EqualError(t, err, "could not apply resource 'kiali-ossm' in namespace 'openshift-operators': unable to create resource of kind: Subscription, version: v1alpha1: Test client error")
@@ -83,7 +102,7 @@ func TestEnsureOperatorsInstalled(t *testing.T) {
 			}
 
 			// when
-			err := EnsureOperatorsInstalled(cl, scheme, []string{"installtemplates/kiali.yaml"})
+			err := EnsureOperatorsInstalled(term, cl, scheme, []string{"installtemplates/kiali.yaml"})
 
 			// then
 			require.EqualError(t, err, "failed to verify installation of operator with subscription 'kiali-ossm': could not find a Subscription with name 'kiali-ossm' in namespace 'openshift-operators' that meets the expected criteria: timed out waiting for the condition")
@@ -105,7 +124,7 @@ func TestEnsureOperatorsInstalled(t *testing.T) {
 			}
 
 			// when
-			err := EnsureOperatorsInstalled(cl, scheme, []string{"installtemplates/kiali.yaml"})
+			err := EnsureOperatorsInstalled(term, cl, scheme, []string{"installtemplates/kiali.yaml"})
 
 			// then
 			require.EqualError(t, err, "failed to find CSV 'kiali-operator.v1.24.7' with Phase 'Succeeded': could not find a CSV with name 'kiali-operator.v1.24.7' in namespace 'openshift-operators' that meets the expected criteria: timed out waiting for the condition")
@@ -129,7 +148,7 @@ func TestEnsureOperatorsInstalled(t *testing.T) {
 			}
 
 			// when
-			err = EnsureOperatorsInstalled(cl, scheme, []string{"installtemplates/kiali.yaml"})
+			err = EnsureOperatorsInstalled(term, cl, scheme, []string{"installtemplates/kiali.yaml"})
 
 			// then
 			require.EqualError(t, err, "failed to find CSV 'kiali-operator.v1.24.7' with Phase 'Succeeded': could not find a CSV with name 'kiali-operator.v1.24.7' in namespace 'openshift-operators' that meets the expected criteria: timed out waiting for the condition")
@@ -140,7 +159,7 @@ func TestEnsureOperatorsInstalled(t *testing.T) {
 			cl := test.NewFakeClient(t)
 
 			// when
-			err := EnsureOperatorsInstalled(cl, scheme, []string{"../test/installtemplates/badoperator.yaml"})
+			err := EnsureOperatorsInstalled(term, cl, scheme, []string{"../test/installtemplates/badoperator.yaml"})
 
 			// then
 			require.EqualError(t, err, "a subscription was not found in template file '../test/installtemplates/badoperator.yaml'")
@@ -148,6 +167,54 @@ func TestEnsureOperatorsInstalled(t *testing.T) {
 	})
 }
 
+func TestEnsureOperatorsUninstalled(t *testing.T) {
+	scheme, err := configuration.NewScheme()
+	require.NoError(t, err)
+
+	t.Run("operator installed", func(t *testing.T) {
+		// given
+		sub := &v1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "kiali-ossm",
+				Namespace: "openshift-operators",
+			},
+			Status: v1alpha1.SubscriptionStatus{
+				InstalledCSV: "kiali-operator.v1.24.7",
+			},
+		}
+		cl := test.NewFakeClient(t, sub, kialiCSV(v1alpha1.CSVPhaseSucceeded))
+		events := &bytes.Buffer{}
+		term := terminal.NewJSON(func() io.Reader { return strings.NewReader("") }, func() io.Writer { return events }, false)
+
+		// when
+		err := EnsureOperatorsUninstalled(term, cl, scheme, []string{"installtemplates/kiali.yaml"})
+
+		// then
+		require.NoError(t, err)
+		event := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(events.Bytes(), &event))
+		assert.Equal(t, "operator-uninstall", event["event"])
+		assert.Equal(t, "removed", event["status"])
+		assert.Equal(t, "kiali-operator.v1.24.7", event["csv"])
+		err = cl.Get(context.TODO(), types.NamespacedName{Name: "kiali-ossm", Namespace: "openshift-operators"}, &v1alpha1.Subscription{})
+		require.True(t, errors.IsNotFound(err))
+		err = cl.Get(context.TODO(), types.NamespacedName{Name: "kiali-operator.v1.24.7", Namespace: "openshift-operators"}, &v1alpha1.ClusterServiceVersion{})
+		require.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("operator not installed", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+		term := terminal.New(func() io.Reader { return strings.NewReader("") }, func() io.Writer { return io.Discard }, false)
+
+		// when
+		err := EnsureOperatorsUninstalled(term, cl, scheme, []string{"installtemplates/kiali.yaml"})
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
 func kialiCSV(phase v1alpha1.ClusterServiceVersionPhase) *v1alpha1.ClusterServiceVersion {
 	return &v1alpha1.ClusterServiceVersion{
 		ObjectMeta: metav1.ObjectMeta{
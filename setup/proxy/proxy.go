@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/regsvc"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// URL returns the URL of the API proxy, from its 'api' route in the given namespace
+func URL(ctx context.Context, cl client.Client, namespace string) (string, error) {
+	route := &routev1.Route{}
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "api"}, route); err != nil {
+		return "", errors.Wrapf(err, "unable to get the route of the API proxy in namespace '%s'", namespace)
+	}
+	return strings.TrimSuffix(fmt.Sprintf("https://%s/%s", route.Spec.Host, route.Spec.Path), "/"), nil
+}
+
+// Clients returns the clients of the users through the API proxy
+type Clients struct {
+	url    string
+	scheme *runtime.Scheme
+	// mapper is shared by the clients of all the users so that the API discovery is not run again for each user
+	mapper meta.RESTMapper
+}
+
+// NewClients returns the factory of the clients of the users through the API proxy at the given URL
+func NewClients(url string, s *runtime.Scheme, mapper meta.RESTMapper) *Clients {
+	return &Clients{
+		url:    url,
+		scheme: s,
+		mapper: mapper,
+	}
+}
+
+// For returns a client of the given user through the API proxy, authenticated with a token of the user signed by the e2e test key.
+// The round trips of the client are recorded in the given stats.
+func (c *Clients) For(username string, stats *Stats) (client.Client, error) {
+	token, err := regsvc.Token(username)
+	if err != nil {
+		return nil, err
+	}
+	config := &rest.Config{
+		Host:        c.url,
+		BearerToken: token,
+		// the route of a development cluster usually has a self-signed certificate
+		TLSClientConfig: rest.TLSClientConfig{Insecure: true},
+		// same as the client of the setup, to avoid client-side throttling
+		QPS:           100,
+		Burst:         100,
+		WrapTransport: stats.wrap,
+	}
+	cl, err := client.New(config, client.Options{Scheme: c.scheme, Mapper: c.mapper})
+	return cl, errors.Wrapf(err, "unable to create the API proxy client of user '%s'", username)
+}
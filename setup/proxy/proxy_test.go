@@ -0,0 +1,128 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/templates"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestURL(t *testing.T) {
+	// given
+	// the fake client uses the default scheme
+	require.NoError(t, routev1.Install(scheme.Scheme))
+	cl := commontest.NewFakeClient(t, &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "toolchain-host-operator", Name: "api"},
+		Spec:       routev1.RouteSpec{Host: "api-toolchain-host-operator.apps.example.com"},
+	})
+
+	// when
+	url, err := URL(context.TODO(), cl, "toolchain-host-operator")
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "https://api-toolchain-host-operator.apps.example.com", url)
+}
+
+func TestClients(t *testing.T) {
+	// given
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"config","namespace":"zippy-0001-dev"}}`)
+	}))
+	defer server.Close()
+	s, err := configuration.NewScheme()
+	require.NoError(t, err)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	stats := NewStats()
+
+	// when
+	cl, err := NewClients(server.URL, s, mapper).For("zippy-0001", stats)
+	require.NoError(t, err)
+	err = cl.Get(context.TODO(), types.NamespacedName{Namespace: "zippy-0001-dev", Name: "config"}, &corev1.ConfigMap{})
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(authorization, "Bearer "))
+	titles := map[string]float64{}
+	for _, r := range stats.Results("default") {
+		titles[r.Title()] = r.Value
+	}
+	assert.Contains(t, titles, "Average API Proxy Round Trip (s)")
+	assert.Contains(t, titles, `Max API Proxy Round Trip {resource="configmaps"} (s)`)
+}
+
+func TestResourceOf(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/workspaces/zippy-0001/apis/apps/v1/namespaces/zippy-0001-dev/deployments/nginx": "deployments",
+		"/api/v1/namespaces/zippy-0001-dev/configmaps":                                    "configmaps",
+		"/apis/rbac.authorization.k8s.io/v1/clusterroles/view":                            "clusterroles",
+		"/api/v1/namespaces/zippy-0001-dev":                                               "namespaces",
+		"/version":                                                                        "other",
+	} {
+		assert.Equal(t, expected, resourceOf(path), path)
+	}
+}
+
+func TestRecordRejections(t *testing.T) {
+	// given
+	stats := NewStats()
+	cl := commontest.NewFakeClient(t)
+	cl.MockCreate = func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+		switch obj.GetName() {
+		case "view":
+			return k8serrors.NewForbidden(schema.GroupResource{Resource: "roles"}, obj.GetName(), fmt.Errorf("user cannot create roles"))
+		case "privileged", "privileged-2":
+			return k8serrors.NewBadRequest(`admission webhook "users.pods.webhook.sandbox" denied the request: privileged pods are not allowed`)
+		default:
+			return fmt.Errorf("timeout")
+		}
+	}
+	objs := []client.Object{
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "view"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "privileged"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "privileged-2"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}},
+	}
+	for _, obj := range objs {
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		require.NoError(t, err)
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+	}
+	err := templates.ApplyObjectsConcurrently(cl, objs, templates.NamespaceModifier("zippy-0001-dev"))
+	require.Error(t, err)
+
+	// when
+	stats.RecordRejections(err)
+
+	// then
+	rejections := map[string]float64{}
+	for _, r := range stats.Results("default") {
+		if r.Name == "API Proxy Rejections" {
+			rejections[r.Labels["kind"]+" "+r.Labels["reason"]] = r.Value
+		}
+	}
+	assert.Equal(t, map[string]float64{"Role rbac": 1, "Pod webhook": 2}, rejections)
+}
@@ -0,0 +1,173 @@
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/templates"
+
+	multierror "github.com/hashicorp/go-multierror"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// the reasons why the objects applied through the API proxy are rejected
+const (
+	rbacRejection    = "rbac"
+	webhookRejection = "webhook"
+)
+
+// Stats collects the latency of the round trips through the API proxy by resource, and the objects rejected by RBAC or by
+// an admission webhook by kind. It is safe for concurrent use.
+type Stats struct {
+	mu         sync.Mutex
+	roundTrips *latency.Distribution
+	resources  map[string]*latency.Distribution
+	rejections map[rejection]int
+}
+
+type rejection struct {
+	kind   string
+	reason string
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		roundTrips: &latency.Distribution{},
+		resources:  map[string]*latency.Distribution{},
+		rejections: map[rejection]int{},
+	}
+}
+
+// wrap returns a round tripper that records the latency of each request sent through the given round tripper
+func (s *Stats) wrap(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := rt.RoundTrip(req)
+		s.recordRoundTrip(resourceOf(req.URL.Path), time.Since(start))
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func (s *Stats) recordRoundTrip(resource string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.resources[resource]; !ok {
+		s.resources[resource] = &latency.Distribution{}
+	}
+	s.resources[resource].Add(d)
+	s.roundTrips.Add(d)
+}
+
+// resourceOf returns the resource of the given path of the Kubernetes API, eg. 'deployments' for
+// '/workspaces/zippy-0001/apis/apps/v1/namespaces/zippy-0001-dev/deployments/nginx'
+func resourceOf(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var rest []string
+	for i, p := range parts {
+		if p == "api" && len(parts) > i+2 {
+			rest = parts[i+2:]
+			break
+		}
+		if p == "apis" && len(parts) > i+3 {
+			rest = parts[i+3:]
+			break
+		}
+	}
+	switch {
+	case len(rest) >= 3 && rest[0] == "namespaces":
+		return rest[2]
+	case len(rest) >= 1:
+		return rest[0]
+	default:
+		return "other"
+	}
+}
+
+// RecordRejections records the objects of the given error of templates.ApplyObjectsConcurrently that were rejected by RBAC
+// or by an admission webhook
+func (s *Stats) RecordRejections(err error) {
+	if err == nil {
+		return
+	}
+	errs := []error{err}
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		errs = merr.Errors
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range errs {
+		var applyErr *templates.ApplyError
+		if !errors.As(e, &applyErr) {
+			continue
+		}
+		if reason := rejectionReason(e); reason != "" {
+			s.rejections[rejection{kind: applyErr.Kind, reason: reason}]++
+		}
+	}
+}
+
+// rejectionReason returns why the object was rejected, or an empty string if the error is not a rejection
+func rejectionReason(err error) string {
+	if strings.Contains(err.Error(), "admission webhook") {
+		return webhookRejection
+	}
+	if k8serrors.IsForbidden(err) {
+		return rbacRejection
+	}
+	return ""
+}
+
+// Results returns the latency of the round trips through the API proxy, overall and by resource, and the number of objects
+// rejected by kind and reason, for the given phase
+func (s *Stats) Results(phase string) []results.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	const roundTrip = "API Proxy Round Trip"
+	proxyResults := []results.Result{latency.Result(roundTrip, results.Average, phase, s.roundTrips.Mean())}
+	proxyResults = append(proxyResults, s.roundTrips.Summary(roundTrip, phase)...)
+	resources := make([]string, 0, len(s.resources))
+	for r := range s.resources {
+		resources = append(resources, r)
+	}
+	sort.Strings(resources)
+	for _, r := range resources {
+		d := s.resources[r]
+		resourceResults := append([]results.Result{latency.Result(roundTrip, results.Average, phase, d.Mean())}, d.Summary(roundTrip, phase)...)
+		for i := range resourceResults {
+			resourceResults[i].Labels = map[string]string{"resource": r}
+		}
+		proxyResults = append(proxyResults, resourceResults...)
+	}
+	rejections := make([]rejection, 0, len(s.rejections))
+	for r := range s.rejections {
+		rejections = append(rejections, r)
+	}
+	sort.Slice(rejections, func(i, j int) bool {
+		if rejections[i].kind != rejections[j].kind {
+			return rejections[i].kind < rejections[j].kind
+		}
+		return rejections[i].reason < rejections[j].reason
+	})
+	for _, r := range rejections {
+		proxyResults = append(proxyResults, results.Result{
+			Name:   "API Proxy Rejections",
+			Value:  float64(s.rejections[r]),
+			Phase:  phase,
+			Labels: map[string]string{"kind": r.kind, "reason": r.reason},
+		})
+	}
+	return proxyResults
+}
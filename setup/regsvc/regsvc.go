@@ -0,0 +1,188 @@
+package regsvc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+	authsupport "github.com/codeready-toolchain/toolchain-e2e/testsupport/auth"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8swait "k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// signupPath is the path of the signup endpoint of the registration service
+const signupPath = "/api/v1/signup"
+
+// URL returns the URL of the route of the registration service in the given namespace
+func URL(ctx context.Context, cl client.Client, namespace string) (string, error) {
+	route := &routev1.Route{}
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "registration-service"}, route); err != nil {
+		return "", errors.Wrapf(err, "unable to get the route of the registration service in namespace '%s'", namespace)
+	}
+	if route.Spec.TLS != nil {
+		return "https://" + route.Spec.Host, nil
+	}
+	return "http://" + route.Spec.Host, nil
+}
+
+// Client signs up users through the REST API of the registration service, with tokens signed by the e2e test key. The latency
+// and the status of each request are recorded in its stats.
+type Client struct {
+	url   string
+	http  *http.Client
+	Stats *Stats
+}
+
+// New returns a client of the registration service at the given URL
+func New(url string) *Client {
+	return &Client{
+		url: strings.TrimSuffix(url, "/"),
+		// same as the client of the e2e tests, the route of a development cluster usually has a self-signed certificate
+		http: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true, // nolint:gosec
+				},
+			},
+		},
+		Stats: NewStats(),
+	}
+}
+
+// Signup is the state of a signup as returned by the registration service
+type Signup struct {
+	Name              string       `json:"name"`
+	CompliantUsername string       `json:"compliantUsername"`
+	Status            SignupStatus `json:"status"`
+}
+
+// SignupStatus is the status of a signup as returned by the registration service
+type SignupStatus struct {
+	Ready   bool   `json:"ready"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+var (
+	tokensMu sync.Mutex
+	// tokens are the tokens of the users by username. Each token has a random subject, so a user keeps the same token, and thus
+	// the same identity, across the retries of its signup and through the API proxy.
+	tokens = map[string]string{}
+)
+
+// Token returns a token of the given user, signed by the e2e test key. The token is generated on the first call for the user
+// and the same token is returned afterwards.
+func Token(username string) (string, error) {
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+	if token, ok := tokens[username]; ok {
+		return token, nil
+	}
+	_, token, err := authsupport.NewToken(
+		authsupport.WithEmail(fmt.Sprintf("%s@test.com", username)),
+		authsupport.WithPreferredUsername(username))
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to generate the token of user '%s'", username)
+	}
+	tokens[username] = token
+	return token, nil
+}
+
+// Signup posts a signup request with the given token. A user that already signed up is not an error.
+func (c *Client) Signup(ctx context.Context, token string) error {
+	status, body, err := c.do(ctx, http.MethodPost, token)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusAccepted && status != http.StatusConflict {
+		return fmt.Errorf("unexpected status %d of the signup request: %s", status, body)
+	}
+	return nil
+}
+
+// Get returns the signup of the given token, it is nil if the signup does not exist yet
+func (c *Client) Get(ctx context.Context, token string) (*Signup, error) {
+	status, body, err := c.do(ctx, http.MethodGet, token)
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case http.StatusNotFound:
+		return nil, nil
+	case http.StatusOK:
+		signup := &Signup{}
+		if err := json.Unmarshal(body, signup); err != nil {
+			return nil, errors.Wrapf(err, "unable to decode the signup: %s", body)
+		}
+		return signup, nil
+	default:
+		return nil, fmt.Errorf("unexpected status %d of the signup: %s", status, body)
+	}
+}
+
+// WaitUntilCreated polls the signup of the given token until it exists, or until the context is done. It returns the signup,
+// whose name is the name of the UserSignup created by the registration service.
+func (c *Client) WaitUntilCreated(ctx context.Context, token string) (*Signup, error) {
+	var created *Signup
+	err := k8swait.PollWithContext(ctx, configuration.DefaultRetryInterval, configuration.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		signup, err := c.Get(ctx, token)
+		if err != nil {
+			return false, err
+		}
+		created = signup
+		return signup != nil, nil
+	})
+	return created, errors.Wrap(err, "signup was not created")
+}
+
+// WaitUntilReady polls the signup of the given token until it is ready, or until the context is done
+func (c *Client) WaitUntilReady(ctx context.Context, token string) error {
+	var last *Signup
+	err := k8swait.PollWithContext(ctx, configuration.DefaultRetryInterval, configuration.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		signup, err := c.Get(ctx, token)
+		if err != nil {
+			return false, err
+		}
+		last = signup
+		return signup != nil && signup.Status.Ready, nil
+	})
+	if err != nil && last != nil {
+		return errors.Wrapf(err, "signup is not ready yet, reason: '%s', message: '%s'", last.Status.Reason, last.Status.Message)
+	}
+	return errors.Wrap(err, "signup is not ready yet")
+}
+
+// do sends a request to the signup endpoint and returns the status and the body of the response
+func (c *Client) do(ctx context.Context, method, token string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+signupPath, nil)
+	if err != nil {
+		return 0, nil, errors.Wrapf(err, "unable to create the %s %s request", method, signupPath)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.Stats.record(method, 0, time.Since(start))
+		return 0, nil, errors.Wrapf(err, "%s %s failed", method, signupPath)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	c.Stats.record(method, resp.StatusCode, time.Since(start))
+	if err != nil {
+		return 0, nil, errors.Wrapf(err, "unable to read the response of %s %s", method, signupPath)
+	}
+	return resp.StatusCode, body, nil
+}
@@ -0,0 +1,205 @@
+package regsvc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestURL(t *testing.T) {
+	// given
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "toolchain-host-operator", Name: "registration-service"},
+		Spec: routev1.RouteSpec{
+			Host: "registration-service-toolchain-host-operator.apps.example.com",
+			TLS:  &routev1.TLSConfig{},
+		},
+	}
+	// the fake client uses the default scheme
+	require.NoError(t, routev1.Install(scheme.Scheme))
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, route)
+
+		// when
+		url, err := URL(context.TODO(), cl, "toolchain-host-operator")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "https://registration-service-toolchain-host-operator.apps.example.com", url)
+	})
+
+	t.Run("route not found", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t)
+
+		// when
+		_, err := URL(context.TODO(), cl, "toolchain-host-operator")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to get the route of the registration service in namespace 'toolchain-host-operator'")
+	})
+}
+
+func TestToken(t *testing.T) {
+	// when
+	token, err := Token("zippy-0002")
+
+	// then
+	require.NoError(t, err)
+	t.Run("same token for the same user", func(t *testing.T) {
+		// when
+		again, err := Token("zippy-0002")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, token, again)
+	})
+
+	t.Run("other token for another user", func(t *testing.T) {
+		// when
+		other, err := Token("zippy-0003")
+
+		// then
+		require.NoError(t, err)
+		assert.NotEqual(t, token, other)
+	})
+}
+
+func TestClient(t *testing.T) {
+	// given
+	configuration.DefaultRetryInterval = 10 * time.Millisecond
+	configuration.DefaultTimeout = time.Second
+	token, err := Token("zippy-0001")
+	require.NoError(t, err)
+
+	// newServer returns a registration service whose signup becomes ready after the given number of status requests
+	newServer := func(t *testing.T, postStatus, pendingGets int) *httptest.Server {
+		var mu sync.Mutex
+		gets := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/signup" || r.Header.Get("Authorization") != "Bearer "+token {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.Method == http.MethodPost {
+				w.WriteHeader(postStatus)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			gets++
+			switch {
+			case gets == 1:
+				w.WriteHeader(http.StatusNotFound)
+			case gets <= pendingGets:
+				w.Write([]byte(`{"name":"zippy-0001","status":{"ready":false,"reason":"Provisioning"}}`)) // nolint:errcheck
+			default:
+				w.Write([]byte(`{"name":"zippy-0001","compliantUsername":"zippy-0001","status":{"ready":true,"reason":"Provisioned"}}`)) // nolint:errcheck
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		server := newServer(t, http.StatusAccepted, 3)
+		c := New(server.URL + "/")
+
+		// when
+		err := c.Signup(context.TODO(), token)
+		require.NoError(t, err)
+		signup, err := c.WaitUntilCreated(context.TODO(), token)
+		require.NoError(t, err)
+		err = c.WaitUntilReady(context.TODO(), token)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "zippy-0001", signup.Name)
+		rs := c.Stats.Results("signups")
+		titles := []string{}
+		for _, r := range rs {
+			titles = append(titles, r.Title())
+		}
+		assert.Contains(t, titles, "Average Registration Service POST /api/v1/signup (s)")
+		assert.Contains(t, titles, "Registration Service GET /api/v1/signup p99 (s)")
+		responses := map[string]float64{}
+		for _, r := range rs {
+			if r.Name == "Registration Service Responses" {
+				responses[r.Labels["method"]+" "+r.Labels["status"]] = r.Value
+			}
+		}
+		assert.Equal(t, map[string]float64{"POST 202": 1, "GET 404": 1, "GET 200": 3}, responses)
+	})
+
+	t.Run("already signed up", func(t *testing.T) {
+		// given
+		server := newServer(t, http.StatusConflict, 0)
+		c := New(server.URL)
+
+		// when
+		err := c.Signup(context.TODO(), token)
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+
+		t.Run("signup rejected", func(t *testing.T) {
+			// given
+			server := newServer(t, http.StatusForbidden, 0)
+			c := New(server.URL)
+
+			// when
+			err := c.Signup(context.TODO(), token)
+
+			// then
+			require.EqualError(t, err, "unexpected status 403 of the signup request: ")
+		})
+
+		t.Run("signup not ready", func(t *testing.T) {
+			// given
+			server := newServer(t, http.StatusAccepted, 1000)
+			c := New(server.URL)
+
+			// when
+			err := c.WaitUntilReady(context.TODO(), token)
+
+			// then
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), "signup is not ready yet, reason: 'Provisioning'"), err.Error())
+		})
+
+		t.Run("registration service unavailable", func(t *testing.T) {
+			// given
+			server := newServer(t, http.StatusAccepted, 0)
+			server.Close()
+			c := New(server.URL)
+
+			// when
+			err := c.Signup(context.TODO(), token)
+
+			// then
+			require.Error(t, err)
+			rs := c.Stats.Results("signups")
+			assert.Equal(t, map[string]string{"method": "POST", "status": "none"}, rs[len(rs)-1].Labels)
+		})
+	})
+}
@@ -0,0 +1,85 @@
+package regsvc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+)
+
+// Stats collects the latency of the requests sent to the registration service by method, and the number of responses by
+// method and status. It is safe for concurrent use.
+type Stats struct {
+	mu        sync.Mutex
+	latencies map[string]*latency.Distribution
+	responses map[response]int
+}
+
+// response is the method of a request and the status of its response, the status is 0 when no response was received
+type response struct {
+	method string
+	status int
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		latencies: map[string]*latency.Distribution{},
+		responses: map[response]int{},
+	}
+}
+
+func (s *Stats) record(method string, status int, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.latencies[method]; !ok {
+		s.latencies[method] = &latency.Distribution{}
+	}
+	s.latencies[method].Add(d)
+	s.responses[response{method: method, status: status}]++
+}
+
+// Results returns the latency of the requests of each method and the number of responses of each status, for the given phase
+func (s *Stats) Results(phase string) []results.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	methods := make([]string, 0, len(s.latencies))
+	for method := range s.latencies {
+		methods = append(methods, method)
+	}
+	// the signup requests come first
+	sort.Sort(sort.Reverse(sort.StringSlice(methods)))
+	regsvcResults := []results.Result{}
+	for _, method := range methods {
+		name := fmt.Sprintf("Registration Service %s %s", method, signupPath)
+		d := s.latencies[method]
+		regsvcResults = append(regsvcResults, latency.Result(name, results.Average, phase, d.Mean()))
+		regsvcResults = append(regsvcResults, d.Summary(name, phase)...)
+	}
+	responses := make([]response, 0, len(s.responses))
+	for r := range s.responses {
+		responses = append(responses, r)
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].method != responses[j].method {
+			return responses[i].method > responses[j].method
+		}
+		return responses[i].status < responses[j].status
+	})
+	for _, r := range responses {
+		status := strconv.Itoa(r.status)
+		if r.status == 0 {
+			status = "none"
+		}
+		regsvcResults = append(regsvcResults, results.Result{
+			Name:   "Registration Service Responses",
+			Value:  float64(s.responses[r]),
+			Phase:  phase,
+			Labels: map[string]string{"method": r.method, "status": status},
+		})
+	}
+	return regsvcResults
+}
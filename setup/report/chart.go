@@ -0,0 +1,152 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics"
+)
+
+const (
+	chartWidth   = 860
+	chartHeight  = 260
+	marginLeft   = 70
+	marginRight  = 20
+	marginTop    = 10
+	marginBottom = 30
+	yTicks       = 5
+	xTicks       = 6
+)
+
+// palette are the colors of the lines of a chart, they are reused when a chart has more lines
+var palette = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"}
+
+// phaseColors are the colors of the spans of the phases, they are reused when the run has more phases
+var phaseColors = []string{"#4e79a7", "#59a14f", "#edc948", "#b07aa1", "#76b7b2"}
+
+// chart is a line chart of a metrics series, as an inline SVG image
+type chart struct {
+	Title  string
+	SVG    template.HTML
+	Legend []legendItem
+}
+
+type legendItem struct {
+	Label string
+	Color template.CSS
+}
+
+// timeRange is the period shown by all the charts
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// newTimeRange returns the period covered by the series and the spans of the phases
+func newTimeRange(series []metrics.Series, spans []latency.Span) timeRange {
+	r := timeRange{}
+	include := func(t time.Time) {
+		if r.start.IsZero() || t.Before(r.start) {
+			r.start = t
+		}
+		if r.end.IsZero() || t.After(r.end) {
+			r.end = t
+		}
+	}
+	for _, s := range series {
+		for _, l := range s.Lines {
+			for _, p := range l.Points {
+				include(p.Timestamp)
+			}
+		}
+	}
+	for _, s := range spans {
+		include(s.Start)
+		include(s.End)
+	}
+	if !r.end.After(r.start) {
+		r.end = r.start.Add(time.Minute)
+	}
+	return r
+}
+
+func (r timeRange) x(t time.Time) float64 {
+	return marginLeft + float64(t.Sub(r.start))/float64(r.end.Sub(r.start))*(chartWidth-marginLeft-marginRight)
+}
+
+// newChart draws the lines of the series with the spans of the phases in the background
+func newChart(s metrics.Series, spans []latency.Span, r timeRange) chart {
+	title := s.Name
+	if s.Unit != "" {
+		title = fmt.Sprintf("%s (%s)", s.Name, s.Unit)
+	}
+	c := chart{Title: title}
+
+	maxValue := 0.0
+	for _, l := range s.Lines {
+		for _, p := range l.Points {
+			maxValue = math.Max(maxValue, p.Value)
+		}
+	}
+	top := niceCeiling(maxValue)
+	plotHeight := float64(chartHeight - marginTop - marginBottom)
+	y := func(v float64) float64 {
+		return marginTop + plotHeight - v/top*plotHeight
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" font-family="sans-serif" font-size="11">`, chartWidth, chartHeight, chartWidth, chartHeight)
+	for i, span := range spans {
+		x1, x2 := r.x(span.Start), r.x(span.End)
+		fmt.Fprintf(b, `<rect x="%.1f" y="%d" width="%.1f" height="%.1f" fill="%s" fill-opacity="0.12"/>`, x1, marginTop, math.Max(x2-x1, 1), plotHeight, phaseColors[i%len(phaseColors)])
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%.1f" stroke="%s" stroke-dasharray="4,3"/>`, x1, marginTop, x1, marginTop+plotHeight, phaseColors[i%len(phaseColors)])
+		// the phases overlap since the users go through them one after the other, their labels are stacked
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" fill="%s">%s</text>`, x1+3, marginTop+12*(i+1), phaseColors[i%len(phaseColors)], html.EscapeString(span.Phase))
+	}
+	for i := 0; i <= yTicks; i++ {
+		v := top / yTicks * float64(i)
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`, marginLeft, y(v), chartWidth-marginRight, y(v))
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`, marginLeft-6, y(v)+4, strconv.FormatFloat(v, 'g', 4, 64))
+	}
+	for i := 0; i <= xTicks; i++ {
+		t := r.start.Add(time.Duration(float64(r.end.Sub(r.start)) / xTicks * float64(i)))
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`, r.x(t), chartHeight-10, t.Local().Format("15:04"))
+	}
+	for i, l := range s.Lines {
+		color := palette[i%len(palette)]
+		c.Legend = append(c.Legend, legendItem{Label: l.Label, Color: template.CSS(color)}) // nolint:gosec // the colors are constants
+		if len(l.Points) == 1 {
+			fmt.Fprintf(b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, r.x(l.Points[0].Timestamp), y(l.Points[0].Value), color)
+			continue
+		}
+		points := make([]string, 0, len(l.Points))
+		for _, p := range l.Points {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", r.x(p.Timestamp), y(p.Value)))
+		}
+		fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`, strings.Join(points, " "), color)
+	}
+	fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#333"/>`, marginLeft, marginTop+plotHeight, chartWidth-marginRight, marginTop+plotHeight)
+	b.WriteString(`</svg>`)
+	c.SVG = template.HTML(b.String()) // nolint:gosec // the labels are escaped
+	return c
+}
+
+// niceCeiling returns the value rounded up to 1, 2, 2.5 or 5 times a power of 10, so that the ticks are round numbers
+func niceCeiling(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, step := range []float64{1, 2, 2.5, 5, 10} {
+		if v <= step*magnitude {
+			return step * magnitude
+		}
+	}
+	return 10 * magnitude
+}
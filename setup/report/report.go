@@ -0,0 +1,157 @@
+package report
+
+import (
+	"html/template"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+
+	"github.com/pkg/errors"
+)
+
+// Data is what the report of a run presents
+type Data struct {
+	Title   string
+	Results []results.Result
+	Series  []metrics.Series
+	Spans   []latency.Span
+	Flags   map[string]string
+}
+
+type phase struct {
+	Name     string
+	Start    string
+	End      string
+	Duration string
+	Timings  []results.Result
+}
+
+type flag struct {
+	Name  string
+	Value string
+}
+
+type page struct {
+	Title       string
+	GeneratedAt string
+	Phases      []phase
+	Charts      []chart
+	Results     []results.Result
+	Flags       []flag
+}
+
+// Write saves the report as a single HTML file without external assets, so that it can be attached to an issue
+func Write(path string, d Data) error {
+	p := page{
+		Title:       d.Title,
+		GeneratedAt: time.Now().Format(time.RFC1123),
+		Results:     d.Results,
+	}
+	for _, s := range d.Spans {
+		ph := phase{
+			Name:     s.Phase,
+			Start:    s.Start.Local().Format("15:04:05"),
+			End:      s.End.Local().Format("15:04:05"),
+			Duration: s.End.Sub(s.Start).Round(time.Second).String(),
+		}
+		for _, r := range d.Results {
+			if r.Phase == s.Phase && r.Unit == results.Seconds {
+				ph.Timings = append(ph.Timings, r)
+			}
+		}
+		p.Phases = append(p.Phases, ph)
+	}
+	r := newTimeRange(d.Series, d.Spans)
+	for _, s := range d.Series {
+		if len(s.Lines) == 0 {
+			continue
+		}
+		p.Charts = append(p.Charts, newChart(s, d.Spans, r))
+	}
+	for name, value := range d.Flags {
+		p.Flags = append(p.Flags, flag{Name: name, Value: value})
+	}
+	sort.Slice(p.Flags, func(i, j int) bool {
+		return p.Flags[i].Name < p.Flags[j].Name
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create report file '%s'", path)
+	}
+	defer f.Close()
+	return errors.Wrapf(reportTemplate.Execute(f, p), "unable to write report file '%s'", path)
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.3em; margin-top: 2em; border-bottom: 1px solid #ccc; }
+h3 { font-size: 1.05em; }
+table { border-collapse: collapse; margin: 0.5em 0 1em 0; }
+th, td { border: 1px solid #ddd; padding: 4px 10px; text-align: left; font-size: 0.9em; }
+th { background: #f4f4f4; }
+td.value { text-align: right; font-family: monospace; }
+.legend span { display: inline-block; margin-right: 1.2em; font-size: 0.85em; }
+.legend i { display: inline-block; width: 12px; height: 3px; margin-right: 4px; vertical-align: middle; }
+.chart { margin-bottom: 1.5em; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>Generated on {{ .GeneratedAt }}</p>
+
+<h2>Phases</h2>
+{{- range .Phases }}
+<h3>{{ .Name }}</h3>
+<p>From {{ .Start }} to {{ .End }} ({{ .Duration }})</p>
+{{- if .Timings }}
+<table>
+<tr><th>Timing</th><th>Value</th></tr>
+{{- range .Timings }}
+<tr><td>{{ .Title }}</td><td class="value">{{ .FormattedValue }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- else }}
+<p>No phase was recorded.</p>
+{{- end }}
+
+<h2>Metrics</h2>
+{{- range .Charts }}
+<div class="chart">
+<h3>{{ .Title }}</h3>
+{{ .SVG }}
+<div class="legend">{{ range .Legend }}<span><i style="background: {{ .Color }}"></i>{{ .Label }}</span>{{ end }}</div>
+</div>
+{{- else }}
+<p>No metrics were gathered.</p>
+{{- end }}
+
+<h2>Results</h2>
+<table>
+<tr><th>Item</th><th>Value</th></tr>
+{{- range .Results }}
+<tr><td>{{ .Title }}</td><td class="value">{{ .FormattedValue }}</td></tr>
+{{- end }}
+</table>
+
+<h2>Flags</h2>
+<table>
+<tr><th>Flag</th><th>Value</th></tr>
+{{- range .Flags }}
+<tr><td>--{{ .Name }}</td><td>{{ .Value }}</td></tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
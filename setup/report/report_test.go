@@ -0,0 +1,79 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	// given
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	data := Data{
+		Title: "Setup run 2023-10-01_12:00:00-nightly",
+		Results: []results.Result{
+			{Name: "Number of Users", Value: 2000},
+			{Name: "Time Per User - signups", Unit: results.Seconds, Aggregate: results.P99, Value: 2.5, Phase: "signups", Precision: 2},
+			{Name: "Time Per User - default", Unit: results.Seconds, Aggregate: results.Max, Value: 7.25, Phase: "default", Precision: 2},
+		},
+		Series: []metrics.Series{
+			{Name: "host-operator Memory Usage", Unit: results.Megabytes, Lines: []metrics.Line{
+				{Label: "host-operator Memory Usage", Points: []metrics.Point{{Timestamp: start, Value: 100}, {Timestamp: start.Add(5 * time.Minute), Value: 150}}},
+			}},
+			{Name: "etcd Instance Memory Usage", Unit: results.Megabytes, Lines: []metrics.Line{
+				{Label: `{pod="etcd-1"}`, Points: []metrics.Point{{Timestamp: start, Value: 400}}},
+				{Label: `{pod="etcd-2"}`, Points: []metrics.Point{{Timestamp: start, Value: 450}}},
+			}},
+			{Name: "no datapoints"},
+		},
+		Spans: []latency.Span{
+			{Phase: "signups", Start: start, End: start.Add(3 * time.Minute)},
+			{Phase: "default", Start: start.Add(time.Minute), End: start.Add(4 * time.Minute)},
+		},
+		Flags: map[string]string{"users": "2000", "template": "[<custom>.yaml]"},
+	}
+	path := filepath.Join(t.TempDir(), "report.html")
+
+	// when
+	err := Write(path, data)
+
+	// then
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	report := string(content)
+	assert.Contains(t, report, "<title>Setup run 2023-10-01_12:00:00-nightly</title>")
+	// phases with their timings
+	assert.Contains(t, report, "<h3>signups</h3>")
+	assert.Contains(t, report, "(3m0s)")
+	assert.Contains(t, report, "<tr><td>Time Per User - signups p99 (s)</td><td class=\"value\">2.50</td></tr>")
+	// charts with the phases and a legend per line, the series without datapoints have no chart
+	assert.Equal(t, 2, strings.Count(report, "<svg "))
+	assert.Contains(t, report, "<h3>host-operator Memory Usage (MB)</h3>")
+	assert.Contains(t, report, `<polyline points="70.0,`)
+	assert.Contains(t, report, `>default</text>`)
+	assert.Contains(t, report, `{pod=&#34;etcd-2&#34;}`)
+	assert.NotContains(t, report, "no datapoints")
+	// results and flags
+	assert.Contains(t, report, "<tr><td>Number of Users</td><td class=\"value\">2000</td></tr>")
+	assert.Contains(t, report, "<tr><td>--template</td><td>[&lt;custom&gt;.yaml]</td></tr>")
+	assert.Less(t, strings.Index(report, "--template"), strings.Index(report, "--users"))
+	// no external assets
+	assert.NotContains(t, report, "src=")
+	assert.NotContains(t, report, "<link")
+}
+
+func TestNiceCeiling(t *testing.T) {
+	for value, expected := range map[float64]float64{0: 1, 0.3: 0.5, 1: 1, 1.2: 2, 2.2: 2.5, 4: 5, 7: 10, 180: 200, 6000: 10000} {
+		assert.InDelta(t, expected, niceCeiling(value), 0.0001, "value %v", value)
+	}
+}
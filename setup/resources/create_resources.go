@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"context"
 	"fmt"
 
 	ctemplate "github.com/codeready-toolchain/toolchain-common/pkg/template"
@@ -9,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 
 	templatev1 "github.com/openshift/api/template/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -17,7 +19,13 @@ const userNSParam = "CURRENT_USER_NAMESPACE"
 
 var tmpls map[string]*templatev1.Template = make(map[string]*templatev1.Template)
 
-func CreateUserResourcesFromTemplateFiles(cl runtimeclient.Client, s *runtime.Scheme, username string, templatePaths []string) error {
+func CreateUserResourcesFromTemplateFiles(ctx context.Context, cl runtimeclient.Client, s *runtime.Scheme, username string, templatePaths []string) error {
+	return CreateUserResourcesAs(ctx, cl, cl, s, username, templatePaths)
+}
+
+// CreateUserResourcesAs waits for the Space of the user with the given client and applies the objects of the templates in the
+// user's namespace with the client of the user, eg. a client authenticated as the user through the API proxy
+func CreateUserResourcesAs(ctx context.Context, cl, userCl runtimeclient.Client, s *runtime.Scheme, username string, templatePaths []string) error {
 	userNS := fmt.Sprintf("%s-dev", username)
 	combinedObjsToProcess := []runtimeclient.Object{}
 	for _, templatePath := range templatePaths {
@@ -31,7 +39,7 @@ func CreateUserResourcesFromTemplateFiles(cl runtimeclient.Client, s *runtime.Sc
 		tmpl := tmpls[templatePath]
 
 		// waiting for each namespace here prevents some edge cases where the setup job can progress beyond the usersignup job and fail with a timeout
-		if err := wait.ForSpace(cl, username); err != nil {
+		if err := wait.ForSpace(ctx, cl, username); err != nil {
 			return err
 		}
 		processor := ctemplate.NewProcessor(s)
@@ -48,5 +56,36 @@ func CreateUserResourcesFromTemplateFiles(cl runtimeclient.Client, s *runtime.Sc
 		return fmt.Errorf("no objects found in templates %v", templatePaths)
 	}
 
-	return templates.ApplyObjectsConcurrently(cl, combinedObjsToProcess, templates.NamespaceModifier(userNS))
+	return templates.ApplyObjectsConcurrently(userCl, combinedObjsToProcess, templates.NamespaceModifier(userNS))
+}
+
+// UserResourcesExist returns true if all the objects of the given templates exist in the user's namespace.
+// It is used to confirm that a user recorded as completed in a checkpoint journal really has its resources applied.
+func UserResourcesExist(cl runtimeclient.Client, s *runtime.Scheme, username string, templatePaths []string) (bool, error) {
+	userNS := fmt.Sprintf("%s-dev", username)
+	for _, templatePath := range templatePaths {
+		if _, ok := tmpls[templatePath]; !ok {
+			var err error
+			if tmpls[templatePath], err = templates.GetTemplateFromFile(templatePath); err != nil {
+				return false, errors.Wrapf(err, "invalid template file: '%s'", templatePath)
+			}
+		}
+		processor := ctemplate.NewProcessor(s)
+		objs, err := processor.Process(tmpls[templatePath].DeepCopy(), map[string]string{
+			userNSParam: userNS,
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, obj := range objs {
+			obj.SetNamespace(userNS)
+			if err := cl.Get(context.TODO(), runtimeclient.ObjectKeyFromObject(obj), obj); err != nil {
+				if k8serrors.IsNotFound(err) {
+					return false, nil
+				}
+				return false, err
+			}
+		}
+	}
+	return true, nil
 }
@@ -0,0 +1,108 @@
+package results
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Load reads the results file at the given path, written in the JSON or in the CSV format
+func Load(path string) ([]Result, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read results file '%s'", path)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		loaded := []Result{}
+		if err := json.Unmarshal(content, &loaded); err != nil {
+			return nil, errors.Wrapf(err, "invalid results file '%s'", path)
+		}
+		for i, r := range loaded {
+			loaded[i].Precision = defaultPrecision(r.Value)
+		}
+		return loaded, nil
+	}
+	rows, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid results file '%s'", path)
+	}
+	loaded := []Result{}
+	for i, row := range rows {
+		if len(row) != 2 {
+			return nil, fmt.Errorf("invalid results file '%s': line %d must have 2 columns", path, i+1)
+		}
+		if i == 0 && row[0] == "Item" {
+			continue
+		}
+		value, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			// some results of the older runs are not numbers
+			continue
+		}
+		r := ParseTitle(row[0])
+		r.Value = value
+		if dot := strings.Index(row[1], "."); dot >= 0 {
+			r.Precision = len(row[1]) - dot - 1
+		}
+		loaded = append(loaded, r)
+	}
+	return loaded, nil
+}
+
+// defaultPrecision returns 2 decimals for the values that are not whole numbers
+func defaultPrecision(value float64) int {
+	if value == float64(int64(value)) {
+		return 0
+	}
+	return 2
+}
+
+var (
+	unitSuffix   = regexp.MustCompile(`^(.*) \(([^()]+)\)$`)
+	labelsSuffix = regexp.MustCompile(`^(.*) \{(.*)\}$`)
+	labelPair    = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)=("(?:[^"\\]|\\.)*")`)
+)
+
+// ParseTitle returns the result whose title is the given one, it is the reverse of Result.Title. The titles of the
+// results of the older runs, eg. 'Provisioning Time Per User max (s)', are parsed as well.
+func ParseTitle(title string) Result {
+	r := Result{Name: title}
+	if m := unitSuffix.FindStringSubmatch(r.Name); m != nil {
+		r.Name = m[1]
+		r.Unit = Unit(m[2])
+	}
+	if m := labelsSuffix.FindStringSubmatch(r.Name); m != nil {
+		labels := map[string]string{}
+		for _, pair := range labelPair.FindAllStringSubmatch(m[2], -1) {
+			if value, err := strconv.Unquote(pair[2]); err == nil {
+				labels[pair[1]] = value
+			}
+		}
+		if len(labels) > 0 {
+			r.Name = m[1]
+			r.Labels = labels
+		}
+	}
+	for _, a := range []Aggregate{Min, P50, P90, P95, P99, Max} {
+		if strings.HasSuffix(r.Name, " "+string(a)) {
+			r.Name = strings.TrimSuffix(r.Name, " "+string(a))
+			r.Aggregate = a
+			return r
+		}
+	}
+	for prefix, a := range map[string]Aggregate{"Average ": Average, "Max ": Max, "Total ": Total} {
+		if strings.HasPrefix(r.Name, prefix) {
+			r.Name = strings.TrimPrefix(r.Name, prefix)
+			r.Aggregate = a
+			return r
+		}
+	}
+	return r
+}
@@ -2,42 +2,210 @@ package results
 
 import (
 	"encoding/csv"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	cfg "github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
 )
 
+// Unit is the unit of the value of a result
+type Unit string
+
+const (
+	NoUnit             Unit = ""
+	Seconds            Unit = "s"
+	Minutes            Unit = "m"
+	Megabytes          Unit = "MB"
+	MegabytesPerSecond Unit = "MB/s"
+	MegabytesPerHour   Unit = "MB/h"
+	Percent            Unit = "%"
+	UsersPerSecond     Unit = "users/s"
+	UsersPerMinute     Unit = "users/m"
+	SpacesPerMinute    Unit = "spaces/m"
+)
+
+// Aggregate is how the value of a result was computed from the measurements
+type Aggregate string
+
+const (
+	NoAggregate Aggregate = ""
+	Average     Aggregate = "avg"
+	Max         Aggregate = "max"
+	Min         Aggregate = "min"
+	Total       Aggregate = "total"
+	P50         Aggregate = "p50"
+	P90         Aggregate = "p90"
+	P95         Aggregate = "p95"
+	P99         Aggregate = "p99"
+)
+
+// Verdict is whether a result is within the limits of the performance budget of the run
+type Verdict string
+
+const (
+	NoVerdict Verdict = ""
+	Pass      Verdict = "pass"
+	Fail      Verdict = "fail"
+)
+
+// Result is a single value of the results of a run, eg. the average memory usage of the host operator
+type Result struct {
+	Name      string            `json:"name"`
+	Unit      Unit              `json:"unit,omitempty"`
+	Aggregate Aggregate         `json:"aggregate,omitempty"`
+	Value     float64           `json:"value"`
+	Phase     string            `json:"phase,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Verdict is set for the results that check the value of another result against the budget of the run
+	Verdict Verdict `json:"verdict,omitempty"`
+	// Precision is the number of decimals of the value in the terminal and the CSV file
+	Precision int `json:"-"`
+}
+
+// Title returns the name of the result with its aggregate, labels and unit, eg. 'Average host-operator Memory Usage (MB)'
+func (r Result) Title() string {
+	title := r.Name
+	switch r.Aggregate {
+	case NoAggregate:
+	case Average:
+		title = "Average " + title
+	case Max:
+		title = "Max " + title
+	case Total:
+		title = "Total " + title
+	default:
+		title = fmt.Sprintf("%s %s", title, r.Aggregate)
+	}
+	if len(r.Labels) > 0 {
+		title = fmt.Sprintf("%s %s", title, formatLabels(r.Labels))
+	}
+	if r.Unit != NoUnit {
+		title = fmt.Sprintf("%s (%s)", title, r.Unit)
+	}
+	return title
+}
+
+// FormattedValue returns the value with the precision of the result, or the verdict of the result
+func (r Result) FormattedValue() string {
+	if r.Verdict != NoVerdict {
+		return string(r.Verdict)
+	}
+	return strconv.FormatFloat(r.Value, 'f', r.Precision, 64)
+}
+
+// formatLabels returns the labels in the Prometheus format, eg. {instance="etcd-1", pod="etcd-1"}
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, name := range sortedNames(labels) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Format is a format in which the results are written to a file
+type Format string
+
+const (
+	CSV         Format = "csv"
+	JSON        Format = "json"
+	JUnit       Format = "junit"
+	OpenMetrics Format = "openmetrics"
+)
+
+var formats = []Format{CSV, JSON, JUnit, OpenMetrics}
+
+// ParseFormats returns the formats of the given names
+func ParseFormats(names []string) ([]Format, error) {
+	parsed := make([]Format, 0, len(names))
+	for _, name := range names {
+		f := Format(strings.ToLower(strings.TrimSpace(name)))
+		if !contains(formats, f) {
+			return nil, fmt.Errorf("unknown output format '%s', must be one of csv, json, junit or openmetrics", name)
+		}
+		if !contains(parsed, f) {
+			parsed = append(parsed, f)
+		}
+	}
+	return parsed, nil
+}
+
+func contains(formats []Format, f Format) bool {
+	for _, format := range formats {
+		if format == f {
+			return true
+		}
+	}
+	return false
+}
+
 type Writer interface {
-	Write([][]string) error
+	Write([]Result) error
 	Close() error
 }
 
 type Results struct {
 	stdOutWriter Writer
-	csvWriter    Writer
-	results      [][]string
+	fileWriters  []Writer
+	paths        []string
+	results      []Result
 	term         terminal.Terminal
 }
 
-func New(term terminal.Terminal) *Results {
-
-	csvFile, err := os.Create(cfg.ResultsFilepath())
-	if err != nil {
-		term.Infof("failed creating file: %s", err)
-		os.Exit(1)
+// New returns the results of a run, they are written to the terminal and to a file for each of the given formats.
+// The results are written to a CSV file if no format is given.
+func New(term terminal.Terminal, formats ...Format) *Results {
+	if len(formats) == 0 {
+		formats = []Format{CSV}
 	}
-
-	return &Results{
-		results:      make([][]string, 0),
-		csvWriter:    csvWriter{csvFile},
+	r := &Results{
+		results:      make([]Result, 0),
 		stdOutWriter: terminalWriter{term},
 		term:         term,
 	}
+	for _, format := range formats {
+		path := resultsFilepath(format)
+		f, err := os.Create(path)
+		if err != nil {
+			term.Infof("failed creating file: %s", err)
+			os.Exit(1)
+		}
+		r.fileWriters = append(r.fileWriters, newFileWriter(format, f))
+		r.paths = append(r.paths, path)
+	}
+	return r
+}
+
+func resultsFilepath(format Format) string {
+	switch format {
+	case JSON:
+		return cfg.ResultsJSONFilepath()
+	case JUnit:
+		return cfg.ResultsJUnitFilepath()
+	case OpenMetrics:
+		return cfg.ResultsOpenMetricsFilepath()
+	default:
+		return cfg.ResultsFilepath()
+	}
+}
+
+func newFileWriter(format Format, f *os.File) Writer {
+	switch format {
+	case JSON:
+		return jsonWriter{f}
+	case JUnit:
+		return junitWriter{f}
+	case OpenMetrics:
+		return openMetricsWriter{f}
+	default:
+		return csvWriter{f}
+	}
 }
 
 func (r *Results) writeResults() error {
-	for _, w := range []Writer{r.stdOutWriter, r.csvWriter} {
+	for _, w := range append([]Writer{r.stdOutWriter}, r.fileWriters...) {
 		if err := w.Write(r.results); err != nil {
 			return err
 		}
@@ -45,17 +213,26 @@ func (r *Results) writeResults() error {
 	return nil
 }
 
-func (r *Results) AddResults(results [][]string) {
+func (r *Results) AddResults(results []Result) {
 	r.results = append(r.results, results...)
 }
 
+// All returns a copy of the results added so far
+func (r *Results) All() []Result {
+	return append([]Result{}, r.results...)
+}
+
 type csvWriter struct {
 	f *os.File
 }
 
-func (w csvWriter) Write(results [][]string) error {
+func (w csvWriter) Write(results []Result) error {
+	rows := [][]string{{"Item", "Value"}}
+	for _, result := range results {
+		rows = append(rows, []string{result.Title(), result.FormattedValue()})
+	}
 	writer := csv.NewWriter(w.f)
-	return writer.WriteAll(results)
+	return writer.WriteAll(rows)
 }
 
 func (w csvWriter) Close() error {
@@ -66,9 +243,9 @@ type terminalWriter struct {
 	t terminal.Terminal
 }
 
-func (w terminalWriter) Write(results [][]string) error {
+func (w terminalWriter) Write(results []Result) error {
 	for _, result := range results {
-		w.t.Infof("%s: %s", result[0], result[1])
+		w.t.Infof("%s: %s", result.Title(), result.FormattedValue())
 	}
 	return nil
 }
@@ -77,11 +254,11 @@ func (w terminalWriter) Close() error {
 	return nil
 }
 
-// OutputResults outputs the aggregated results to the terminal and a csv file
+// OutputResults outputs the aggregated results to the terminal and the results files
 func (r *Results) OutputResults() {
 	if err := r.writeResults(); err != nil {
 		r.term.Fatalf(err, "failed to write results")
 	}
 
-	r.term.Infof("\nResults file: " + cfg.ResultsFilepath())
+	r.term.Infof("\nResults file: " + strings.Join(r.paths, ", "))
 }
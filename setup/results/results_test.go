@@ -0,0 +1,277 @@
+package results
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testResults = []Result{
+	{Name: "Number of Users", Value: 2000},
+	{Name: "Provisioning Time Per User", Unit: Seconds, Aggregate: P50, Value: 1.234, Phase: "signups", Precision: 2},
+	{Name: "Provisioning Time Per User", Unit: Seconds, Aggregate: Max, Value: 12.5, Phase: "signups", Precision: 2},
+	{Name: "etcd Instance Memory Usage", Unit: Megabytes, Aggregate: Average, Value: 512.345, Labels: map[string]string{"pod": "etcd-1", "instance": "10.0.0.1:9979"}, Precision: 2},
+	{Name: "Running Time", Unit: Minutes, Aggregate: Total, Value: 42.5, Precision: 6},
+}
+
+func TestTitle(t *testing.T) {
+	titles := [][]string{}
+	for _, r := range testResults {
+		titles = append(titles, []string{r.Title(), r.FormattedValue()})
+	}
+	assert.Equal(t, [][]string{
+		{"Number of Users", "2000"},
+		{"Provisioning Time Per User p50 (s)", "1.23"},
+		{"Max Provisioning Time Per User (s)", "12.50"},
+		{`Average etcd Instance Memory Usage {instance="10.0.0.1:9979", pod="etcd-1"} (MB)`, "512.35"},
+		{"Total Running Time (m)", "42.500000"},
+	}, titles)
+}
+
+func TestParseFormats(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// when
+		formats, err := ParseFormats([]string{"csv", "JUnit", " openmetrics", "csv"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Format{CSV, JUnit, OpenMetrics}, formats)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		// when
+		_, err := ParseFormats([]string{"csv", "xml"})
+
+		// then
+		require.EqualError(t, err, "unknown output format 'xml', must be one of csv, json, junit or openmetrics")
+	})
+}
+
+func TestWriters(t *testing.T) {
+	write := func(t *testing.T, format Format) string {
+		return writeFile(t, format, testResults)
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		// when
+		content := write(t, CSV)
+
+		// then
+		assert.Equal(t, `Item,Value
+Number of Users,2000
+Provisioning Time Per User p50 (s),1.23
+Max Provisioning Time Per User (s),12.50
+"Average etcd Instance Memory Usage {instance=""10.0.0.1:9979"", pod=""etcd-1""} (MB)",512.35
+Total Running Time (m),42.500000
+`, content)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// when
+		content := write(t, JSON)
+
+		// then
+		written := []Result{}
+		require.NoError(t, json.Unmarshal([]byte(content), &written))
+		require.Len(t, written, len(testResults))
+		assert.Equal(t, Result{Name: "Provisioning Time Per User", Unit: Seconds, Aggregate: P50, Value: 1.234, Phase: "signups"}, written[1])
+		assert.Equal(t, map[string]string{"pod": "etcd-1", "instance": "10.0.0.1:9979"}, written[3].Labels)
+	})
+
+	t.Run("junit", func(t *testing.T) {
+		// when
+		content := write(t, JUnit)
+
+		// then
+		assert.Contains(t, content, `<testsuite name="setup" tests="5" failures="0">`)
+		assert.Contains(t, content, `<testcase name="Provisioning Time Per User p50 (s)" classname="setup.signups">
+    <properties>
+      <property name="unit" value="s"></property>
+      <property name="aggregate" value="p50"></property>
+    </properties>
+    <system-out>1.23</system-out>
+  </testcase>`)
+		assert.Contains(t, content, `<testcase name="Number of Users" classname="setup">
+    <system-out>2000</system-out>
+  </testcase>`)
+	})
+
+	t.Run("openmetrics", func(t *testing.T) {
+		// when
+		content := write(t, OpenMetrics)
+
+		// then
+		assert.Equal(t, `# TYPE toolchain_setup_number_of_users gauge
+toolchain_setup_number_of_users 2000
+# TYPE toolchain_setup_provisioning_time_per_user_seconds gauge
+# UNIT toolchain_setup_provisioning_time_per_user_seconds seconds
+toolchain_setup_provisioning_time_per_user_seconds{aggregate="p50",phase="signups"} 1.234
+toolchain_setup_provisioning_time_per_user_seconds{aggregate="max",phase="signups"} 12.5
+# TYPE toolchain_setup_etcd_instance_memory_usage_megabytes gauge
+# UNIT toolchain_setup_etcd_instance_memory_usage_megabytes megabytes
+toolchain_setup_etcd_instance_memory_usage_megabytes{aggregate="avg",instance="10.0.0.1:9979",pod="etcd-1"} 512.345
+# TYPE toolchain_setup_running_time_minutes gauge
+# UNIT toolchain_setup_running_time_minutes minutes
+toolchain_setup_running_time_minutes{aggregate="total"} 42.5
+# EOF
+`, content)
+	})
+
+	t.Run("verdicts", func(t *testing.T) {
+		// given
+		verdicts := []Result{
+			{Name: "Budget: Max etcd Instance Memory Usage (MB) <= 6000", Value: 6512.5, Precision: 2, Verdict: Fail},
+			{Name: "Budget: Total Running Time (m) <= 60", Value: 42.5, Precision: 6, Verdict: Pass},
+		}
+
+		t.Run("csv", func(t *testing.T) {
+			// when
+			content := writeFile(t, CSV, verdicts)
+
+			// then
+			assert.Equal(t, `Item,Value
+Budget: Max etcd Instance Memory Usage (MB) <= 6000,fail
+Budget: Total Running Time (m) <= 60,pass
+`, content)
+		})
+
+		t.Run("junit", func(t *testing.T) {
+			// when
+			content := writeFile(t, JUnit, verdicts)
+
+			// then
+			assert.Contains(t, content, `<testsuite name="setup" tests="2" failures="1">`)
+			assert.Contains(t, content, `<testcase name="Budget: Max etcd Instance Memory Usage (MB) &lt;= 6000" classname="setup">
+    <failure message="Budget: Max etcd Instance Memory Usage (MB) &lt;= 6000 failed with 6512.50"></failure>
+    <system-out>fail</system-out>
+  </testcase>`)
+		})
+
+		t.Run("openmetrics", func(t *testing.T) {
+			// when
+			content := writeFile(t, OpenMetrics, verdicts)
+
+			// then
+			assert.Equal(t, `# TYPE toolchain_setup_budget_passed gauge
+toolchain_setup_budget_passed{budget="Budget: Max etcd Instance Memory Usage (MB) <= 6000"} 0
+toolchain_setup_budget_passed{budget="Budget: Total Running Time (m) <= 60"} 1
+# EOF
+`, content)
+		})
+	})
+}
+
+func TestMetricName(t *testing.T) {
+	for expected, r := range map[string]Result{
+		"toolchain_setup_number_of_users":                                      {Name: "Number of Users"},
+		"toolchain_setup_provisioning_time_per_user_seconds":                   {Name: "Provisioning Time Per User", Unit: Seconds},
+		"toolchain_setup_etcd_instance_memory_usage_megabytes":                 {Name: "etcd Instance Memory Usage", Unit: Megabytes},
+		"toolchain_setup_host_operator_memory_usage_growth_megabytes_per_hour": {Name: "Host Operator Memory Usage Growth", Unit: MegabytesPerHour},
+		"toolchain_setup_tier_rollout_throughput_spaces_per_minute":            {Name: "Tier Rollout Throughput", Unit: SpacesPerMinute},
+		"toolchain_setup_budget_passed":                                        {Name: "Budget: Total Running Time (m) <= 60", Verdict: Pass},
+	} {
+		t.Run(expected, func(t *testing.T) {
+			assert.Equal(t, expected, r.MetricName())
+		})
+	}
+}
+
+func writeFile(t *testing.T, format Format, results []Result) string {
+	path := filepath.Join(t.TempDir(), "results")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w := newFileWriter(format, f)
+	require.NoError(t, w.Write(results))
+	require.NoError(t, w.Close())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestParseTitle(t *testing.T) {
+	t.Run("titles of the results", func(t *testing.T) {
+		for _, r := range testResults {
+			// when
+			parsed := ParseTitle(r.Title())
+
+			// then
+			assert.Equal(t, Result{Name: r.Name, Unit: r.Unit, Aggregate: r.Aggregate, Labels: r.Labels}, parsed)
+		}
+	})
+
+	t.Run("titles of older runs", func(t *testing.T) {
+		// when
+		parsed := ParseTitle("Time Per User - default max (s)")
+
+		// then
+		assert.Equal(t, Result{Name: "Time Per User - default", Unit: Seconds, Aggregate: Max}, parsed)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	write := func(t *testing.T, name, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		// given
+		path := write(t, "results.csv", `Item,Value
+Number of Users,2000
+Provisioning Time Per User p50 (s),1.23
+Cluster Version,4.12.3-x86
+"Average etcd Instance Memory Usage {instance=""10.0.0.1:9979"", pod=""etcd-1""} (MB)",512.35
+`)
+
+		// when
+		loaded, err := Load(path)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Result{
+			{Name: "Number of Users", Value: 2000},
+			{Name: "Provisioning Time Per User", Unit: Seconds, Aggregate: P50, Value: 1.23, Precision: 2},
+			{Name: "etcd Instance Memory Usage", Unit: Megabytes, Aggregate: Average, Value: 512.35, Labels: map[string]string{"pod": "etcd-1", "instance": "10.0.0.1:9979"}, Precision: 2},
+		}, loaded)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		// given
+		path := write(t, "results.json", `[{"name": "Number of Users", "value": 2000}, {"name": "Provisioning Time Per User", "unit": "s", "aggregate": "p50", "value": 1.234, "phase": "signups"}]`)
+
+		// when
+		loaded, err := Load(path)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []Result{
+			{Name: "Number of Users", Value: 2000},
+			{Name: "Provisioning Time Per User", Unit: Seconds, Aggregate: P50, Value: 1.234, Phase: "signups", Precision: 2},
+		}, loaded)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		// given
+		path := write(t, "results.csv", "Item,Value\nNumber of Users\n")
+
+		// when
+		_, err := Load(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid results file")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		// when
+		_, err := Load("not-found.csv")
+
+		// then
+		require.EqualError(t, err, "unable to read results file 'not-found.csv': open not-found.csv: no such file or directory")
+	})
+}
@@ -0,0 +1,232 @@
+package results
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonWriter struct {
+	f *os.File
+}
+
+func (w jsonWriter) Write(results []Result) error {
+	encoder := json.NewEncoder(w.f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+func (w jsonWriter) Close() error {
+	return w.f.Close()
+}
+
+// junitWriter writes each result as a test case of a single test suite, with the value of the result as its output,
+// its phase in the class name and its unit, aggregate and labels as properties
+type junitWriter struct {
+	f *os.File
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitFailure    `xml:"failure,omitempty"`
+	SystemOut  string           `xml:"system-out"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func (w junitWriter) Write(results []Result) error {
+	suite := junitTestSuite{
+		Name:  "setup",
+		Tests: len(results),
+	}
+	for _, r := range results {
+		className := "setup"
+		if r.Phase != "" {
+			className = fmt.Sprintf("setup.%s", r.Phase)
+		}
+		tc := junitTestCase{
+			Name:      r.Title(),
+			ClassName: className,
+			SystemOut: r.FormattedValue(),
+		}
+		properties := []junitProperty{}
+		if r.Unit != NoUnit {
+			properties = append(properties, junitProperty{Name: "unit", Value: string(r.Unit)})
+		}
+		if r.Aggregate != NoAggregate {
+			properties = append(properties, junitProperty{Name: "aggregate", Value: string(r.Aggregate)})
+		}
+		for _, name := range sortedNames(r.Labels) {
+			properties = append(properties, junitProperty{Name: name, Value: r.Labels[name]})
+		}
+		if len(properties) > 0 {
+			tc.Properties = &junitProperties{Properties: properties}
+		}
+		if r.Verdict == Fail {
+			tc.Failure = &junitFailure{Message: fmt.Sprintf("%s failed with %s", r.Name, strconv.FormatFloat(r.Value, 'f', r.Precision, 64))}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	if _, err := w.f.WriteString(xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w.f)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := w.f.WriteString("\n")
+	return err
+}
+
+func (w junitWriter) Close() error {
+	return w.f.Close()
+}
+
+// openMetricsWriter writes the results in the OpenMetrics text format, as one gauge per result name and unit with
+// the aggregate, the phase and the labels of the results as labels
+type openMetricsWriter struct {
+	f *os.File
+}
+
+const metricPrefix = "toolchain_setup_"
+
+var unitSuffixes = map[Unit]string{
+	Seconds:            "seconds",
+	Minutes:            "minutes",
+	Megabytes:          "megabytes",
+	MegabytesPerSecond: "megabytes_per_second",
+	MegabytesPerHour:   "megabytes_per_hour",
+	Percent:            "percent",
+	UsersPerSecond:     "users_per_second",
+	UsersPerMinute:     "users_per_minute",
+	SpacesPerMinute:    "spaces_per_minute",
+}
+
+var invalidMetricNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// budgetMetricName is the gauge of the verdicts of the budget, 1 when the result passed and 0 when it failed
+const budgetMetricName = metricPrefix + "budget_passed"
+
+// MetricName returns the name of the OpenMetrics gauge of the result, eg. toolchain_setup_host_operator_memory_usage_megabytes
+func (r Result) MetricName() string {
+	if r.Verdict != NoVerdict {
+		return budgetMetricName
+	}
+	name := strings.Trim(invalidMetricNameChars.ReplaceAllString(strings.ToLower(r.Name), "_"), "_")
+	if suffix, found := unitSuffixes[r.Unit]; found {
+		name = fmt.Sprintf("%s_%s", name, suffix)
+	}
+	return metricPrefix + name
+}
+
+func (w openMetricsWriter) Write(results []Result) error {
+	// the samples of a metric must be grouped together
+	names := []string{}
+	byName := map[string][]Result{}
+	for _, r := range results {
+		name := r.MetricName()
+		if _, found := byName[name]; !found {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], r)
+	}
+	b := &strings.Builder{}
+	for _, name := range names {
+		fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+		if suffix, found := unitSuffixes[byName[name][0].Unit]; found {
+			fmt.Fprintf(b, "# UNIT %s %s\n", name, suffix)
+		}
+		for _, r := range byName[name] {
+			if r.Verdict != NoVerdict {
+				fmt.Fprintf(b, "%s{budget=\"%s\"} %d\n", name, escapeLabelValue(r.Name), btoi(r.Verdict == Pass))
+				continue
+			}
+			fmt.Fprintf(b, "%s%s %s\n", name, openMetricsLabels(r), openMetricsValue(r.Value))
+		}
+	}
+	b.WriteString("# EOF\n")
+	_, err := w.f.WriteString(b.String())
+	return err
+}
+
+func (w openMetricsWriter) Close() error {
+	return w.f.Close()
+}
+
+func openMetricsLabels(r Result) string {
+	pairs := []string{}
+	if r.Aggregate != NoAggregate {
+		pairs = append(pairs, fmt.Sprintf(`aggregate="%s"`, escapeLabelValue(string(r.Aggregate))))
+	}
+	if r.Phase != "" {
+		pairs = append(pairs, fmt.Sprintf(`phase="%s"`, escapeLabelValue(r.Phase)))
+	}
+	for _, name := range sortedNames(r.Labels) {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escapeLabelValue(r.Labels[name])))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func openMetricsValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func btoi(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func sortedNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
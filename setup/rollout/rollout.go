@@ -0,0 +1,176 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/condition"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
+	"github.com/codeready-toolchain/toolchain-e2e/testsupport/tiers"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Bump creates a new revision of each TierTemplate of the given tier, with the same content, and updates the tier with their
+// refs, so that the host operator rolls the new revisions out to all the Spaces of the tier. It returns the updated tier.
+func Bump(ctx context.Context, cl client.Client, namespace, tierName, revision string) (*toolchainv1alpha1.NSTemplateTier, error) {
+	tier := &toolchainv1alpha1.NSTemplateTier{}
+	if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: tierName}, tier); err != nil {
+		return nil, errors.Wrapf(err, "unable to get tier '%s'", tierName)
+	}
+	bump := func(templateRef string) (string, error) {
+		origTierTemplate := &toolchainv1alpha1.TierTemplate{}
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: templateRef}, origTierTemplate); err != nil {
+			return "", errors.Wrapf(err, "unable to get tier template '%s'", templateRef)
+		}
+		// the name of the TierTemplates of the host operator is made of the tier, the type and the revision of the template
+		name := fmt.Sprintf("%s-%s-%s", tierName, origTierTemplate.Spec.Type, revision)
+		tierTemplate := tiers.CopyTierTemplate(origTierTemplate, namespace, name, tierName)
+		tierTemplate.Spec.Revision = revision
+		if err := cl.Create(ctx, tierTemplate); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return "", errors.Wrapf(err, "unable to create tier template '%s'", name)
+		}
+		return name, nil
+	}
+
+	var err error
+	if tier.Spec.ClusterResources != nil {
+		if tier.Spec.ClusterResources.TemplateRef, err = bump(tier.Spec.ClusterResources.TemplateRef); err != nil {
+			return nil, err
+		}
+	}
+	for i := range tier.Spec.Namespaces {
+		if tier.Spec.Namespaces[i].TemplateRef, err = bump(tier.Spec.Namespaces[i].TemplateRef); err != nil {
+			return nil, err
+		}
+	}
+	for role, spaceRole := range tier.Spec.SpaceRoles {
+		if spaceRole.TemplateRef, err = bump(spaceRole.TemplateRef); err != nil {
+			return nil, err
+		}
+		tier.Spec.SpaceRoles[role] = spaceRole
+	}
+	if err := cl.Update(ctx, tier); err != nil {
+		return nil, errors.Wrapf(err, "unable to update tier '%s'", tierName)
+	}
+	return tier, nil
+}
+
+// Tracker waits until the Spaces of a tier have converged to its revisions, on the host cluster (the Space) and on the
+// member clusters (the NSTemplateSet)
+type Tracker struct {
+	Client                   client.Client
+	HostOperatorNamespace    string
+	MemberOperatorNamespaces []string
+	// Interval is the time between two checks of the Spaces
+	Interval time.Duration
+	// Converged is called once for each Space that has converged, with the time it took since the tier was updated
+	Converged func(username string, d time.Duration)
+}
+
+// Wait checks the Spaces of the given users until all of them have converged to the revisions of the given tier, until the
+// timeout, until stop is closed or until the context is done. The tier was updated at the given time. It returns the statistics
+// of the rollout, whose pending Spaces are the ones that have not converged.
+func (t *Tracker) Wait(ctx context.Context, stop <-chan struct{}, tier *toolchainv1alpha1.NSTemplateTier, usernames []string, updated time.Time, timeout time.Duration) (*Stats, error) {
+	tierHash, err := hash.ComputeHashForNSTemplateTier(tier)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to compute the hash of tier '%s'", tier.Name)
+	}
+	pending := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		pending[username] = true
+	}
+	stats := newStats(updated, len(pending))
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+	for len(pending) > 0 {
+		converged, err := t.converged(ctx, tier.Name, tierHash, pending)
+		if err != nil {
+			return stats, err
+		}
+		now := time.Now()
+		for _, username := range converged {
+			delete(pending, username)
+			stats.record(now)
+			if t.Converged != nil {
+				t.Converged(username, now.Sub(updated))
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return stats, fmt.Errorf("%d space(s) did not converge to the revisions of tier '%s' within %s", len(pending), tier.Name, timeout)
+		case <-stop:
+			return stats, nil
+		case <-ctx.Done():
+			return stats, nil
+		}
+	}
+	return stats, nil
+}
+
+// SpacesInTier returns the given users whose Space is in the given tier, the other users (eg. the users who failed to sign up)
+// are not part of the rollout
+func (t *Tracker) SpacesInTier(ctx context.Context, tierName string, usernames []string) ([]string, error) {
+	spaces := &toolchainv1alpha1.SpaceList{}
+	if err := t.Client.List(ctx, spaces, client.InNamespace(t.HostOperatorNamespace)); err != nil {
+		return nil, errors.Wrap(err, "unable to list the spaces")
+	}
+	inTier := map[string]bool{}
+	for _, space := range spaces.Items {
+		if space.Spec.TierName == tierName {
+			inTier[space.Name] = true
+		}
+	}
+	var spacesInTier []string
+	for _, username := range usernames {
+		if inTier[username] {
+			spacesInTier = append(spacesInTier, username)
+		}
+	}
+	return spacesInTier, nil
+}
+
+// converged returns the pending users whose Space has the hash of the tier and is provisioned, and whose NSTemplateSet has
+// the template refs of the tier and is ready
+func (t *Tracker) converged(ctx context.Context, tierName, tierHash string, pending map[string]bool) ([]string, error) {
+	spaces := &toolchainv1alpha1.SpaceList{}
+	if err := t.Client.List(ctx, spaces, client.InNamespace(t.HostOperatorNamespace)); err != nil {
+		return nil, errors.Wrap(err, "unable to list the spaces")
+	}
+	hostConverged := map[string]bool{}
+	for _, space := range spaces.Items {
+		if pending[space.Name] &&
+			space.Labels[hash.TemplateTierHashLabelKey(tierName)] == tierHash &&
+			condition.IsTrueWithReason(space.Status.Conditions, toolchainv1alpha1.ConditionReady, toolchainv1alpha1.SpaceProvisionedReason) {
+			hostConverged[space.Name] = true
+		}
+	}
+	var converged []string
+	for _, ns := range t.MemberOperatorNamespaces {
+		nsTemplateSets := &toolchainv1alpha1.NSTemplateSetList{}
+		if err := t.Client.List(ctx, nsTemplateSets, client.InNamespace(ns)); err != nil {
+			return nil, errors.Wrapf(err, "unable to list the nstemplatesets in namespace '%s'", ns)
+		}
+		for _, nsTemplateSet := range nsTemplateSets.Items {
+			if !hostConverged[nsTemplateSet.Name] || !condition.IsTrue(nsTemplateSet.Status.Conditions, toolchainv1alpha1.ConditionReady) {
+				continue
+			}
+			if nsTemplateSetHash, err := hash.ComputeHashForNSTemplateSetSpec(nsTemplateSet.Spec); err == nil && nsTemplateSetHash == tierHash {
+				converged = append(converged, nsTemplateSet.Name)
+			}
+		}
+	}
+	return converged, nil
+}
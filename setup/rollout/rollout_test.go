@@ -0,0 +1,223 @@
+package rollout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	hostNS    = "toolchain-host-operator"
+	member1NS = "toolchain-member-operator"
+	member2NS = "toolchain-member2-operator"
+)
+
+func TestBump(t *testing.T) {
+	// given
+	tier, tierTemplates := base1ns()
+	cl := commontest.NewFakeClient(t, append(tierTemplates, tier)...)
+
+	// when
+	bumped, err := Bump(context.TODO(), cl, hostNS, "base1ns", "65301e4e")
+
+	// then
+	require.NoError(t, err)
+	expected := toolchainv1alpha1.NSTemplateTierSpec{
+		ClusterResources: &toolchainv1alpha1.NSTemplateTierClusterResources{TemplateRef: "base1ns-clusterresources-65301e4e"},
+		Namespaces:       []toolchainv1alpha1.NSTemplateTierNamespace{{TemplateRef: "base1ns-dev-65301e4e"}},
+		SpaceRoles:       map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{"admin": {TemplateRef: "base1ns-admin-65301e4e"}},
+	}
+	assert.Equal(t, expected, bumped.Spec)
+	actual := &toolchainv1alpha1.NSTemplateTier{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostNS, Name: "base1ns"}, actual))
+	assert.Equal(t, expected, actual.Spec)
+	tierTemplate := &toolchainv1alpha1.TierTemplate{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostNS, Name: "base1ns-dev-65301e4e"}, tierTemplate))
+	assert.Equal(t, "base1ns", tierTemplate.Spec.TierName)
+	assert.Equal(t, "dev", tierTemplate.Spec.Type)
+	assert.Equal(t, "65301e4e", tierTemplate.Spec.Revision)
+
+	t.Run("unknown tier", func(t *testing.T) {
+		// when
+		_, err := Bump(context.TODO(), cl, hostNS, "unknown", "65301e4e")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to get tier 'unknown'")
+	})
+}
+
+func TestTracker(t *testing.T) {
+	// given
+	tier, _ := base1ns()
+	tierHash, err := hash.ComputeHashForNSTemplateTier(tier)
+	require.NoError(t, err)
+	ready := []toolchainv1alpha1.Condition{{Type: toolchainv1alpha1.ConditionReady, Status: corev1.ConditionTrue, Reason: toolchainv1alpha1.SpaceProvisionedReason}}
+	updating := []toolchainv1alpha1.Condition{{Type: toolchainv1alpha1.ConditionReady, Status: corev1.ConditionFalse, Reason: toolchainv1alpha1.SpaceUpdatingReason}}
+	space := func(username, tierName, spaceHash string, conditions []toolchainv1alpha1.Condition) *toolchainv1alpha1.Space {
+		return &toolchainv1alpha1.Space{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: hostNS,
+				Name:      username,
+				Labels:    map[string]string{hash.TemplateTierHashLabelKey(tierName): spaceHash},
+			},
+			Spec:   toolchainv1alpha1.SpaceSpec{TierName: tierName},
+			Status: toolchainv1alpha1.SpaceStatus{Conditions: conditions},
+		}
+	}
+	nsTemplateSet := func(namespace, username string, spec toolchainv1alpha1.NSTemplateTierSpec, conditions []toolchainv1alpha1.Condition) *toolchainv1alpha1.NSTemplateSet {
+		return &toolchainv1alpha1.NSTemplateSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: username},
+			Spec: toolchainv1alpha1.NSTemplateSetSpec{
+				TierName:         "base1ns",
+				ClusterResources: &toolchainv1alpha1.NSTemplateSetClusterResources{TemplateRef: spec.ClusterResources.TemplateRef},
+				Namespaces:       []toolchainv1alpha1.NSTemplateSetNamespace{{TemplateRef: spec.Namespaces[0].TemplateRef}},
+			},
+			Status: toolchainv1alpha1.NSTemplateSetStatus{Conditions: conditions},
+		}
+	}
+	previous := toolchainv1alpha1.NSTemplateTierSpec{
+		ClusterResources: &toolchainv1alpha1.NSTemplateTierClusterResources{TemplateRef: "base1ns-clusterresources-previous"},
+		Namespaces:       []toolchainv1alpha1.NSTemplateTierNamespace{{TemplateRef: "base1ns-dev-previous"}},
+	}
+	tracker := func(objs ...runtime.Object) (*Tracker, map[string]time.Duration) {
+		converged := map[string]time.Duration{}
+		return &Tracker{
+			Client:                   commontest.NewFakeClient(t, objs...),
+			HostOperatorNamespace:    hostNS,
+			MemberOperatorNamespaces: []string{member1NS, member2NS},
+			Interval:                 10 * time.Millisecond,
+			Converged: func(username string, d time.Duration) {
+				converged[username] = d
+			},
+		}, converged
+	}
+
+	t.Run("all spaces converged", func(t *testing.T) {
+		// given
+		tr, converged := tracker(
+			space("zippy-0001", "base1ns", tierHash, ready),
+			nsTemplateSet(member1NS, "zippy-0001", tier.Spec, ready),
+			space("zippy-0002", "base1ns", tierHash, ready),
+			nsTemplateSet(member2NS, "zippy-0002", tier.Spec, ready),
+			// the users who are not in the tier are not part of the rollout
+			space("zippy-0003", "appstudio", "other", ready),
+			// the spaces of the other users are not part of the rollout
+			space("other-0001", "base1ns", "previous", ready),
+		)
+		updated := time.Now().Add(-90 * time.Second)
+
+		// when
+		spaces, err := tr.SpacesInTier(context.TODO(), "base1ns", []string{"zippy-0001", "zippy-0002", "zippy-0003", "zippy-0004"})
+		require.NoError(t, err)
+		stats, err := tr.Wait(context.TODO(), make(chan struct{}), tier, spaces, updated, time.Second)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"zippy-0001", "zippy-0002"}, spaces)
+		assert.Len(t, converged, 2)
+		assert.GreaterOrEqual(t, converged["zippy-0001"], 90*time.Second)
+		byTitle := map[string]float64{}
+		for _, r := range stats.Results("tier-rollout") {
+			assert.Equal(t, "tier-rollout", r.Phase)
+			byTitle[r.Title()] = r.Value
+		}
+		assert.Equal(t, 2.0, byTitle["Total Tier Rollout Spaces"])
+		assert.Equal(t, 0.0, byTitle["Total Tier Rollout Pending Spaces"])
+		assert.InDelta(t, 90.0, byTitle["Average Tier Rollout Time Per Space (s)"], 1)
+		assert.InDelta(t, 1.33, byTitle["Average Tier Rollout Throughput (spaces/m)"], 0.05)
+		// both spaces converged in the second minute of the rollout
+		assert.Equal(t, 2.0, byTitle["Max Tier Rollout Throughput (spaces/m)"])
+		series := stats.Series()
+		require.Len(t, series.Lines, 1)
+		assert.Equal(t, []float64{0, 2}, values(series.Lines[0].Points))
+	})
+
+	t.Run("spaces not converged", func(t *testing.T) {
+		// given
+		tr, converged := tracker(
+			// the host operator has not updated the space yet
+			space("zippy-0001", "base1ns", "previous", ready),
+			nsTemplateSet(member1NS, "zippy-0001", previous, ready),
+			// the member operator is updating the namespaces
+			space("zippy-0002", "base1ns", tierHash, updating),
+			nsTemplateSet(member1NS, "zippy-0002", tier.Spec, updating),
+			space("zippy-0003", "base1ns", tierHash, ready),
+			nsTemplateSet(member1NS, "zippy-0003", tier.Spec, ready),
+		)
+
+		// when
+		stats, err := tr.Wait(context.TODO(), make(chan struct{}), tier, []string{"zippy-0001", "zippy-0002", "zippy-0003"}, time.Now(), 100*time.Millisecond)
+
+		// then
+		require.EqualError(t, err, "2 space(s) did not converge to the revisions of tier 'base1ns' within 100ms")
+		assert.Len(t, converged, 1)
+		assert.Contains(t, stats.Results("tier-rollout"), results.Result{Name: "Tier Rollout Pending Spaces", Aggregate: results.Total, Value: 2, Phase: "tier-rollout"})
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		// given
+		tr, _ := tracker(space("zippy-0001", "base1ns", "previous", ready))
+		stop := make(chan struct{})
+		close(stop)
+
+		// when
+		stats, err := tr.Wait(context.TODO(), stop, tier, []string{"zippy-0001"}, time.Now(), time.Hour)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []results.Result{
+			{Name: "Tier Rollout Spaces", Aggregate: results.Total, Value: 1, Phase: "tier-rollout"},
+			{Name: "Tier Rollout Pending Spaces", Aggregate: results.Total, Value: 1, Phase: "tier-rollout"},
+		}, stats.Results("tier-rollout"))
+	})
+}
+
+// base1ns returns a tier with a cluster resources template, a namespace template and a space role template, along with its templates
+func base1ns() (*toolchainv1alpha1.NSTemplateTier, []runtime.Object) {
+	tier := &toolchainv1alpha1.NSTemplateTier{
+		ObjectMeta: metav1.ObjectMeta{Namespace: hostNS, Name: "base1ns"},
+		Spec: toolchainv1alpha1.NSTemplateTierSpec{
+			ClusterResources: &toolchainv1alpha1.NSTemplateTierClusterResources{TemplateRef: "base1ns-clusterresources-123456a-123456a"},
+			Namespaces:       []toolchainv1alpha1.NSTemplateTierNamespace{{TemplateRef: "base1ns-dev-123456b-123456b"}},
+			SpaceRoles:       map[string]toolchainv1alpha1.NSTemplateTierSpaceRole{"admin": {TemplateRef: "base1ns-admin-123456c-123456c"}},
+		},
+	}
+	tierTemplates := []runtime.Object{}
+	for templateType, name := range map[string]string{
+		"clusterresources": "base1ns-clusterresources-123456a-123456a",
+		"dev":              "base1ns-dev-123456b-123456b",
+		"admin":            "base1ns-admin-123456c-123456c",
+	} {
+		tierTemplates = append(tierTemplates, &toolchainv1alpha1.TierTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hostNS, Name: name},
+			Spec: toolchainv1alpha1.TierTemplateSpec{
+				TierName: "base1ns",
+				Type:     templateType,
+				Revision: name[len(name)-15:],
+			},
+		})
+	}
+	return tier, tierTemplates
+}
+
+func values(points []metrics.Point) []float64 {
+	vs := make([]float64, 0, len(points))
+	for _, p := range points {
+		vs = append(vs, p.Value)
+	}
+	return vs
+}
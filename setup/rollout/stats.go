@@ -0,0 +1,95 @@
+package rollout
+
+import (
+	"sync"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+)
+
+// throughputWindow is the width of the windows over which the throughput of the rollout is computed
+const throughputWindow = time.Minute
+
+// Stats collects the time it took for each Space to converge since the tier was updated. It is safe for concurrent use.
+type Stats struct {
+	mu      sync.Mutex
+	updated time.Time
+	spaces  int
+	times   latency.Distribution
+	// last is when the last Space converged
+	last time.Time
+	// windows are the number of Spaces that converged in each throughput window since the tier was updated
+	windows []int
+}
+
+func newStats(updated time.Time, spaces int) *Stats {
+	return &Stats{
+		updated: updated,
+		spaces:  spaces,
+	}
+}
+
+func (s *Stats) record(converged time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := converged.Sub(s.updated)
+	s.times.Add(d)
+	if converged.After(s.last) {
+		s.last = converged
+	}
+	i := int(d / throughputWindow)
+	for len(s.windows) <= i {
+		s.windows = append(s.windows, 0)
+	}
+	s.windows[i]++
+}
+
+// Results returns the number of Spaces of the rollout and of the ones that did not converge, the time it took for the Spaces to
+// converge and the throughput of the rollout, for the given phase
+func (s *Stats) Results(phase string) []results.Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	converged := s.times.Count()
+	rolloutResults := []results.Result{
+		{Name: "Tier Rollout Spaces", Aggregate: results.Total, Value: float64(s.spaces), Phase: phase},
+		{Name: "Tier Rollout Pending Spaces", Aggregate: results.Total, Value: float64(s.spaces - converged), Phase: phase},
+	}
+	if converged == 0 {
+		return rolloutResults
+	}
+	rolloutResults = append(rolloutResults, latency.Result("Tier Rollout Time Per Space", results.Average, phase, s.times.Mean()))
+	rolloutResults = append(rolloutResults, s.times.Summary("Tier Rollout Time Per Space", phase)...)
+	duration := s.last.Sub(s.updated)
+	rolloutResults = append(rolloutResults, latency.Result("Tier Rollout Duration", results.NoAggregate, phase, duration))
+	var average, peak float64
+	if duration > 0 {
+		average = float64(converged) / duration.Minutes()
+	}
+	for _, count := range s.windows {
+		if rate := float64(count) / throughputWindow.Minutes(); rate > peak {
+			peak = rate
+		}
+	}
+	rolloutResults = append(rolloutResults,
+		results.Result{Name: "Tier Rollout Throughput", Unit: results.SpacesPerMinute, Aggregate: results.Average, Value: average, Phase: phase, Precision: 2},
+		results.Result{Name: "Tier Rollout Throughput", Unit: results.SpacesPerMinute, Aggregate: results.Max, Value: peak, Phase: phase, Precision: 2},
+	)
+	return rolloutResults
+}
+
+// Series returns the throughput of the rollout over time, as the number of Spaces that converged in each window since the tier
+// was updated, so that it is charted along with the metrics of the run
+func (s *Stats) Series() metrics.Series {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	line := metrics.Line{Label: "Tier Rollout Throughput"}
+	for i, count := range s.windows {
+		line.Points = append(line.Points, metrics.Point{
+			Timestamp: s.updated.Add(time.Duration(i+1) * throughputWindow),
+			Value:     float64(count) / throughputWindow.Minutes(),
+		})
+	}
+	return metrics.Series{Name: "Tier Rollout Throughput", Unit: results.SpacesPerMinute, Lines: []metrics.Line{line}}
+}
@@ -0,0 +1,81 @@
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics/queries"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// catalogue is a file of user-defined queries
+type catalogue struct {
+	Queries []Query `yaml:"queries"`
+}
+
+// LoadQueries reads and validates the query catalogue file at the given path
+func LoadQueries(path string) ([]Query, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read query catalogue file '%s'", path)
+	}
+	return ParseQueries(path, content)
+}
+
+// ParseQueries parses and validates the given query catalogue, the errors are reported with their line numbers
+func ParseQueries(name string, content []byte) ([]Query, error) {
+	c := &catalogue{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return nil, fmt.Errorf("invalid query catalogue '%s': %s", name, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(content, root); err != nil {
+		return nil, fmt.Errorf("invalid query catalogue '%s': %s", name, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	errs := &validationErrors{}
+	validateQueries(errs, lines{root: root}, c.Queries, "queries")
+	if err := errs.errorOrNil(); err != nil {
+		return nil, fmt.Errorf("invalid query catalogue '%s':\n%s", name, err.Error())
+	}
+	return c.Queries, nil
+}
+
+// validateQueries checks the given queries, path is their location in the file
+func validateQueries(errs *validationErrors, l lines, qs []Query, path ...string) {
+	names := map[string]bool{}
+	for i, q := range qs {
+		line := l.of(append(path, strconv.Itoa(i))...)
+		if q.Name == "" || q.Query == "" {
+			errs.add(line, "query %d: name and query are required", i+1)
+			continue
+		}
+		if !contains(resultTypes, q.ResultType) {
+			errs.add(line, "query '%s': unknown resultType '%s', must be one of %s", q.Name, q.ResultType, strings.Join(resultTypes, ", "))
+		}
+		if q.Reduction != "" && !contains(reductions, q.Reduction) {
+			errs.add(line, "query '%s': unknown reduction '%s', must be one of %s", q.Name, q.Reduction, strings.Join(reductions, ", "))
+		}
+		if _, err := queries.Render(q.Name, queries.Placeholders{}); err != nil {
+			errs.add(line, "query '%s': %s", q.Name, err.Error())
+			continue
+		}
+		if _, err := queries.Render(q.Query, queries.Placeholders{}); err != nil {
+			errs.add(line, "query '%s': %s", q.Name, err.Error())
+			continue
+		}
+		// the queries that use the workload placeholders are run for each workload, the names of the results must tell them apart
+		if queries.UsesWorkload(q.Query) && !queries.UsesWorkload(q.Name) {
+			errs.add(line, "query '%s': the name must contain the {{.Workload}} placeholder since the query is run for each workload", q.Name)
+		}
+		if names[q.Name] {
+			errs.add(line, "query '%s': name is already used by another query", q.Name)
+		}
+		names[q.Name] = true
+	}
+}
@@ -0,0 +1,73 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadQueries(t *testing.T) {
+	t.Run("example catalogue", func(t *testing.T) {
+		// when
+		qs, err := LoadQueries("queries.yaml")
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, qs, 5)
+		assert.Equal(t, Query{
+			Name:       "etcd DB Size",
+			Query:      `etcd_mvcc_db_total_size_in_bytes{job="etcd"}`,
+			ResultType: "memory",
+			Reduction:  "keep",
+		}, qs[1])
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		// when
+		_, err := LoadQueries("not-found.yaml")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to read query catalogue file 'not-found.yaml'")
+	})
+
+	t.Run("invalid queries", func(t *testing.T) {
+		// given
+		content := `queries:
+- name: restarts
+  query: sum(kube_pod_container_status_restarts_total{namespace="{{.Namespace}}"})
+  resultType: count
+- name: '{{.Workload}} restarts'
+  query: up{namespace="{{.Unknown}}"}
+  resultType: count
+- name: rate
+  query: sum(rate(apiserver_request_total[5m]))
+  resultType: requests/sec
+- name: rate
+  query: sum(rate(apiserver_request_total[1m]))
+  resultType: simple
+- name: no query
+`
+
+		// when
+		_, err := ParseQueries("queries.yaml", []byte(content))
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid query catalogue 'queries.yaml':\n"+
+			"line 2: query 'restarts': the name must contain the {{.Workload}} placeholder since the query is run for each workload\n"+
+			"line 5: query '{{.Workload}} restarts': invalid placeholder")
+		assert.Contains(t, err.Error(), "line 8: query 'rate': unknown resultType 'requests/sec', must be one of percentage, memory, simple, bytes/sec, count, increase\n"+
+			"line 11: query 'rate': name is already used by another query\n"+
+			"line 14: query 5: name and query are required")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		// when
+		_, err := ParseQueries("queries.yaml", []byte("queries:\n- name: up\n  expr: up\n"))
+
+		// then
+		require.EqualError(t, err, "invalid query catalogue 'queries.yaml': unmarshal errors:\n  line 3: field expr not found in type scenario.Query")
+	})
+}
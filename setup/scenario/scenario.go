@@ -0,0 +1,546 @@
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/arrival"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/churn"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/metrics/queries"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is the type of work that a phase applies to each user
+type Kind string
+
+const (
+	// Signups creates the UserSignups and waits for their Spaces to be ready
+	Signups Kind = "signups"
+	// Idlers updates the timeout of the user idlers
+	Idlers Kind = "idlers"
+	// Templates applies a set of templates in the user namespaces
+	Templates Kind = "templates"
+)
+
+// default concurrency of each kind of phase
+var defaultConcurrency = map[Kind]int{
+	Signups:   10,
+	Idlers:    3,
+	Templates: 5,
+}
+
+const (
+	DefaultIdlerTimeout    = "15s"
+	DefaultSettleDuration  = "15m"
+	DefaultMetricsInterval = "5m"
+	DefaultMetricsStep     = "30s"
+	// DefaultUpdatedTier is the tier of the users
+	DefaultUpdatedTier    = "base1ns"
+	DefaultRolloutTimeout = "30m"
+)
+
+var resultTypes = []string{"percentage", "memory", "simple", "bytes/sec", "count", "increase"}
+
+var reductions = []string{"sum", "avg", "max", "keep"}
+
+const defaultRampSteps = 5
+
+const defaultSoakConcurrency = 10
+
+// Scenario declares the phases of a setup run and everything that is needed to reproduce it
+type Scenario struct {
+	// Users is the number of users to provision
+	Users int `yaml:"users"`
+	// Phases are started in order, each one is applied to the users concurrently
+	Phases []Phase `yaml:"phases"`
+	// TierUpdate rolls a new revision of a tier out to the Spaces once all phases are complete
+	TierUpdate *TierUpdate `yaml:"tierUpdate,omitempty"`
+	// Soak keeps the users churning through their lifecycle once all phases are complete
+	Soak *Soak `yaml:"soak,omitempty"`
+	// Metrics configures what is gathered during the run
+	Metrics Metrics `yaml:"metrics,omitempty"`
+	// SettleDuration is how long the metrics are gathered after all phases are complete
+	SettleDuration string `yaml:"settleDuration,omitempty"`
+
+	settleDuration time.Duration
+}
+
+// Phase is a step of the run that is applied to each of its users
+type Phase struct {
+	Name string `yaml:"name"`
+	Kind Kind   `yaml:"kind"`
+	// Concurrency is the number of routines processing the users of the phase
+	Concurrency int `yaml:"concurrency,omitempty"`
+	// Users is the number of users the phase is applied to, starting with the first user. Mutually exclusive with Ratio.
+	Users *int `yaml:"users,omitempty"`
+	// Ratio is the share of all users the phase is applied to. Mutually exclusive with Users.
+	Ratio *float64 `yaml:"ratio,omitempty"`
+	// Templates are the paths of the template files applied by a 'templates' phase
+	Templates []string `yaml:"templates,omitempty"`
+	// IdlerTimeout is the timeout set by an 'idlers' phase
+	IdlerTimeout string `yaml:"idlerTimeout,omitempty"`
+	// Arrival starts the users of a 'signups' phase at a given rate instead of as fast as the concurrent routines allow
+	Arrival *Arrival `yaml:"arrival,omitempty"`
+
+	idlerTimeout time.Duration
+}
+
+// Arrival declares the rate at which the users arrive (open-loop mode)
+type Arrival struct {
+	// Rate is the target rate, eg. '5/s', '120/m'
+	Rate string `yaml:"rate"`
+	// Ramp is how the rate goes from RampFrom to Rate during RampDuration: 'linear' or 'step'
+	Ramp         string `yaml:"ramp,omitempty"`
+	RampFrom     string `yaml:"rampFrom,omitempty"`
+	RampDuration string `yaml:"rampDuration,omitempty"`
+	// RampSteps is the number of steps of a 'step' ramp
+	RampSteps int `yaml:"rampSteps,omitempty"`
+	// Bursts are groups of users that arrive all at once on top of the rate, eg. '100@5m'
+	Bursts []string `yaml:"bursts,omitempty"`
+
+	profile arrival.Profile
+}
+
+// TierUpdate declares the update of a tier whose rollout to the Spaces of the users is measured
+type TierUpdate struct {
+	// Tier is the NSTemplateTier whose templates get a new revision, the tier of the users by default
+	Tier string `yaml:"tier,omitempty"`
+	// Timeout is how long the Spaces are given to converge to the new revisions
+	Timeout string `yaml:"timeout,omitempty"`
+
+	timeout time.Duration
+}
+
+// Soak declares the lifecycle operations applied to the users for a given duration, the banned and deleted users are
+// replaced by new users to keep the population at its size
+type Soak struct {
+	// Duration is how long the operations are started, eg. '4h'
+	Duration string `yaml:"duration"`
+	// Population is the number of users that are kept signed up, active or deactivated, the number of users of the scenario by default
+	Population int `yaml:"population,omitempty"`
+	// Concurrency is the maximum number of operations in flight
+	Concurrency int `yaml:"concurrency,omitempty"`
+	// Rates are the rates of the deactivate, reactivate, ban and delete operations, eg. 'deactivate: 10/m'
+	Rates map[string]string `yaml:"rates"`
+
+	duration time.Duration
+	rates    map[churn.Operation]float64
+}
+
+// Metrics configures the metrics gathered during the run
+type Metrics struct {
+	// Interval is the time between two samples of the queries
+	Interval string `yaml:"interval,omitempty"`
+	// Step is the resolution of the range queries run over the whole run, the range queries are disabled with '0s'
+	// and only the samples taken at each interval are used
+	Step string `yaml:"step,omitempty"`
+	// Workloads are kind/namespace/name triples (or namespace:name pairs of deployments) of the workloads whose CPU and
+	// memory usage, restarts and OOMKills are gathered
+	Workloads []string `yaml:"workloads,omitempty"`
+	// Queries are additional PromQL queries to gather
+	Queries []Query `yaml:"queries,omitempty"`
+
+	interval time.Duration
+	step     time.Duration
+}
+
+// Query is an additional PromQL query, the same format is used by the query catalogue files
+type Query struct {
+	// Name and Query can contain placeholders, see queries.Placeholders
+	Name       string `yaml:"name"`
+	Query      string `yaml:"query"`
+	ResultType string `yaml:"resultType"`
+	// Reduction is how the series of the query are combined: sum, avg (the default), max or keep to also report each series
+	Reduction string `yaml:"reduction,omitempty"`
+}
+
+// UserCount returns the number of users the phase is applied to
+func (p Phase) UserCount(totalUsers int) int {
+	if p.Users != nil {
+		return *p.Users
+	}
+	if p.Ratio != nil {
+		return int(math.Round(*p.Ratio * float64(totalUsers)))
+	}
+	return totalUsers
+}
+
+// GetIdlerTimeout returns the parsed idler timeout, it is only set once the scenario is validated
+func (p Phase) GetIdlerTimeout() time.Duration {
+	return p.idlerTimeout
+}
+
+// GetProfile returns the parsed arrival profile, it is only set once the scenario is validated
+func (a Arrival) GetProfile() arrival.Profile {
+	return a.profile
+}
+
+// GetTimeout returns the parsed rollout timeout, it is only set once the scenario is validated
+func (u TierUpdate) GetTimeout() time.Duration {
+	return u.timeout
+}
+
+// GetDuration returns the parsed soak duration, it is only set once the scenario is validated
+func (s Soak) GetDuration() time.Duration {
+	return s.duration
+}
+
+// GetRates returns the parsed rates of the operations in operations per second, they are only set once the scenario is validated
+func (s Soak) GetRates() map[churn.Operation]float64 {
+	return s.rates
+}
+
+// GetSettleDuration returns the parsed settle duration, it is only set once the scenario is validated
+func (s Scenario) GetSettleDuration() time.Duration {
+	return s.settleDuration
+}
+
+// GetInterval returns the parsed metrics interval, it is only set once the scenario is validated
+func (m Metrics) GetInterval() time.Duration {
+	return m.interval
+}
+
+// GetStep returns the parsed range query step, it is only set once the scenario is validated
+func (m Metrics) GetStep() time.Duration {
+	return m.step
+}
+
+// Load reads and validates the scenario file at the given path. It returns the scenario along with the raw content of the file.
+func Load(path string) (*Scenario, []byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "unable to read scenario file '%s'", path)
+	}
+	s, err := Parse(path, content)
+	return s, content, err
+}
+
+// Parse decodes and validates a scenario, the name is used as a prefix for the errors
+func Parse(name string, content []byte) (*Scenario, error) {
+	s := &Scenario{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(s); err != nil {
+		// the yaml errors already contain the line numbers
+		return nil, fmt.Errorf("invalid scenario '%s': %s", name, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(content, root); err != nil {
+		return nil, fmt.Errorf("invalid scenario '%s': %s", name, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+	if err := s.validate(lines{root: root}); err != nil {
+		return nil, fmt.Errorf("invalid scenario '%s':\n%s", name, err.Error())
+	}
+	return s, nil
+}
+
+// Validate checks a scenario that was not loaded from a file, eg. one built from the command flags
+func (s *Scenario) Validate() error {
+	return s.validate(lines{})
+}
+
+// Marshal returns the scenario as yaml
+func (s *Scenario) Marshal() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// validate checks the values of the scenario, sets the defaults and parses the durations
+func (s *Scenario) validate(l lines) error { // nolint:gocyclo
+	errs := &validationErrors{}
+
+	if s.Users < 1 {
+		errs.add(l.of("users"), "users must be more than 0")
+	}
+
+	if s.SettleDuration == "" {
+		s.SettleDuration = DefaultSettleDuration
+	}
+	var err error
+	if s.settleDuration, err = time.ParseDuration(s.SettleDuration); err != nil {
+		errs.add(l.of("settleDuration"), "invalid settleDuration '%s'", s.SettleDuration)
+	}
+
+	if len(s.Phases) == 0 {
+		errs.add(l.of("phases"), "at least one phase is required")
+	}
+	names := map[string]bool{}
+	for i := range s.Phases {
+		p := &s.Phases[i]
+		line := l.of("phases", strconv.Itoa(i))
+		if p.Name == "" {
+			errs.add(line, "phase %d: name is required", i+1)
+		} else if names[p.Name] {
+			errs.add(line, "phase '%s': name is already used by another phase", p.Name)
+		}
+		names[p.Name] = true
+
+		if _, ok := defaultConcurrency[p.Kind]; !ok {
+			errs.add(l.of("phases", strconv.Itoa(i), "kind"), "phase '%s': unknown kind '%s', must be one of %s, %s or %s", p.Name, p.Kind, Signups, Idlers, Templates)
+			continue
+		}
+		if p.Kind == Signups && i != 0 {
+			errs.add(line, "phase '%s': the signups phase must be the first phase", p.Name)
+		}
+		if i == 0 && p.Kind != Signups {
+			errs.add(line, "phase '%s': the first phase must be a signups phase", p.Name)
+		}
+
+		if p.Concurrency == 0 {
+			p.Concurrency = defaultConcurrency[p.Kind]
+		} else if p.Concurrency < 0 {
+			errs.add(l.of("phases", strconv.Itoa(i), "concurrency"), "phase '%s': concurrency must be more than 0", p.Name)
+		}
+
+		if p.Users != nil && p.Ratio != nil {
+			errs.add(line, "phase '%s': users and ratio are mutually exclusive", p.Name)
+		} else if p.Users != nil && (*p.Users < 0 || *p.Users > s.Users) {
+			errs.add(l.of("phases", strconv.Itoa(i), "users"), "phase '%s': users must be between 0 and %d", p.Name, s.Users)
+		} else if p.Ratio != nil && (*p.Ratio < 0 || *p.Ratio > 1) {
+			errs.add(l.of("phases", strconv.Itoa(i), "ratio"), "phase '%s': ratio must be between 0 and 1", p.Name)
+		}
+		if p.Kind == Signups && p.UserCount(s.Users) != s.Users {
+			errs.add(line, "phase '%s': the signups phase must apply to all users", p.Name)
+		}
+
+		switch p.Kind {
+		case Templates:
+			if len(p.Templates) == 0 && p.UserCount(s.Users) > 0 {
+				errs.add(line, "phase '%s': at least one template is required", p.Name)
+			}
+			for j, t := range p.Templates {
+				if _, err := os.Stat(t); err != nil {
+					errs.add(l.of("phases", strconv.Itoa(i), "templates", strconv.Itoa(j)), "phase '%s': invalid template file '%s'", p.Name, t)
+				}
+			}
+		case Idlers:
+			if p.IdlerTimeout == "" {
+				p.IdlerTimeout = DefaultIdlerTimeout
+			}
+			if p.idlerTimeout, err = time.ParseDuration(p.IdlerTimeout); err != nil {
+				errs.add(l.of("phases", strconv.Itoa(i), "idlerTimeout"), "phase '%s': invalid idlerTimeout '%s'", p.Name, p.IdlerTimeout)
+			}
+		}
+		if p.Kind != Templates && len(p.Templates) > 0 {
+			errs.add(l.of("phases", strconv.Itoa(i), "templates"), "phase '%s': templates can only be set on a %s phase", p.Name, Templates)
+		}
+		if p.Kind != Idlers && p.IdlerTimeout != "" {
+			errs.add(l.of("phases", strconv.Itoa(i), "idlerTimeout"), "phase '%s': idlerTimeout can only be set on an %s phase", p.Name, Idlers)
+		}
+		if p.Arrival != nil {
+			if p.Kind != Signups {
+				errs.add(l.of("phases", strconv.Itoa(i), "arrival"), "phase '%s': arrival can only be set on a %s phase", p.Name, Signups)
+			} else {
+				p.Arrival.validate(errs, l, p.Name, "phases", strconv.Itoa(i), "arrival")
+			}
+		}
+	}
+
+	if s.TierUpdate != nil {
+		if s.TierUpdate.Tier == "" {
+			s.TierUpdate.Tier = DefaultUpdatedTier
+		}
+		if s.TierUpdate.Timeout == "" {
+			s.TierUpdate.Timeout = DefaultRolloutTimeout
+		}
+		if s.TierUpdate.timeout, err = time.ParseDuration(s.TierUpdate.Timeout); err != nil || s.TierUpdate.timeout <= 0 {
+			errs.add(l.of("tierUpdate", "timeout"), "invalid tier update timeout '%s'", s.TierUpdate.Timeout)
+		}
+	}
+
+	if s.Soak != nil {
+		s.Soak.validate(errs, l, s.Users)
+	}
+
+	if s.Metrics.Interval == "" {
+		s.Metrics.Interval = DefaultMetricsInterval
+	}
+	if s.Metrics.interval, err = time.ParseDuration(s.Metrics.Interval); err != nil || s.Metrics.interval <= 0 {
+		errs.add(l.of("metrics", "interval"), "invalid metrics interval '%s'", s.Metrics.Interval)
+	}
+	if s.Metrics.Step == "" {
+		s.Metrics.Step = DefaultMetricsStep
+	}
+	if s.Metrics.step, err = time.ParseDuration(s.Metrics.Step); err != nil || s.Metrics.step < 0 {
+		errs.add(l.of("metrics", "step"), "invalid metrics step '%s'", s.Metrics.Step)
+	}
+	for i, w := range s.Metrics.Workloads {
+		if _, err := queries.ParseWorkload(w); err != nil {
+			errs.add(l.of("metrics", "workloads", strconv.Itoa(i)), "%s", err.Error())
+		}
+	}
+	validateQueries(errs, l, s.Metrics.Queries, "metrics", "queries")
+
+	return errs.errorOrNil()
+}
+
+// validate parses the arrival profile, path is the location of the arrival in the scenario
+func (a *Arrival) validate(errs *validationErrors, l lines, phase string, path ...string) {
+	at := func(key string) int {
+		return l.of(append(path, key)...)
+	}
+	var err error
+	if a.profile.Rate, err = arrival.ParseRate(a.Rate); err != nil {
+		errs.add(at("rate"), "phase '%s': %s", phase, err.Error())
+	} else if a.profile.Rate == 0 {
+		errs.add(at("rate"), "phase '%s': the arrival rate must be more than 0", phase)
+	}
+
+	a.profile.Ramp = arrival.Ramp(a.Ramp)
+	switch a.profile.Ramp {
+	case arrival.NoRamp:
+		if a.RampFrom != "" || a.RampDuration != "" || a.RampSteps != 0 {
+			errs.add(at("ramp"), "phase '%s': rampFrom, rampDuration and rampSteps require a ramp", phase)
+		}
+	case arrival.Linear, arrival.Step:
+		if a.RampFrom == "" {
+			a.RampFrom = "0/s"
+		}
+		if a.profile.RampFrom, err = arrival.ParseRate(a.RampFrom); err != nil {
+			errs.add(at("rampFrom"), "phase '%s': %s", phase, err.Error())
+		}
+		if a.profile.RampDuration, err = time.ParseDuration(a.RampDuration); err != nil || a.profile.RampDuration <= 0 {
+			errs.add(at("rampDuration"), "phase '%s': invalid rampDuration '%s'", phase, a.RampDuration)
+		}
+		if a.profile.Ramp == arrival.Step && a.RampSteps == 0 {
+			a.RampSteps = defaultRampSteps
+		}
+		if a.profile.Ramp == arrival.Linear && a.RampSteps != 0 {
+			errs.add(at("rampSteps"), "phase '%s': rampSteps can only be set on a %s ramp", phase, arrival.Step)
+		} else if a.RampSteps < 0 {
+			errs.add(at("rampSteps"), "phase '%s': rampSteps must be more than 0", phase)
+		}
+		a.profile.RampSteps = a.RampSteps
+	default:
+		errs.add(at("ramp"), "phase '%s': unknown ramp '%s', must be %s or %s", phase, a.Ramp, arrival.Linear, arrival.Step)
+	}
+
+	a.profile.Bursts = nil
+	for j, b := range a.Bursts {
+		burst, err := arrival.ParseBurst(b)
+		if err != nil {
+			errs.add(l.of(append(path, "bursts", strconv.Itoa(j))...), "phase '%s': %s", phase, err.Error())
+			continue
+		}
+		a.profile.Bursts = append(a.profile.Bursts, burst)
+	}
+}
+
+// validate parses the duration and the rates of the soak, and sets its defaults
+func (s *Soak) validate(errs *validationErrors, l lines, users int) {
+	at := func(path ...string) int {
+		return l.of(append([]string{"soak"}, path...)...)
+	}
+	var err error
+	if s.duration, err = time.ParseDuration(s.Duration); err != nil || s.duration <= 0 {
+		errs.add(at("duration"), "invalid soak duration '%s'", s.Duration)
+	}
+	if s.Population == 0 {
+		s.Population = users
+	} else if s.Population < 0 {
+		errs.add(at("population"), "soak population must be more than 0")
+	}
+	if s.Concurrency == 0 {
+		s.Concurrency = defaultSoakConcurrency
+	} else if s.Concurrency < 0 {
+		errs.add(at("concurrency"), "soak concurrency must be more than 0")
+	}
+
+	if len(s.Rates) == 0 {
+		errs.add(at("rates"), "at least one soak rate is required eg. 'deactivate: 10/m'")
+	}
+	operations := make([]string, len(churn.Operations))
+	for i, op := range churn.Operations {
+		operations[i] = string(op)
+	}
+	names := make([]string, 0, len(s.Rates))
+	for name := range s.Rates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s.rates = map[churn.Operation]float64{}
+	for _, name := range names {
+		if !contains(operations, name) {
+			errs.add(at("rates", name), "unknown soak operation '%s', must be one of %s or %s", name, strings.Join(operations[:len(operations)-1], ", "), operations[len(operations)-1])
+			continue
+		}
+		rate, err := arrival.ParseRate(s.Rates[name])
+		if err != nil {
+			errs.add(at("rates", name), "soak operation '%s': %s", name, err.Error())
+			continue
+		}
+		s.rates[churn.Operation(name)] = rate
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// lines looks up the line numbers of the scenario's values in the yaml document
+type lines struct {
+	root *yaml.Node
+}
+
+// of returns the line of the value at the given path, or the line of the closest parent that exists.
+// It returns 0 if the scenario was not loaded from a yaml document.
+func (l lines) of(path ...string) int {
+	if l.root == nil || len(l.root.Content) == 0 {
+		return 0
+	}
+	node := l.root.Content[0]
+	line := node.Line
+	for _, key := range path {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if idx, err := strconv.Atoi(key); err == nil && idx < len(node.Content) {
+				next = node.Content[idx]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		node = next
+	}
+	return line
+}
+
+type validationErrors struct {
+	msgs []string
+}
+
+func (e *validationErrors) add(line int, msg string, args ...interface{}) {
+	if line > 0 {
+		e.msgs = append(e.msgs, fmt.Sprintf("line %d: %s", line, fmt.Sprintf(msg, args...)))
+		return
+	}
+	e.msgs = append(e.msgs, fmt.Sprintf(msg, args...))
+}
+
+func (e *validationErrors) errorOrNil() error {
+	if len(e.msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(e.msgs, "\n"))
+}
@@ -0,0 +1,348 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/arrival"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/churn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	template := filepath.Join(t.TempDir(), "template.yaml")
+	require.NoError(t, os.WriteFile(template, []byte("kind: Template"), 0600))
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		content := `users: 100
+phases:
+- name: signups
+  kind: signups
+- name: idlers
+  kind: idlers
+  concurrency: 2
+  idlerTimeout: 1m
+- name: default
+  kind: templates
+  ratio: 0.5
+  templates:
+  - ` + template + `
+- name: custom
+  kind: templates
+  users: 10
+  templates:
+  - ` + template + `
+metrics:
+  interval: 1m
+  step: 15s
+  workloads:
+  - my-ns:my-operator
+  - StatefulSet/openshift-logging/elasticsearch
+  queries:
+  - name: apiserver requests
+    query: sum(rate(apiserver_request_total[5m]))
+    resultType: simple
+    reduction: keep
+settleDuration: 2m
+`
+
+		// when
+		s, err := Parse("scenario.yaml", []byte(content))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 100, s.Users)
+		require.Len(t, s.Phases, 4)
+		assert.Equal(t, 10, s.Phases[0].Concurrency) // default
+		assert.Equal(t, 100, s.Phases[0].UserCount(s.Users))
+		assert.Equal(t, 2, s.Phases[1].Concurrency)
+		assert.Equal(t, time.Minute, s.Phases[1].GetIdlerTimeout())
+		assert.Equal(t, 5, s.Phases[2].Concurrency) // default
+		assert.Equal(t, 50, s.Phases[2].UserCount(s.Users))
+		assert.Equal(t, 10, s.Phases[3].UserCount(s.Users))
+		assert.Equal(t, time.Minute, s.Metrics.GetInterval())
+		assert.Equal(t, 15*time.Second, s.Metrics.GetStep())
+		assert.Equal(t, 2*time.Minute, s.GetSettleDuration())
+		require.Len(t, s.Metrics.Queries, 1)
+		assert.Equal(t, "keep", s.Metrics.Queries[0].Reduction)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		// given
+		content := `users: 1
+phases:
+- name: signups
+  kind: signups
+- name: idlers
+  kind: idlers
+`
+		// when
+		s, err := Parse("scenario.yaml", []byte(content))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 15*time.Second, s.Phases[1].GetIdlerTimeout())
+		assert.Equal(t, 5*time.Minute, s.Metrics.GetInterval())
+		assert.Equal(t, 30*time.Second, s.Metrics.GetStep())
+		assert.Equal(t, 15*time.Minute, s.GetSettleDuration())
+	})
+
+	t.Run("arrival", func(t *testing.T) {
+		// given
+		content := `users: 100
+phases:
+- name: signups
+  kind: signups
+  arrival:
+    rate: 300/m
+    ramp: step
+    rampDuration: 10m
+    bursts:
+    - 20@2m
+`
+		// when
+		s, err := Parse("scenario.yaml", []byte(content))
+
+		// then
+		require.NoError(t, err)
+		require.NotNil(t, s.Phases[0].Arrival)
+		assert.Equal(t, arrival.Profile{
+			Rate:         5,
+			Ramp:         arrival.Step,
+			RampDuration: 10 * time.Minute,
+			RampSteps:    5, // default
+			Bursts:       []arrival.Burst{{At: 2 * time.Minute, Users: 20}},
+		}, s.Phases[0].Arrival.GetProfile())
+	})
+
+	t.Run("soak", func(t *testing.T) {
+		// given
+		content := `users: 100
+phases:
+- name: signups
+  kind: signups
+soak:
+  duration: 4h
+  rates:
+    deactivate: 10/m
+    reactivate: 5/m
+    ban: 1/h
+`
+		// when
+		s, err := Parse("scenario.yaml", []byte(content))
+
+		// then
+		require.NoError(t, err)
+		require.NotNil(t, s.Soak)
+		assert.Equal(t, 4*time.Hour, s.Soak.GetDuration())
+		assert.Equal(t, 100, s.Soak.Population) // default
+		assert.Equal(t, 10, s.Soak.Concurrency) // default
+		assert.Equal(t, map[churn.Operation]float64{churn.Deactivate: 10.0 / 60, churn.Reactivate: 5.0 / 60, churn.Ban: 1.0 / 3600}, s.Soak.GetRates())
+	})
+
+	t.Run("tier update", func(t *testing.T) {
+		// given
+		content := `users: 100
+phases:
+- name: signups
+  kind: signups
+tierUpdate: {}
+`
+		// when
+		s, err := Parse("scenario.yaml", []byte(content))
+
+		// then
+		require.NoError(t, err)
+		require.NotNil(t, s.TierUpdate)
+		assert.Equal(t, "base1ns", s.TierUpdate.Tier)              // default
+		assert.Equal(t, 30*time.Minute, s.TierUpdate.GetTimeout()) // default
+	})
+
+	t.Run("marshal and parse again", func(t *testing.T) {
+		// given
+		users := 1
+		s := &Scenario{
+			Users: 2,
+			Phases: []Phase{
+				{Name: "signups", Kind: Signups},
+				{Name: "default", Kind: Templates, Users: &users, Templates: []string{template}},
+			},
+		}
+		require.NoError(t, s.Validate())
+
+		// when
+		content, err := s.Marshal()
+		require.NoError(t, err)
+		parsed, err := Parse("scenario.yaml", content)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, s, parsed)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+
+		t.Run("unknown field", func(t *testing.T) {
+			// given
+			content := `users: 1
+phases:
+- name: signups
+  kind: signups
+  foo: bar
+`
+			// when
+			_, err := Parse("scenario.yaml", []byte(content))
+
+			// then
+			require.EqualError(t, err, "invalid scenario 'scenario.yaml': unmarshal errors:\n  line 5: field foo not found in type scenario.Phase")
+		})
+
+		t.Run("invalid values", func(t *testing.T) {
+			// given
+			content := `users: 10
+phases:
+- name: idlers
+  kind: idlers
+  idlerTimeout: soon
+- name: signups
+  kind: signups
+  ratio: 0.5
+- name: default
+  kind: templates
+  users: 11
+  templates:
+  - not-found.yaml
+- name: default
+  kind: unknown
+metrics:
+  interval: 0s
+  step: -1s
+  workloads:
+  - no-pair
+  queries:
+  - name: q
+    query: up
+    resultType: bytes
+    reduction: median
+settleDuration: forever
+`
+			// when
+			_, err := Parse("scenario.yaml", []byte(content))
+
+			// then
+			require.EqualError(t, err, `invalid scenario 'scenario.yaml':
+line 26: invalid settleDuration 'forever'
+line 3: phase 'idlers': the first phase must be a signups phase
+line 5: phase 'idlers': invalid idlerTimeout 'soon'
+line 6: phase 'signups': the signups phase must be the first phase
+line 6: phase 'signups': the signups phase must apply to all users
+line 11: phase 'default': users must be between 0 and 10
+line 13: phase 'default': invalid template file 'not-found.yaml'
+line 14: phase 'default': name is already used by another phase
+line 15: phase 'default': unknown kind 'unknown', must be one of signups, idlers or templates
+line 17: invalid metrics interval '0s'
+line 18: invalid metrics step '-1s'
+line 20: invalid workload 'no-pair', must be a kind/namespace/name triple or a namespace:name pair for a deployment
+line 22: query 'q': unknown resultType 'bytes', must be one of percentage, memory, simple, bytes/sec, count, increase
+line 22: query 'q': unknown reduction 'median', must be one of sum, avg, max, keep`)
+		})
+
+		t.Run("invalid arrival", func(t *testing.T) {
+			// given
+			content := `users: 10
+phases:
+- name: signups
+  kind: signups
+  arrival:
+    rate: 0/s
+    ramp: linear
+    rampSteps: 2
+    bursts:
+    - soon
+- name: idlers
+  kind: idlers
+  arrival:
+    rate: 1/s
+`
+			// when
+			_, err := Parse("scenario.yaml", []byte(content))
+
+			// then
+			require.EqualError(t, err, `invalid scenario 'scenario.yaml':
+line 6: phase 'signups': the arrival rate must be more than 0
+line 5: phase 'signups': invalid rampDuration ''
+line 8: phase 'signups': rampSteps can only be set on a step ramp
+line 10: phase 'signups': invalid burst 'soon', must be a number of users and a time eg. '100@5m'
+line 13: phase 'idlers': arrival can only be set on a signups phase`)
+		})
+
+		t.Run("invalid soak", func(t *testing.T) {
+			// given
+			content := `users: 10
+phases:
+- name: signups
+  kind: signups
+soak:
+  duration: -1h
+  population: -1
+  rates:
+    deactivate: often
+    suspend: 1/m
+`
+			// when
+			_, err := Parse("scenario.yaml", []byte(content))
+
+			// then
+			require.EqualError(t, err, `invalid scenario 'scenario.yaml':
+line 6: invalid soak duration '-1h'
+line 7: soak population must be more than 0
+line 9: soak operation 'deactivate': invalid rate 'often', must be a number of users per s, m or h eg. '5/s'
+line 10: unknown soak operation 'suspend', must be one of deactivate, reactivate, ban or delete`)
+		})
+
+		t.Run("invalid tier update timeout", func(t *testing.T) {
+			// given
+			content := `users: 10
+phases:
+- name: signups
+  kind: signups
+tierUpdate:
+  tier: base1ns
+  timeout: never
+`
+			// when
+			_, err := Parse("scenario.yaml", []byte(content))
+
+			// then
+			require.EqualError(t, err, "invalid scenario 'scenario.yaml':\nline 7: invalid tier update timeout 'never'")
+		})
+
+		t.Run("no soak rates", func(t *testing.T) {
+			// given
+			content := `users: 10
+phases:
+- name: signups
+  kind: signups
+soak:
+  duration: 1h
+`
+			// when
+			_, err := Parse("scenario.yaml", []byte(content))
+
+			// then
+			require.EqualError(t, err, "invalid scenario 'scenario.yaml':\nline 5: at least one soak rate is required eg. 'deactivate: 10/m'")
+		})
+
+		t.Run("no phases", func(t *testing.T) {
+			// when
+			_, err := Parse("scenario.yaml", []byte("users: 0\n"))
+
+			// then
+			require.EqualError(t, err, "invalid scenario 'scenario.yaml':\nline 1: users must be more than 0\nline 1: at least one phase is required")
+		})
+	})
+}
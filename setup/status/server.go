@@ -0,0 +1,51 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server serves the status and the metrics of a running setup
+type Server struct {
+	srv *http.Server
+	// Addr is the address the server listens on, eg. with the port chosen by the system when the given port is 0
+	Addr string
+}
+
+// Serve starts serving the /status and /metrics endpoints of the tracker on the given address, eg. ':9095'
+func Serve(addr string, t *Tracker) (*Server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to listen on '%s'", addr)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/status", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(t.Status()) // nolint:errcheck
+	}))
+	mux.Handle("/metrics", promhttp.HandlerFor(t.registry, promhttp.HandlerOpts{}))
+	s := &Server{
+		srv: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		Addr: l.Addr().String(),
+	}
+	go s.srv.Serve(l) // nolint:errcheck
+	return s, nil
+}
+
+// Shutdown stops the server, the requests in progress are given a few seconds to complete
+func (s *Server) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(ctx)
+}
@@ -0,0 +1,208 @@
+package status
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// throughputWindow is the period over which the current throughput of a phase is computed
+const throughputWindow = time.Minute
+
+// State is the state of a phase of a run
+type State string
+
+const (
+	Running   State = "running"
+	Completed State = "completed"
+)
+
+// Status is the progress of a run, as served by the /status endpoint
+type Status struct {
+	StartedAt      time.Time `json:"startedAt"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+	Interrupted    bool      `json:"interrupted"`
+	// Failures is the number of users that failed in any phase
+	Failures int           `json:"failures"`
+	Phases   []PhaseStatus `json:"phases"`
+}
+
+// PhaseStatus is the progress of a phase of a run
+type PhaseStatus struct {
+	Name      string     `json:"name"`
+	State     State      `json:"state"`
+	Users     int        `json:"users"`
+	Completed int        `json:"completed"`
+	Failed    int        `json:"failed"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+	// Throughput is the number of users completed per second over the last minute
+	Throughput float64 `json:"throughput"`
+	// ETASeconds is the time left until all the users of the phase are processed at the average rate of the phase so far,
+	// it is not set until a user is completed
+	ETASeconds *float64 `json:"etaSeconds,omitempty"`
+}
+
+type phase struct {
+	name      string
+	users     int
+	completed int
+	failed    int
+	started   time.Time
+	ended     time.Time
+	// recent are the completion times of the users within the throughput window
+	recent []time.Time
+}
+
+// Tracker records the progress of the phases of a run, for the /status and /metrics endpoints
+type Tracker struct {
+	mu          sync.Mutex
+	started     time.Time
+	interrupted bool
+	phases      []*phase
+	// now is replaced in the tests
+	now func() time.Time
+
+	registry  *prometheus.Registry
+	users     *prometheus.GaugeVec
+	completed *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+}
+
+// NewTracker returns a tracker of a run started now
+func NewTracker() *Tracker {
+	t := &Tracker{
+		now:      time.Now,
+		registry: prometheus.NewRegistry(),
+		users: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "toolchain_setup_phase_users",
+			Help: "The number of users processed by the phase.",
+		}, []string{"phase"}),
+		completed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "toolchain_setup_users_completed_total",
+			Help: "The number of users that the phase processed successfully.",
+		}, []string{"phase"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "toolchain_setup_users_failed_total",
+			Help: "The number of users that the phase failed to process.",
+		}, []string{"phase"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "toolchain_setup_user_duration_seconds",
+			Help:    "The time spent by the phase on each user.",
+			Buckets: prometheus.ExponentialBuckets(0.25, 2, 12),
+		}, []string{"phase"}),
+	}
+	t.started = t.now()
+	t.registry.MustRegister(t.users, t.completed, t.failed, t.durations)
+	return t
+}
+
+// PhaseStarted records the start of the phase with the given number of users
+func (t *Tracker) PhaseStarted(name string, users int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.phases = append(t.phases, &phase{name: name, users: users, started: t.now()})
+	t.users.WithLabelValues(name).Set(float64(users))
+}
+
+// PhaseCompleted records the end of the phase
+func (t *Tracker) PhaseCompleted(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p := t.phase(name); p != nil {
+		p.ended = t.now()
+	}
+}
+
+// UserCompleted records a user that the phase processed successfully in the given time
+func (t *Tracker) UserCompleted(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p := t.phase(name); p != nil {
+		p.completed++
+		p.recent = append(p.recent, t.now())
+	}
+	t.completed.WithLabelValues(name).Inc()
+	t.durations.WithLabelValues(name).Observe(d.Seconds())
+}
+
+// UserFailed records a user that the phase failed to process
+func (t *Tracker) UserFailed(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p := t.phase(name); p != nil {
+		p.failed++
+	}
+	t.failed.WithLabelValues(name).Inc()
+}
+
+// Interrupted records that the run was interrupted
+func (t *Tracker) Interrupted() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.interrupted = true
+}
+
+func (t *Tracker) phase(name string) *phase {
+	for _, p := range t.phases {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Status returns the progress of the run
+func (t *Tracker) Status() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	s := Status{
+		StartedAt:      t.started,
+		ElapsedSeconds: now.Sub(t.started).Seconds(),
+		Interrupted:    t.interrupted,
+		Phases:         make([]PhaseStatus, 0, len(t.phases)),
+	}
+	for _, p := range t.phases {
+		s.Failures += p.failed
+		s.Phases = append(s.Phases, p.status(now))
+	}
+	return s
+}
+
+func (p *phase) status(now time.Time) PhaseStatus {
+	s := PhaseStatus{
+		Name:      p.name,
+		State:     Running,
+		Users:     p.users,
+		Completed: p.completed,
+		Failed:    p.failed,
+		StartedAt: p.started,
+	}
+	if !p.ended.IsZero() {
+		ended := p.ended
+		s.State = Completed
+		s.EndedAt = &ended
+		return s
+	}
+	// only the completions within the window are kept
+	recent := p.recent[:0]
+	for _, completed := range p.recent {
+		if now.Sub(completed) <= throughputWindow {
+			recent = append(recent, completed)
+		}
+	}
+	p.recent = recent
+	s.Throughput = float64(len(recent)) / throughputWindow.Seconds()
+	if elapsed := now.Sub(p.started); p.completed > 0 && elapsed > 0 {
+		remaining := p.users - p.completed - p.failed
+		if remaining < 0 {
+			remaining = 0
+		}
+		eta := float64(remaining) / (float64(p.completed) / elapsed.Seconds())
+		s.ETASeconds = &eta
+	}
+	return s
+}
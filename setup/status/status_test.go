@@ -0,0 +1,126 @@
+package status
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	// given
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+	tracker.started = now
+
+	tracker.PhaseStarted("signups", 100)
+	tracker.PhaseStarted("default", 10)
+	for i := 0; i < 20; i++ {
+		now = now.Add(5 * time.Second)
+		tracker.UserCompleted("signups", 2*time.Second)
+	}
+	tracker.UserFailed("signups")
+	tracker.UserCompleted("default", 30*time.Second)
+	tracker.PhaseCompleted("default")
+
+	t.Run("status", func(t *testing.T) {
+		// when
+		s := tracker.Status()
+
+		// then
+		assert.Equal(t, 100.0, s.ElapsedSeconds)
+		assert.Equal(t, 1, s.Failures)
+		assert.False(t, s.Interrupted)
+		require.Len(t, s.Phases, 2)
+		signups := s.Phases[0]
+		assert.Equal(t, Running, signups.State)
+		assert.Equal(t, 100, signups.Users)
+		assert.Equal(t, 20, signups.Completed)
+		assert.Equal(t, 1, signups.Failed)
+		assert.InDelta(t, 13.0/60, signups.Throughput, 0.001) // 13 users completed during the last minute
+		require.NotNil(t, signups.ETASeconds)
+		assert.InDelta(t, 395.0, *signups.ETASeconds, 0.001) // 79 users left at the average rate of 0.2 users per second
+		defaultPhase := s.Phases[1]
+		assert.Equal(t, Completed, defaultPhase.State)
+		assert.Equal(t, 1, defaultPhase.Completed)
+		require.NotNil(t, defaultPhase.EndedAt)
+		assert.Nil(t, defaultPhase.ETASeconds)
+	})
+
+	t.Run("throughput drops when no user is completed", func(t *testing.T) {
+		// given
+		now = now.Add(2 * time.Minute)
+
+		// when
+		s := tracker.Status()
+
+		// then
+		assert.Zero(t, s.Phases[0].Throughput)
+	})
+
+	t.Run("interrupted", func(t *testing.T) {
+		// when
+		tracker.Interrupted()
+
+		// then
+		assert.True(t, tracker.Status().Interrupted)
+	})
+}
+
+func TestServe(t *testing.T) {
+	// given
+	tracker := NewTracker()
+	tracker.PhaseStarted("signups", 10)
+	tracker.UserCompleted("signups", 3*time.Second)
+	tracker.UserFailed("signups")
+	server, err := Serve("127.0.0.1:0", tracker)
+	require.NoError(t, err)
+	defer server.Shutdown() // nolint:errcheck
+
+	t.Run("status", func(t *testing.T) {
+		// when
+		resp, err := http.Get("http://" + server.Addr + "/status")
+
+		// then
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		s := Status{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&s))
+		require.Len(t, s.Phases, 1)
+		assert.Equal(t, 1, s.Phases[0].Completed)
+		assert.Equal(t, 1, s.Failures)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		// when
+		resp, err := http.Get("http://" + server.Addr + "/metrics")
+
+		// then
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `toolchain_setup_phase_users{phase="signups"} 10`)
+		assert.Contains(t, string(body), `toolchain_setup_users_completed_total{phase="signups"} 1`)
+		assert.Contains(t, string(body), `toolchain_setup_users_failed_total{phase="signups"} 1`)
+		assert.Contains(t, string(body), `toolchain_setup_user_duration_seconds_bucket{phase="signups",le="4"} 1`)
+		assert.Contains(t, string(body), `toolchain_setup_user_duration_seconds_count{phase="signups"} 1`)
+	})
+
+	t.Run("address in use", func(t *testing.T) {
+		// when
+		_, err := Serve(server.Addr, tracker)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to listen on '"+server.Addr+"'")
+	})
+}
@@ -146,7 +146,33 @@ func applyObject(applycl *applyclientlib.ApplyClient, obj runtimeclient.Object,
 		}
 		return true, nil
 	}); err != nil {
-		return errors.Wrapf(err, "could not apply resource '%s' in namespace '%s'", obj.GetName(), obj.GetNamespace())
+		return &ApplyError{
+			Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			err:       errors.Wrapf(err, "could not apply resource '%s' in namespace '%s'", obj.GetName(), obj.GetNamespace()),
+		}
 	}
 	return nil
 }
+
+// ApplyError is returned when an object could not be applied, it identifies the object that failed
+type ApplyError struct {
+	Kind      string
+	Name      string
+	Namespace string
+	err       error
+}
+
+func (e *ApplyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ApplyError) Unwrap() error {
+	return errors.Cause(e.err)
+}
+
+// ObjectRef returns the kind, namespace and name of the object that could not be applied
+func (e *ApplyError) ObjectRef() string {
+	return fmt.Sprintf("%s %s/%s", e.Kind, e.Namespace, e.Name)
+}
@@ -0,0 +1,122 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format is the format of the messages of a terminal
+type Format string
+
+const (
+	// Text is the default format, with free-form messages for a human reader
+	Text Format = "text"
+	// JSON is the format of the structured log, one JSON object per line (JSON Lines) so that log processors can follow a run
+	JSON Format = "json"
+)
+
+// ParseFormat returns the format of the given name
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case Text, JSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown log format '%s', must be one of text or json", name)
+	}
+}
+
+// NewJSON returns a terminal that writes its messages and events as JSON Lines to the given `out` writer
+func NewJSON(in func() io.Reader, out func() io.Writer, verbose bool) Terminal {
+	return &JSONTerminal{
+		DefaultTerminal: DefaultTerminal{
+			in:      in,
+			out:     out,
+			verbose: verbose,
+		},
+		mu: &sync.Mutex{},
+	}
+}
+
+// JSONTerminal is a terminal that writes a JSON object per message or event, with its time, level and event type
+type JSONTerminal struct {
+	DefaultTerminal
+	// mu prevents the lines written by concurrent routines from being interleaved
+	mu *sync.Mutex
+}
+
+// Debugf writes a debug message (if verbose was enabled)
+func (t *JSONTerminal) Debugf(msg string, args ...interface{}) {
+	if !t.verbose {
+		return
+	}
+	t.write("debug", Event{Type: Log, Message: fmt.Sprintf(msg, args...)}, nil)
+}
+
+// Infof writes an info message
+func (t *JSONTerminal) Infof(msg string, args ...interface{}) {
+	if strings.TrimSpace(msg) == "" {
+		return
+	}
+	t.write("info", Event{Type: Log, Message: fmt.Sprintf(msg, args...)}, nil)
+}
+
+// Errorf writes an error message with the given error
+func (t *JSONTerminal) Errorf(err error, msg string, args ...interface{}) {
+	t.write("error", Event{Type: Log, Message: fmt.Sprintf(msg, args...)}, err)
+}
+
+// Fatalf writes an error message and exits the program with a `1` return code
+func (t *JSONTerminal) Fatalf(err error, msg string, args ...interface{}) {
+	defer os.Exit(1)
+	for _, hook := range t.fatalExitHooks {
+		hook()
+	}
+	t.Errorf(err, msg, args...)
+}
+
+// Event writes the event, the failures of users are written at the error level
+func (t *JSONTerminal) Event(e Event) {
+	level := "info"
+	if e.Type == UserFailed {
+		level = "error"
+	}
+	t.write(level, e, nil)
+}
+
+func (t *JSONTerminal) write(level string, e Event, err error) {
+	line := make(map[string]interface{}, len(e.Attributes)+7)
+	for k, v := range e.Attributes {
+		line[k] = v
+	}
+	line["time"] = time.Now().Format(time.RFC3339Nano)
+	line["level"] = level
+	line["event"] = e.Type
+	// the blank lines that lay out the text output are left out
+	if msg := strings.TrimSpace(e.Message); msg != "" {
+		line["msg"] = msg
+	}
+	if e.Phase != "" {
+		line["phase"] = e.Phase
+	}
+	if e.User != "" {
+		line["user"] = e.User
+	}
+	if e.Duration != 0 {
+		line["durationSeconds"] = e.Duration.Seconds()
+	}
+	if err != nil {
+		line["error"] = err.Error()
+	}
+	content, marshalErr := json.Marshal(line)
+	if marshalErr != nil {
+		content, _ = json.Marshal(map[string]interface{}{"time": line["time"], "level": "error", "event": Log, "msg": "unable to write event", "error": marshalErr.Error()}) // nolint:errchkjson
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.out().Write(append(content, '\n')) // nolint:errcheck
+}
@@ -0,0 +1,136 @@
+package terminal_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codeready-toolchain/toolchain-e2e/setup/terminal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	for name, expected := range map[string]terminal.Format{"text": terminal.Text, "json": terminal.JSON, "JSON": terminal.JSON} {
+		format, err := terminal.ParseFormat(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	_, err := terminal.ParseFormat("yaml")
+	require.EqualError(t, err, "unknown log format 'yaml', must be one of text or json")
+}
+
+func TestJSONTerminal(t *testing.T) {
+	newTerm := func(verbose bool) (terminal.Terminal, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return terminal.NewJSON(func() io.Reader { return strings.NewReader("") }, func() io.Writer { return out }, verbose), out
+	}
+
+	t.Run("messages", func(t *testing.T) {
+		// given
+		term, out := newTerm(false)
+
+		// when
+		term.Debugf("not verbose")
+		term.Infof("provisioning %d users", 10)
+		term.Errorf(fmt.Errorf("timeout"), "failed to write the %s file", "timings")
+
+		// then
+		lines := readLines(t, out)
+		require.Len(t, lines, 2)
+		assert.Equal(t, "info", lines[0]["level"])
+		assert.Equal(t, "log", lines[0]["event"])
+		assert.Equal(t, "provisioning 10 users", lines[0]["msg"])
+		assert.NotEmpty(t, lines[0]["time"])
+		assert.Equal(t, "error", lines[1]["level"])
+		assert.Equal(t, "failed to write the timings file", lines[1]["msg"])
+		assert.Equal(t, "timeout", lines[1]["error"])
+	})
+
+	t.Run("debug messages when verbose", func(t *testing.T) {
+		// given
+		term, out := newTerm(true)
+
+		// when
+		term.Debugf("user '%s' is recorded as completed", "zippy-0001")
+
+		// then
+		lines := readLines(t, out)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "debug", lines[0]["level"])
+	})
+
+	t.Run("events", func(t *testing.T) {
+		// given
+		term, out := newTerm(false)
+
+		// when
+		term.Event(terminal.Event{Type: terminal.UserCompleted, Phase: "signups", User: "zippy-0001", Duration: 1500 * time.Millisecond})
+		term.Event(terminal.Event{Type: terminal.UserFailed, Phase: "signups", User: "zippy-0002", Attributes: map[string]interface{}{"attempts": 3}})
+
+		// then
+		lines := readLines(t, out)
+		require.Len(t, lines, 2)
+		assert.Equal(t, "user-completed", lines[0]["event"])
+		assert.Equal(t, "info", lines[0]["level"])
+		assert.Equal(t, "signups", lines[0]["phase"])
+		assert.Equal(t, "zippy-0001", lines[0]["user"])
+		assert.Equal(t, 1.5, lines[0]["durationSeconds"])
+		assert.NotContains(t, lines[0], "msg")
+		assert.Equal(t, "user-failed", lines[1]["event"])
+		assert.Equal(t, "error", lines[1]["level"])
+		assert.Equal(t, 3.0, lines[1]["attempts"])
+	})
+
+	t.Run("concurrent events are not interleaved", func(t *testing.T) {
+		// given
+		term, out := newTerm(false)
+		var wg sync.WaitGroup
+
+		// when
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				term.Event(terminal.Event{Type: terminal.UserCompleted, User: fmt.Sprintf("zippy-%04d", i)})
+			}(i)
+		}
+		wg.Wait()
+
+		// then
+		assert.Len(t, readLines(t, out), 50)
+	})
+}
+
+func TestTextTerminalEvent(t *testing.T) {
+	// given
+	out := &bytes.Buffer{}
+	term := terminal.New(func() io.Reader { return strings.NewReader("") }, func() io.Writer { return out }, false)
+
+	// when
+	term.Event(terminal.Event{Type: terminal.UserCompleted, User: "zippy-0001"})
+	term.Event(terminal.Event{Type: terminal.OperatorInstall, Message: "Installing operator with subscription 'kiali'"})
+
+	// then
+	assert.Equal(t, "Installing operator with subscription 'kiali'\n", out.String())
+}
+
+func readLines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	lines := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		l := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &l), line)
+		lines = append(lines, l)
+	}
+	return lines
+}
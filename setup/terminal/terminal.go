@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
@@ -22,6 +23,41 @@ type Terminal interface {
 	Fatalf(err error, msg string, args ...interface{})
 	PromptBoolf(msg string, args ...interface{}) bool
 	AddPreFatalExitHook(func())
+	// Event records a step of a run, see the Event type
+	Event(e Event)
+}
+
+// EventType is the kind of an event of the structured log
+type EventType string
+
+const (
+	// Log is the event of a free-form message, ie. of Debugf, Infof and Errorf
+	Log EventType = "log"
+	// PhaseStarted and PhaseCompleted are the start and the end of a phase of the run
+	PhaseStarted   EventType = "phase-started"
+	PhaseCompleted EventType = "phase-completed"
+	// UserCompleted is a user that a phase processed successfully, with the time spent on the user
+	UserCompleted EventType = "user-completed"
+	// UserFailed is a user that a phase failed to process, when the failure policy allows failures
+	UserFailed EventType = "user-failed"
+	// OperatorInstall is a step of the installation of an operator
+	OperatorInstall EventType = "operator-install"
+	// OperatorUninstall is the removal of an operator by the teardown
+	OperatorUninstall EventType = "operator-uninstall"
+	// MetricsSample is a sample of a metrics query
+	MetricsSample EventType = "metrics-sample"
+)
+
+// Event is a step of a run
+type Event struct {
+	Type EventType
+	// Message is displayed by the text terminal, an event without a message is only emitted by the JSON terminal
+	Message  string
+	Phase    string
+	User     string
+	Duration time.Duration
+	// Attributes are the other fields of the event, eg. the query and the value of a metrics sample
+	Attributes map[string]interface{}
 }
 
 // New returns a new terminal with the given funcs to
@@ -107,6 +143,14 @@ func (t DefaultTerminal) PromptBoolf(msg string, args ...interface{}) bool {
 	return strings.ToLower(result) == "y"
 }
 
+// Event displays the message of the event, the events without a message are only emitted by the JSON terminal
+func (t DefaultTerminal) Event(e Event) {
+	if e.Message == "" {
+		return
+	}
+	t.Infof("%s", e.Message)
+}
+
 func (t *DefaultTerminal) AddPreFatalExitHook(hook func()) {
 	t.fatalExitHooks = append(t.fatalExitHooks, hook)
 }
@@ -0,0 +1,146 @@
+package tiers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Distribution assigns the users to the tiers in proportion to their percentages, the tier of a user only depends on its number
+// so that a resumed run assigns the users to the same tiers
+type Distribution struct {
+	// names and percentages are the tiers in the order of the distribution
+	names       []string
+	percentages []int
+	// cycle is the sequence of tiers that the users are assigned to in turn
+	cycle []string
+	// devNamespace are the tiers that have a dev namespace, the namespace that the idlers and the templates of the users apply to
+	devNamespace map[string]bool
+}
+
+// ParseDistribution parses a list of tiers with their percentages such as 'base1ns=70%,baselarge=20%,appstudio=10%', the
+// percentages must add up to 100. A single tier may omit its percentage.
+func ParseDistribution(value string) (*Distribution, error) {
+	d := &Distribution{}
+	entries := strings.Split(value, ",")
+	total := 0
+	for _, entry := range entries {
+		name, percentage, hasPercentage := strings.Cut(strings.TrimSpace(entry), "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid tier distribution '%s', must be a list of tiers with their percentages eg. 'base1ns=70%%,baselarge=20%%,appstudio=10%%'", value)
+		}
+		p := 100
+		if hasPercentage {
+			var err error
+			if p, err = strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(percentage), "%")); err != nil || p <= 0 || p > 100 {
+				return nil, fmt.Errorf("invalid percentage '%s' of tier '%s', must be an integer between 1 and 100", percentage, name)
+			}
+		} else if len(entries) > 1 {
+			return nil, fmt.Errorf("invalid tier distribution '%s', tier '%s' has no percentage", value, name)
+		}
+		for _, n := range d.names {
+			if n == name {
+				return nil, fmt.Errorf("invalid tier distribution '%s', tier '%s' is listed more than once", value, name)
+			}
+		}
+		d.names = append(d.names, name)
+		d.percentages = append(d.percentages, p)
+		total += p
+	}
+	if total != 100 {
+		return nil, fmt.Errorf("invalid tier distribution '%s', the percentages add up to %d%% instead of 100%%", value, total)
+	}
+	d.cycle = weightedCycle(d.names, d.percentages)
+	return d, nil
+}
+
+// weightedCycle returns a sequence in which each tier appears as many times as its percentage, reduced by their greatest common
+// divisor. The tiers are interleaved as evenly as possible, with the smooth weighted round-robin of nginx.
+func weightedCycle(names []string, percentages []int) []string {
+	divisor := 0
+	for _, p := range percentages {
+		divisor = gcd(divisor, p)
+	}
+	weights := make([]int, len(percentages))
+	total := 0
+	for i, p := range percentages {
+		weights[i] = p / divisor
+		total += weights[i]
+	}
+	cycle := make([]string, 0, total)
+	current := make([]int, len(names))
+	for len(cycle) < total {
+		for i := range names {
+			current[i] += weights[i]
+		}
+		best := 0
+		for i := range names {
+			if current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		cycle = append(cycle, names[best])
+	}
+	return cycle
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Tier returns the tier that the user with the given number, starting at 1, is assigned to
+func (d *Distribution) Tier(userNumber int) string {
+	return d.cycle[(userNumber-1)%len(d.cycle)]
+}
+
+// Tiers returns the tiers of the distribution
+func (d *Distribution) Tiers() []string {
+	return d.names
+}
+
+// Bind looks up the tiers of the distribution in the given namespace, to tell which ones have a dev namespace. It returns an error
+// if a tier of the distribution does not exist.
+func (d *Distribution) Bind(ctx context.Context, cl client.Client, hostOperatorNamespace string) error {
+	d.devNamespace = map[string]bool{}
+	for _, name := range d.names {
+		tier := &toolchainv1alpha1.NSTemplateTier{}
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: hostOperatorNamespace, Name: name}, tier); err != nil {
+			return errors.Wrapf(err, "unable to get tier '%s'", name)
+		}
+		for _, ns := range tier.Spec.Namespaces {
+			tierTemplate := &toolchainv1alpha1.TierTemplate{}
+			if err := cl.Get(ctx, types.NamespacedName{Namespace: hostOperatorNamespace, Name: ns.TemplateRef}, tierTemplate); err != nil {
+				return errors.Wrapf(err, "unable to get tier template '%s' of tier '%s'", ns.TemplateRef, name)
+			}
+			if tierTemplate.Spec.Type == "dev" {
+				d.devNamespace[name] = true
+			}
+		}
+	}
+	return nil
+}
+
+// HasDevNamespace returns true if the tier of the user with the given number, starting at 1, has a dev namespace
+func (d *Distribution) HasDevNamespace(userNumber int) bool {
+	return d.devNamespace[d.Tier(userNumber)]
+}
+
+// String returns how the users are distributed, eg. 'base1ns=70%, baselarge=20%, appstudio=10%'
+func (d *Distribution) String() string {
+	entries := make([]string, 0, len(d.names))
+	for i, name := range d.names {
+		entries = append(entries, fmt.Sprintf("%s=%d%%", name, d.percentages[i]))
+	}
+	return strings.Join(entries, ", ")
+}
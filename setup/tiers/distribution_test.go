@@ -0,0 +1,133 @@
+package tiers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestDistribution(t *testing.T) {
+	// given
+	tiers := func(d *Distribution, users int) map[string]int {
+		counts := map[string]int{}
+		for n := 1; n <= users; n++ {
+			counts[d.Tier(n)]++
+		}
+		return counts
+	}
+
+	t.Run("percentages", func(t *testing.T) {
+		// when
+		d, err := ParseDistribution("base1ns=70%, baselarge=20%,appstudio=10%")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"base1ns", "baselarge", "appstudio"}, d.Tiers())
+		assert.Equal(t, map[string]int{"base1ns": 70, "baselarge": 20, "appstudio": 10}, tiers(d, 100))
+		assert.Equal(t, map[string]int{"base1ns": 7, "baselarge": 2, "appstudio": 1}, tiers(d, 10))
+		// the tiers are interleaved rather than assigned in blocks
+		cycle := []string{}
+		for n := 1; n <= 10; n++ {
+			cycle = append(cycle, d.Tier(n))
+		}
+		assert.Equal(t, []string{"base1ns", "base1ns", "baselarge", "base1ns", "base1ns", "appstudio", "base1ns", "base1ns", "baselarge", "base1ns"}, cycle)
+		assert.Equal(t, d.Tier(1), d.Tier(11))
+		assert.Equal(t, "base1ns=70%, baselarge=20%, appstudio=10%", d.String())
+	})
+
+	t.Run("percentages without percent sign", func(t *testing.T) {
+		// when
+		d, err := ParseDistribution("base=50,baselarge=50")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"base", "baselarge", "base", "baselarge"}, []string{d.Tier(1), d.Tier(2), d.Tier(3), d.Tier(4)})
+	})
+
+	t.Run("single tier", func(t *testing.T) {
+		// when
+		d, err := ParseDistribution("appstudio")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"appstudio": 10}, tiers(d, 10))
+		assert.Equal(t, "appstudio=100%", d.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for value, msg := range map[string]string{
+			"":                          "invalid tier distribution '', must be a list of tiers with their percentages",
+			"base1ns=70%,baselarge=20%": "the percentages add up to 90% instead of 100%",
+			"base1ns=70%,baselarge":     "tier 'baselarge' has no percentage",
+			"base1ns=half,baselarge=50": "invalid percentage 'half' of tier 'base1ns'",
+			"base1ns=0%,baselarge=100%": "invalid percentage '0%' of tier 'base1ns'",
+			"base1ns=50%,base1ns=50%":   "tier 'base1ns' is listed more than once",
+			"base1ns=50%,=50%":          "must be a list of tiers with their percentages",
+		} {
+			t.Run(value, func(t *testing.T) {
+				// when
+				_, err := ParseDistribution(value)
+
+				// then
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), msg)
+			})
+		}
+	})
+}
+
+func TestBind(t *testing.T) {
+	// given
+	hostOperatorNamespace := "toolchain-host-operator"
+	tier := func(name string, namespaceTypes ...string) []runtime.Object {
+		objs := []runtime.Object{}
+		nsTemplateTier := &toolchainv1alpha1.NSTemplateTier{ObjectMeta: metav1.ObjectMeta{Namespace: hostOperatorNamespace, Name: name}}
+		for _, namespaceType := range namespaceTypes {
+			templateRef := fmt.Sprintf("%s-%s-123456a", name, namespaceType)
+			nsTemplateTier.Spec.Namespaces = append(nsTemplateTier.Spec.Namespaces, toolchainv1alpha1.NSTemplateTierNamespace{TemplateRef: templateRef})
+			objs = append(objs, &toolchainv1alpha1.TierTemplate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: hostOperatorNamespace, Name: templateRef},
+				Spec:       toolchainv1alpha1.TierTemplateSpec{TierName: name, Type: namespaceType},
+			})
+		}
+		return append(objs, nsTemplateTier)
+	}
+	cl := commontest.NewFakeClient(t, append(tier("base1ns", "dev"), tier("appstudio", "tenant")...)...)
+
+	t.Run("all tiers exist", func(t *testing.T) {
+		// given
+		d, err := ParseDistribution("base1ns=50%,appstudio=50%")
+		require.NoError(t, err)
+
+		// when
+		err = d.Bind(context.TODO(), cl, hostOperatorNamespace)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "base1ns", d.Tier(1))
+		assert.True(t, d.HasDevNamespace(1))
+		assert.Equal(t, "appstudio", d.Tier(2))
+		assert.False(t, d.HasDevNamespace(2))
+	})
+
+	t.Run("unknown tier", func(t *testing.T) {
+		// given
+		d, err := ParseDistribution("base1ns=90%,baselarge=10%")
+		require.NoError(t, err)
+
+		// when
+		err = d.Bind(context.TODO(), cl, hostOperatorNamespace)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to get tier 'baselarge'")
+	})
+}
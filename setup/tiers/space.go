@@ -0,0 +1,80 @@
+package tiers
+
+import (
+	"context"
+	"fmt"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8swait "k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CreateSpace creates the Space of the given user in the given tier on the given member cluster, along with the SpaceBinding
+// that grants the user the admin role. The UserSignup of the user must skip the creation of its Space, the Space is created once
+// the host operator has created the MasterUserRecord of the user. The host operator picks the member cluster when the target
+// cluster is empty. The resources that already exist, eg. in a resumed run, are left as they are.
+func CreateSpace(ctx context.Context, cl client.Client, username, hostOperatorNamespace, tierName, targetCluster string) error {
+	mur := &toolchainv1alpha1.MasterUserRecord{}
+	if err := k8swait.PollWithContext(ctx, configuration.DefaultRetryInterval, configuration.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: hostOperatorNamespace, Name: username}, mur); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}); err != nil {
+		return errors.Wrapf(err, "masteruserrecord '%s' was not found", username)
+	}
+
+	space := &toolchainv1alpha1.Space{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hostOperatorNamespace,
+			Name:      username,
+			Labels: map[string]string{
+				toolchainv1alpha1.SpaceCreatorLabelKey: username,
+			},
+		},
+		Spec: toolchainv1alpha1.SpaceSpec{
+			TierName:      tierName,
+			TargetCluster: targetCluster,
+		},
+	}
+	if err := cl.Create(ctx, space); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "unable to create space '%s'", username)
+	}
+
+	// the SpaceBindings have a generated name, so an existing one is looked up by its labels
+	spaceBindings := &toolchainv1alpha1.SpaceBindingList{}
+	if err := cl.List(ctx, spaceBindings, client.InNamespace(hostOperatorNamespace), client.MatchingLabels{
+		toolchainv1alpha1.SpaceBindingMasterUserRecordLabelKey: mur.Name,
+		toolchainv1alpha1.SpaceBindingSpaceLabelKey:            space.Name,
+	}); err != nil {
+		return errors.Wrapf(err, "unable to list the spacebindings of space '%s'", username)
+	}
+	if len(spaceBindings.Items) > 0 {
+		return nil
+	}
+	spaceBinding := &toolchainv1alpha1.SpaceBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    hostOperatorNamespace,
+			GenerateName: fmt.Sprintf("%s-%s-", mur.Name, space.Name),
+			Labels: map[string]string{
+				toolchainv1alpha1.SpaceBindingMasterUserRecordLabelKey: mur.Name,
+				toolchainv1alpha1.SpaceBindingSpaceLabelKey:            space.Name,
+			},
+		},
+		Spec: toolchainv1alpha1.SpaceBindingSpec{
+			MasterUserRecord: mur.Name,
+			Space:            space.Name,
+			SpaceRole:        "admin",
+		},
+	}
+	return errors.Wrapf(cl.Create(ctx, spaceBinding), "unable to create the spacebinding of space '%s'", username)
+}
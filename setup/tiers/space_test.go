@@ -0,0 +1,76 @@
+package tiers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCreateSpace(t *testing.T) {
+	// given
+	defaultTimeout := configuration.DefaultTimeout
+	t.Cleanup(func() {
+		configuration.DefaultTimeout = defaultTimeout
+	})
+	configuration.DefaultTimeout = time.Second * 2
+	hostOperatorNamespace := "toolchain-host-operator"
+	mur := &toolchainv1alpha1.MasterUserRecord{
+		ObjectMeta: metav1.ObjectMeta{Namespace: hostOperatorNamespace, Name: "user-0001"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, mur.DeepCopy())
+
+		// when
+		err := CreateSpace(context.TODO(), cl, "user-0001", hostOperatorNamespace, "baselarge", "member-abcd")
+
+		// then
+		require.NoError(t, err)
+		space := &toolchainv1alpha1.Space{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: "user-0001"}, space))
+		assert.Equal(t, "baselarge", space.Spec.TierName)
+		assert.Equal(t, "member-abcd", space.Spec.TargetCluster)
+		assert.Equal(t, "user-0001", space.Labels[toolchainv1alpha1.SpaceCreatorLabelKey])
+		spaceBindings := &toolchainv1alpha1.SpaceBindingList{}
+		require.NoError(t, cl.List(context.TODO(), spaceBindings, client.InNamespace(hostOperatorNamespace)))
+		require.Len(t, spaceBindings.Items, 1)
+		assert.Equal(t, "user-0001", spaceBindings.Items[0].Spec.MasterUserRecord)
+		assert.Equal(t, "user-0001", spaceBindings.Items[0].Spec.Space)
+		assert.Equal(t, "admin", spaceBindings.Items[0].Spec.SpaceRole)
+
+		t.Run("already created", func(t *testing.T) {
+			// when
+			err := CreateSpace(context.TODO(), cl, "user-0001", hostOperatorNamespace, "baselarge", "member-abcd")
+
+			// then
+			require.NoError(t, err)
+			require.NoError(t, cl.List(context.TODO(), spaceBindings, client.InNamespace(hostOperatorNamespace)))
+			assert.Len(t, spaceBindings.Items, 1)
+		})
+	})
+
+	t.Run("masteruserrecord not created", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t)
+
+		// when
+		err := CreateSpace(context.TODO(), cl, "user-0001", hostOperatorNamespace, "baselarge", "")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "masteruserrecord 'user-0001' was not found")
+		err = cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: "user-0001"}, &toolchainv1alpha1.Space{})
+		assert.Error(t, err)
+	})
+}
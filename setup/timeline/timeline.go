@@ -0,0 +1,185 @@
+package timeline
+
+import (
+	"context"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/condition"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/latency"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/results"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Event is a step of the provisioning of a user, as recorded by the cluster
+type Event string
+
+const (
+	UserSignupCreated        Event = "UserSignup created"
+	UserSignupApproved       Event = "UserSignup approved"
+	MasterUserRecordCreated  Event = "MasterUserRecord created"
+	UserAccountReady         Event = "UserAccount ready"
+	NSTemplateSetProvisioned Event = "NSTemplateSet provisioned"
+	SpaceReady               Event = "Space ready"
+)
+
+// Stage is the time between two events
+type Stage struct {
+	Name string
+	From Event
+	To   Event
+}
+
+// Stages are the stages of the provisioning of a user, in order, followed by the whole provisioning
+var Stages = []Stage{
+	{Name: "Signup Approval", From: UserSignupCreated, To: UserSignupApproved},
+	{Name: "MasterUserRecord Creation", From: UserSignupApproved, To: MasterUserRecordCreated},
+	{Name: "UserAccount Provisioning", From: MasterUserRecordCreated, To: UserAccountReady},
+	{Name: "NSTemplateSet Provisioning", From: UserAccountReady, To: NSTemplateSetProvisioned},
+	{Name: "Space Readiness", From: NSTemplateSetProvisioned, To: SpaceReady},
+	{Name: "Total Provisioning", From: UserSignupCreated, To: SpaceReady},
+}
+
+// Timeline is the time of each provisioning event of a user, events that could not be found are missing
+type Timeline struct {
+	Username string
+	Events   map[Event]time.Time
+}
+
+// Duration returns the duration of the given stage, it returns false if one of the events of the stage is missing
+func (t Timeline) Duration(s Stage) (time.Duration, bool) {
+	from, found := t.Events[s.From]
+	if !found {
+		return 0, false
+	}
+	to, found := t.Events[s.To]
+	if !found {
+		return 0, false
+	}
+	// the creation timestamps are set by the API server while the conditions are set with the clock of the operators,
+	// a small skew is not reported as a negative duration
+	if to.Before(from) {
+		return 0, true
+	}
+	return to.Sub(from), true
+}
+
+// Collect rebuilds the provisioning timeline of the given users from the creation timestamps and the lastTransitionTime
+// of the conditions of their resources, rather than from the client clock. The member resources are looked up in the namespaces
+// of all the given member operators.
+func Collect(cl client.Client, hostNS string, memberNamespaces []string, usernames []string) ([]Timeline, error) {
+	signups := &toolchainv1alpha1.UserSignupList{}
+	if err := cl.List(context.TODO(), signups, client.InNamespace(hostNS)); err != nil {
+		return nil, errors.Wrap(err, "unable to list the UserSignups")
+	}
+	murs := &toolchainv1alpha1.MasterUserRecordList{}
+	if err := cl.List(context.TODO(), murs, client.InNamespace(hostNS)); err != nil {
+		return nil, errors.Wrap(err, "unable to list the MasterUserRecords")
+	}
+	spaces := &toolchainv1alpha1.SpaceList{}
+	if err := cl.List(context.TODO(), spaces, client.InNamespace(hostNS)); err != nil {
+		return nil, errors.Wrap(err, "unable to list the Spaces")
+	}
+	userAccounts := &toolchainv1alpha1.UserAccountList{}
+	nsTemplateSets := &toolchainv1alpha1.NSTemplateSetList{}
+	for _, memberNS := range memberNamespaces {
+		memberUserAccounts := &toolchainv1alpha1.UserAccountList{}
+		if err := cl.List(context.TODO(), memberUserAccounts, client.InNamespace(memberNS)); err != nil {
+			return nil, errors.Wrapf(err, "unable to list the UserAccounts of '%s'", memberNS)
+		}
+		userAccounts.Items = append(userAccounts.Items, memberUserAccounts.Items...)
+		memberNSTemplateSets := &toolchainv1alpha1.NSTemplateSetList{}
+		if err := cl.List(context.TODO(), memberNSTemplateSets, client.InNamespace(memberNS)); err != nil {
+			return nil, errors.Wrapf(err, "unable to list the NSTemplateSets of '%s'", memberNS)
+		}
+		nsTemplateSets.Items = append(nsTemplateSets.Items, memberNSTemplateSets.Items...)
+	}
+
+	signupsByName := map[string]toolchainv1alpha1.UserSignup{}
+	for _, s := range signups.Items {
+		signupsByName[s.Name] = s
+	}
+	mursByName := map[string]toolchainv1alpha1.MasterUserRecord{}
+	for _, m := range murs.Items {
+		mursByName[m.Name] = m
+	}
+	spacesByName := map[string]toolchainv1alpha1.Space{}
+	for _, s := range spaces.Items {
+		spacesByName[s.Name] = s
+	}
+	userAccountsByName := map[string]toolchainv1alpha1.UserAccount{}
+	for _, ua := range userAccounts.Items {
+		userAccountsByName[ua.Name] = ua
+	}
+	nsTemplateSetsByName := map[string]toolchainv1alpha1.NSTemplateSet{}
+	for _, nsts := range nsTemplateSets.Items {
+		nsTemplateSetsByName[nsts.Name] = nsts
+	}
+
+	timelines := make([]Timeline, 0, len(usernames))
+	for _, username := range usernames {
+		t := Timeline{
+			Username: username,
+			Events:   map[Event]time.Time{},
+		}
+		signup, found := signupsByName[username]
+		if !found {
+			timelines = append(timelines, t)
+			continue
+		}
+		t.Events[UserSignupCreated] = signup.CreationTimestamp.Time
+		t.addCondition(UserSignupApproved, signup.Status.Conditions, toolchainv1alpha1.UserSignupApproved)
+
+		// the MasterUserRecord, the UserAccount, the Space and the NSTemplateSet are all named after the compliant username
+		name := signup.Status.CompliantUsername
+		if name == "" {
+			name = username
+		}
+		if mur, found := mursByName[name]; found {
+			t.Events[MasterUserRecordCreated] = mur.CreationTimestamp.Time
+		}
+		if ua, found := userAccountsByName[name]; found {
+			t.addCondition(UserAccountReady, ua.Status.Conditions, toolchainv1alpha1.ConditionReady)
+		}
+		if nsts, found := nsTemplateSetsByName[name]; found {
+			t.addCondition(NSTemplateSetProvisioned, nsts.Status.Conditions, toolchainv1alpha1.ConditionReady)
+		}
+		if space, found := spacesByName[name]; found {
+			t.addCondition(SpaceReady, space.Status.Conditions, toolchainv1alpha1.ConditionReady)
+		}
+		timelines = append(timelines, t)
+	}
+	return timelines, nil
+}
+
+// addCondition records the event at the lastTransitionTime of the given condition if it is true
+func (t Timeline) addCondition(event Event, conditions []toolchainv1alpha1.Condition, conditionType toolchainv1alpha1.ConditionType) {
+	c, found := condition.FindConditionByType(conditions, conditionType)
+	if !found || c.Status != corev1.ConditionTrue || c.LastTransitionTime.IsZero() {
+		return
+	}
+	t.Events[event] = c.LastTransitionTime.Time
+}
+
+// Summarize returns the p50, p90, p99 and max duration of each stage, in seconds
+func Summarize(timelines []Timeline) []results.Result {
+	summary := []results.Result{}
+	for _, s := range Stages {
+		d := &latency.Distribution{}
+		for _, t := range timelines {
+			if duration, ok := t.Duration(s); ok {
+				d.Add(duration)
+			}
+		}
+		summary = append(summary,
+			latency.Result(s.Name, results.P50, "", d.Percentile(50)),
+			latency.Result(s.Name, results.P90, "", d.Percentile(90)),
+			latency.Result(s.Name, results.P99, "", d.Percentile(99)),
+			latency.Result(s.Name, results.Max, "", d.Max()),
+		)
+	}
+	return summary
+}
@@ -0,0 +1,151 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	hostNS   = "toolchain-host-operator"
+	memberNS = "toolchain-member-operator"
+	// member2NS is the namespace of the operator of a second member cluster
+	member2NS = "toolchain-member2-operator"
+)
+
+func TestCollect(t *testing.T) {
+	// given
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+	cl := commontest.NewFakeClient(t,
+		&toolchainv1alpha1.UserSignup{
+			ObjectMeta: metav1.ObjectMeta{Name: "zippy-0001", Namespace: hostNS, CreationTimestamp: metav1.NewTime(at(0))},
+			Status: toolchainv1alpha1.UserSignupStatus{
+				CompliantUsername: "zippy-0001",
+				Conditions:        []toolchainv1alpha1.Condition{readyCondition(toolchainv1alpha1.UserSignupApproved, at(1))},
+			},
+		},
+		&toolchainv1alpha1.MasterUserRecord{
+			ObjectMeta: metav1.ObjectMeta{Name: "zippy-0001", Namespace: hostNS, CreationTimestamp: metav1.NewTime(at(2))},
+		},
+		&toolchainv1alpha1.UserAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "zippy-0001", Namespace: memberNS},
+			Status: toolchainv1alpha1.UserAccountStatus{
+				Conditions: []toolchainv1alpha1.Condition{readyCondition(toolchainv1alpha1.ConditionReady, at(4))},
+			},
+		},
+		// the resources of the member clusters are looked up in the namespaces of all the member operators
+		&toolchainv1alpha1.NSTemplateSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "zippy-0001", Namespace: member2NS},
+			Status: toolchainv1alpha1.NSTemplateSetStatus{
+				Conditions: []toolchainv1alpha1.Condition{readyCondition(toolchainv1alpha1.ConditionReady, at(10))},
+			},
+		},
+		&toolchainv1alpha1.Space{
+			ObjectMeta: metav1.ObjectMeta{Name: "zippy-0001", Namespace: hostNS},
+			Status: toolchainv1alpha1.SpaceStatus{
+				Conditions: []toolchainv1alpha1.Condition{readyCondition(toolchainv1alpha1.ConditionReady, at(11))},
+			},
+		},
+		// the second user is not approved yet
+		&toolchainv1alpha1.UserSignup{
+			ObjectMeta: metav1.ObjectMeta{Name: "zippy-0002", Namespace: hostNS, CreationTimestamp: metav1.NewTime(at(5))},
+		},
+	)
+
+	// when
+	timelines, err := Collect(cl, hostNS, []string{memberNS, member2NS}, []string{"zippy-0001", "zippy-0002", "zippy-0003"})
+
+	// then
+	require.NoError(t, err)
+	require.Len(t, timelines, 3)
+	assert.Equal(t, map[Event]time.Time{
+		UserSignupCreated:        at(0),
+		UserSignupApproved:       at(1),
+		MasterUserRecordCreated:  at(2),
+		UserAccountReady:         at(4),
+		NSTemplateSetProvisioned: at(10),
+		SpaceReady:               at(11),
+	}, utc(timelines[0].Events))
+	assert.Equal(t, map[Event]time.Time{
+		UserSignupCreated: at(5),
+	}, utc(timelines[1].Events))
+	assert.Empty(t, timelines[2].Events)
+
+	t.Run("summarize", func(t *testing.T) {
+		// when
+		results := Summarize(timelines)
+
+		// then
+		require.Len(t, results, len(Stages)*4)
+		assert.Equal(t, []string{"Signup Approval p50 (s)", "1.00"}, []string{results[0].Title(), results[0].FormattedValue()})
+		assert.Equal(t, []string{"Max UserAccount Provisioning (s)", "2.00"}, []string{results[11].Title(), results[11].FormattedValue()})
+		assert.Equal(t, []string{"NSTemplateSet Provisioning p50 (s)", "6.00"}, []string{results[12].Title(), results[12].FormattedValue()})
+		assert.Equal(t, []string{"Total Provisioning p99 (s)", "11.00"}, []string{results[22].Title(), results[22].FormattedValue()})
+	})
+}
+
+func TestDuration(t *testing.T) {
+	start := time.Now()
+	stage := Stage{Name: "Signup Approval", From: UserSignupCreated, To: UserSignupApproved}
+
+	t.Run("complete", func(t *testing.T) {
+		// given
+		tl := Timeline{Events: map[Event]time.Time{UserSignupCreated: start, UserSignupApproved: start.Add(time.Second)}}
+
+		// when
+		d, ok := tl.Duration(stage)
+
+		// then
+		assert.True(t, ok)
+		assert.Equal(t, time.Second, d)
+	})
+
+	t.Run("clock skew", func(t *testing.T) {
+		// given
+		tl := Timeline{Events: map[Event]time.Time{UserSignupCreated: start, UserSignupApproved: start.Add(-time.Second)}}
+
+		// when
+		d, ok := tl.Duration(stage)
+
+		// then
+		assert.True(t, ok)
+		assert.Equal(t, time.Duration(0), d)
+	})
+
+	t.Run("missing event", func(t *testing.T) {
+		// given
+		tl := Timeline{Events: map[Event]time.Time{UserSignupCreated: start}}
+
+		// when
+		_, ok := tl.Duration(stage)
+
+		// then
+		assert.False(t, ok)
+	})
+}
+
+func readyCondition(conditionType toolchainv1alpha1.ConditionType, lastTransitionTime time.Time) toolchainv1alpha1.Condition {
+	return toolchainv1alpha1.Condition{
+		Type:               conditionType,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(lastTransitionTime),
+	}
+}
+
+func utc(events map[Event]time.Time) map[Event]time.Time {
+	converted := map[Event]time.Time{}
+	for e, t := range events {
+		converted[e] = t.UTC()
+	}
+	return converted
+}
@@ -7,21 +7,25 @@ import (
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
 	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
 	"github.com/codeready-toolchain/toolchain-common/pkg/states"
-	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
-	"github.com/codeready-toolchain/toolchain-e2e/testsupport/wait"
 
+	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	k8swait "k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-var memberClusterName string
+// Create creates an approved UserSignup for the given user on the given member cluster, the host operator picks the member
+// cluster when the target cluster is empty
+func Create(ctx context.Context, cl client.Client, username, hostOperatorNamespace, targetCluster string) error {
+	return create(ctx, cl, username, hostOperatorNamespace, targetCluster, false)
+}
 
-func Create(cl client.Client, username, hostOperatorNamespace, memberOperatorNamespace string) error {
-	memberClusterName, err := getMemberClusterName(cl, hostOperatorNamespace, memberOperatorNamespace)
-	if err != nil {
-		return fmt.Errorf("unable to lookup member cluster name, ensure the sandbox setup steps are followed")
-	}
+// CreateWithoutSpace creates an approved UserSignup like Create, but the host operator does not create the Space of the user
+// so that it can be created in another tier than the default one
+func CreateWithoutSpace(ctx context.Context, cl client.Client, username, hostOperatorNamespace, targetCluster string) error {
+	return create(ctx, cl, username, hostOperatorNamespace, targetCluster, true)
+}
+
+func create(ctx context.Context, cl client.Client, username, hostOperatorNamespace, targetCluster string, skipSpace bool) error {
 	usersignup := &toolchainv1alpha1.UserSignup{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: hostOperatorNamespace,
@@ -36,46 +40,41 @@ func Create(cl client.Client, username, hostOperatorNamespace, memberOperatorNam
 		Spec: toolchainv1alpha1.UserSignupSpec{
 			Username:      username,
 			Userid:        username,
-			TargetCluster: memberClusterName,
+			TargetCluster: targetCluster,
 		},
 	}
+	if skipSpace {
+		usersignup.Annotations[toolchainv1alpha1.SkipAutoCreateSpaceAnnotationKey] = "true"
+	}
 	states.SetApprovedManually(usersignup, true)
 
-	return cl.Create(context.TODO(), usersignup)
+	return cl.Create(ctx, usersignup)
 }
 
-func getMemberClusterName(cl client.Client, hostOperatorNamespace, memberOperatorNamespace string) (string, error) {
-	if memberClusterName != "" {
-		return memberClusterName, nil
-	}
-	var memberCluster toolchainv1alpha1.ToolchainCluster
-	err := k8swait.Poll(configuration.DefaultRetryInterval, configuration.DefaultTimeout, func() (bool, error) {
-		clusters := &toolchainv1alpha1.ToolchainClusterList{}
-		if err := cl.List(context.TODO(), clusters, client.InNamespace(hostOperatorNamespace), client.MatchingLabels{
-			"namespace": memberOperatorNamespace,
-			"type":      "member",
-		}); err != nil {
-			return false, err
-		}
-		for _, cluster := range clusters.Items {
-			if containsClusterCondition(cluster.Status.Conditions, wait.ReadyToolchainCluster) {
-				memberCluster = cluster
-				return true, nil
-			}
-		}
-		return false, nil
-	})
-	return memberCluster.Name, err
+// Approve approves the UserSignup of the given user, and sets its target cluster unless it is empty. It waits until the UserSignup
+// exists, eg. when it is created by the registration service, or until the context is done.
+func Approve(ctx context.Context, cl client.Client, username, hostOperatorNamespace, targetCluster string) error {
+	return approve(ctx, cl, username, hostOperatorNamespace, targetCluster, false)
 }
 
-func containsClusterCondition(conditions []toolchainv1alpha1.ToolchainClusterCondition, contains *toolchainv1alpha1.ToolchainClusterCondition) bool {
-	if contains == nil {
-		return true
-	}
-	for _, c := range conditions {
-		if c.Type == contains.Type {
-			return contains.Status == c.Status
+// ApproveWithoutSpace approves the UserSignup of the given user like Approve, but the host operator does not create the Space of
+// the user so that it can be created in another tier than the default one
+func ApproveWithoutSpace(ctx context.Context, cl client.Client, username, hostOperatorNamespace, targetCluster string) error {
+	return approve(ctx, cl, username, hostOperatorNamespace, targetCluster, true)
+}
+
+func approve(ctx context.Context, cl client.Client, username, hostOperatorNamespace, targetCluster string, skipSpace bool) error {
+	err := update(ctx, cl, username, hostOperatorNamespace, func(usersignup *toolchainv1alpha1.UserSignup) {
+		if skipSpace {
+			if usersignup.Annotations == nil {
+				usersignup.Annotations = map[string]string{}
+			}
+			usersignup.Annotations[toolchainv1alpha1.SkipAutoCreateSpaceAnnotationKey] = "true"
 		}
-	}
-	return false
+		states.SetApprovedManually(usersignup, true)
+		if targetCluster != "" {
+			usersignup.Spec.TargetCluster = targetCluster
+		}
+	})
+	return errors.Wrapf(err, "unable to approve usersignup '%s'", username)
 }
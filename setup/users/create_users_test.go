@@ -1,77 +1,123 @@
 package users
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/states"
 	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestCreate(t *testing.T) {
-
 	// given
-	configuration.DefaultTimeout = time.Second * 5
+	hostOperatorNamespace := "toolchain-host-operator"
 
 	t.Run("success", func(t *testing.T) {
 		// given
-		// needed otherwise the following tests can't verify what happens when `memberClusterName` cannot be retrieved
-		t.Cleanup(func() {
-			memberClusterName = ""
-		})
-		hostOperatorNamespace := "toolchain-host-operator"
-		memberOperatorNamespace := "toolchain-member-operator"
-		memberCluster := &toolchainv1alpha1.ToolchainCluster{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: hostOperatorNamespace,
-				Name:      "member-abcd",
-				Labels: map[string]string{
-					"namespace": memberOperatorNamespace,
-					"type":      "member",
-				},
-			},
-			Status: toolchainv1alpha1.ToolchainClusterStatus{
-				Conditions: []toolchainv1alpha1.ToolchainClusterCondition{
-					{
-						Type:   toolchainv1alpha1.ToolchainClusterReady,
-						Status: corev1.ConditionTrue,
-					},
-				},
-			},
-		}
-		cl := commontest.NewFakeClient(t, memberCluster)
+		cl := commontest.NewFakeClient(t)
 		username := "user-0001"
 
 		// when
-		err := Create(cl, username, hostOperatorNamespace, memberOperatorNamespace)
+		err := Create(context.TODO(), cl, username, hostOperatorNamespace, "member-abcd")
+
+		// then
+		require.NoError(t, err)
+		usersignup := &toolchainv1alpha1.UserSignup{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: username}, usersignup))
+		assert.Equal(t, "member-abcd", usersignup.Spec.TargetCluster)
+		assert.True(t, states.ApprovedManually(usersignup))
+	})
+
+	t.Run("without target cluster", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t)
+		username := "user-0002"
+
+		// when
+		err := Create(context.TODO(), cl, username, hostOperatorNamespace, "")
 
 		// then
 		require.NoError(t, err)
+		usersignup := &toolchainv1alpha1.UserSignup{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: username}, usersignup))
+		assert.Empty(t, usersignup.Spec.TargetCluster)
+	})
+
+	t.Run("without space", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t)
+		username := "user-0003"
+
+		// when
+		err := CreateWithoutSpace(context.TODO(), cl, username, hostOperatorNamespace, "member-abcd")
 
+		// then
+		require.NoError(t, err)
+		usersignup := &toolchainv1alpha1.UserSignup{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: username}, usersignup))
+		assert.Equal(t, "true", usersignup.Annotations[toolchainv1alpha1.SkipAutoCreateSpaceAnnotationKey])
+		assert.Equal(t, "member-abcd", usersignup.Spec.TargetCluster)
+		assert.True(t, states.ApprovedManually(usersignup))
 	})
+}
+
+func TestApprove(t *testing.T) {
+	// given
+	configuration.DefaultTimeout = time.Second * 2
+	hostOperatorNamespace := "toolchain-host-operator"
 
-	t.Run("failures", func(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, &toolchainv1alpha1.UserSignup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hostOperatorNamespace, Name: "user-0001"},
+		})
 
-		t.Run("missing ToolchainCluster resource for member cluster", func(t *testing.T) {
-			// given
-			configuration.DefaultTimeout = time.Second * 5
-			cl := commontest.NewFakeClient(t) // no ToolchainCluster
-			username := "user-0001"
-			hostOperatorNamespace := "toolchain-host-operator"
-			memberOperatorNamespace := "toolchain-member-operator"
+		// when
+		err := Approve(context.TODO(), cl, "user-0001", hostOperatorNamespace, "member-abcd")
 
-			// when
-			err := Create(cl, username, hostOperatorNamespace, memberOperatorNamespace)
+		// then
+		require.NoError(t, err)
+		usersignup := &toolchainv1alpha1.UserSignup{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: "user-0001"}, usersignup))
+		assert.True(t, states.ApprovedManually(usersignup))
+		assert.Equal(t, "member-abcd", usersignup.Spec.TargetCluster)
+	})
 
-			// then
-			require.Error(t, err)
-			assert.EqualError(t, err, "unable to lookup member cluster name, ensure the sandbox setup steps are followed")
+	t.Run("without space", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, &toolchainv1alpha1.UserSignup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: hostOperatorNamespace, Name: "user-0002"},
 		})
+
+		// when
+		err := ApproveWithoutSpace(context.TODO(), cl, "user-0002", hostOperatorNamespace, "")
+
+		// then
+		require.NoError(t, err)
+		usersignup := &toolchainv1alpha1.UserSignup{}
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: "user-0002"}, usersignup))
+		assert.True(t, states.ApprovedManually(usersignup))
+		assert.Equal(t, "true", usersignup.Annotations[toolchainv1alpha1.SkipAutoCreateSpaceAnnotationKey])
+		assert.Empty(t, usersignup.Spec.TargetCluster)
+	})
+
+	t.Run("usersignup not created", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t)
+
+		// when
+		err := Approve(context.TODO(), cl, "user-0001", hostOperatorNamespace, "")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to approve usersignup 'user-0001'")
 	})
 }
@@ -0,0 +1,81 @@
+package users
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListWithPrefix returns the sorted names of the UserSignups created by the setup with the given username prefix
+func ListWithPrefix(cl client.Client, prefix, hostOperatorNamespace string) ([]string, error) {
+	signups := &toolchainv1alpha1.UserSignupList{}
+	if err := cl.List(context.TODO(), signups, client.InNamespace(hostOperatorNamespace)); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, signup := range signups.Items {
+		if strings.HasPrefix(signup.Name, prefix+"-") {
+			names = append(names, signup.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Namespaces returns the names of the namespaces provisioned for the given user's space
+func Namespaces(cl client.Client, username string) ([]string, error) {
+	namespaces := &corev1.NamespaceList{}
+	if err := cl.List(context.TODO(), namespaces, client.MatchingLabels{
+		toolchainv1alpha1.SpaceLabelKey: username,
+	}); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		names = append(names, ns.Name)
+	}
+	return names, nil
+}
+
+// Delete deletes the UserSignup of the given user, a UserSignup that is already gone is not an error. It gives up when the
+// context is done.
+func Delete(ctx context.Context, cl client.Client, username, hostOperatorNamespace string) error {
+	propagationPolicy := metav1.DeletePropagationForeground
+	err := cl.Delete(ctx, &toolchainv1alpha1.UserSignup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hostOperatorNamespace,
+			Name:      username,
+		},
+	}, &client.DeleteOptions{PropagationPolicy: &propagationPolicy})
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// DeleteBanned deletes the BannedUsers created by the setup for the users with the given username prefix, it returns the number
+// of deleted BannedUsers
+func DeleteBanned(cl client.Client, prefix, hostOperatorNamespace string) (int, error) {
+	banned := &toolchainv1alpha1.BannedUserList{}
+	if err := cl.List(context.TODO(), banned, client.InNamespace(hostOperatorNamespace)); err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for i := range banned.Items {
+		if !strings.HasPrefix(banned.Items[i].Name, prefix+"-") {
+			continue
+		}
+		if err := cl.Delete(context.TODO(), &banned.Items[i]); err != nil && !k8serrors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
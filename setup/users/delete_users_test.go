@@ -0,0 +1,129 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestListWithPrefix(t *testing.T) {
+	// given
+	hostOperatorNamespace := "toolchain-host-operator"
+	cl := commontest.NewFakeClient(t,
+		userSignup(hostOperatorNamespace, "zippy-0002"),
+		userSignup(hostOperatorNamespace, "zippy-0001"),
+		userSignup(hostOperatorNamespace, "zippyzorro-0001"),
+		userSignup(hostOperatorNamespace, "cupcake-0001"),
+		userSignup("other-namespace", "zippy-0003"),
+	)
+
+	// when
+	names, err := ListWithPrefix(cl, "zippy", hostOperatorNamespace)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zippy-0001", "zippy-0002"}, names)
+}
+
+func TestNamespaces(t *testing.T) {
+	// given
+	cl := commontest.NewFakeClient(t,
+		namespace("zippy-0001-dev", "zippy-0001"),
+		namespace("zippy-0001-stage", "zippy-0001"),
+		namespace("zippy-0002-dev", "zippy-0002"),
+	)
+
+	// when
+	names, err := Namespaces(cl, "zippy-0001")
+
+	// then
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"zippy-0001-dev", "zippy-0001-stage"}, names)
+}
+
+func TestDelete(t *testing.T) {
+	// given
+	hostOperatorNamespace := "toolchain-host-operator"
+	cl := commontest.NewFakeClient(t, userSignup(hostOperatorNamespace, "zippy-0001"))
+
+	t.Run("existing usersignup", func(t *testing.T) {
+		// when
+		err := Delete(context.TODO(), cl, "zippy-0001", hostOperatorNamespace)
+
+		// then
+		require.NoError(t, err)
+		err = cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: "zippy-0001"}, &toolchainv1alpha1.UserSignup{})
+		assert.True(t, errors.IsNotFound(err))
+	})
+
+	t.Run("usersignup already deleted", func(t *testing.T) {
+		// when
+		err := Delete(context.TODO(), cl, "zippy-0001", hostOperatorNamespace)
+
+		// then
+		require.NoError(t, err)
+	})
+}
+
+func TestDeleteBanned(t *testing.T) {
+	// given
+	hostOperatorNamespace := "toolchain-host-operator"
+	cl := commontest.NewFakeClient(t,
+		bannedUser(hostOperatorNamespace, "zippy-0001"),
+		bannedUser(hostOperatorNamespace, "zippy-churn-0002"),
+		bannedUser(hostOperatorNamespace, "zippyzorro-0001"),
+		bannedUser(hostOperatorNamespace, "spammer"),
+	)
+
+	// when
+	deleted, err := DeleteBanned(cl, "zippy", hostOperatorNamespace)
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	remaining := &toolchainv1alpha1.BannedUserList{}
+	require.NoError(t, cl.List(context.TODO(), remaining))
+	names := []string{}
+	for _, b := range remaining.Items {
+		names = append(names, b.Name)
+	}
+	assert.ElementsMatch(t, []string{"zippyzorro-0001", "spammer"}, names)
+}
+
+func userSignup(namespace, name string) *toolchainv1alpha1.UserSignup {
+	return &toolchainv1alpha1.UserSignup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+}
+
+func namespace(name, space string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				toolchainv1alpha1.SpaceLabelKey: space,
+			},
+		},
+	}
+}
+
+func bannedUser(namespace, name string) *toolchainv1alpha1.BannedUser {
+	return &toolchainv1alpha1.BannedUser{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+	}
+}
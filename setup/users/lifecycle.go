@@ -0,0 +1,76 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
+	"github.com/codeready-toolchain/toolchain-common/pkg/states"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+
+	"github.com/pkg/errors"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8swait "k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Deactivate sets the deactivated state on the UserSignup of the given user, the host operator then deprovisions the user
+func Deactivate(ctx context.Context, cl client.Client, username, hostOperatorNamespace string) error {
+	err := update(ctx, cl, username, hostOperatorNamespace, func(usersignup *toolchainv1alpha1.UserSignup) {
+		states.SetDeactivated(usersignup, true)
+	})
+	return errors.Wrapf(err, "unable to deactivate usersignup '%s'", username)
+}
+
+// Reactivate approves the deactivated UserSignup of the given user again, the host operator then provisions the user again
+func Reactivate(ctx context.Context, cl client.Client, username, hostOperatorNamespace string) error {
+	err := update(ctx, cl, username, hostOperatorNamespace, func(usersignup *toolchainv1alpha1.UserSignup) {
+		// the approval removes the deactivated state
+		states.SetApprovedManually(usersignup, true)
+	})
+	return errors.Wrapf(err, "unable to reactivate usersignup '%s'", username)
+}
+
+// Ban creates a BannedUser with the email of the given user, the host operator then bans the user's UserSignup.
+// The BannedUser is named after the user so that it can be found by the username prefix.
+func Ban(ctx context.Context, cl client.Client, username, hostOperatorNamespace string) error {
+	email := fmt.Sprintf("%s@test.com", username)
+	banned := &toolchainv1alpha1.BannedUser{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: hostOperatorNamespace,
+			Name:      username,
+			Labels: map[string]string{
+				toolchainv1alpha1.BannedUserEmailHashLabelKey: hash.EncodeString(email),
+			},
+		},
+		Spec: toolchainv1alpha1.BannedUserSpec{
+			Email: email,
+		},
+	}
+	return errors.Wrapf(cl.Create(ctx, banned), "unable to ban user '%s'", username)
+}
+
+// update applies the given change to the UserSignup of the given user. It waits until the UserSignup exists, and reads it
+// again when it was updated by the host operator in the meantime, until the context is done.
+func update(ctx context.Context, cl client.Client, username, hostOperatorNamespace string, change func(*toolchainv1alpha1.UserSignup)) error {
+	return k8swait.PollWithContext(ctx, configuration.DefaultRetryInterval, configuration.DefaultTimeout, func(ctx context.Context) (bool, error) {
+		usersignup := &toolchainv1alpha1.UserSignup{}
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: hostOperatorNamespace, Name: username}, usersignup); err != nil {
+			if k8serrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		change(usersignup)
+		if err := cl.Update(ctx, usersignup); err != nil {
+			if k8serrors.IsConflict(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+}
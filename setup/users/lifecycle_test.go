@@ -0,0 +1,117 @@
+package users
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	toolchainv1alpha1 "github.com/codeready-toolchain/api/api/v1alpha1"
+	"github.com/codeready-toolchain/toolchain-common/pkg/hash"
+	"github.com/codeready-toolchain/toolchain-common/pkg/states"
+	commontest "github.com/codeready-toolchain/toolchain-common/pkg/test"
+	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDeactivate(t *testing.T) {
+	// given
+	configuration.DefaultTimeout = time.Second * 2
+	hostOperatorNamespace := "toolchain-host-operator"
+
+	t.Run("success", func(t *testing.T) {
+		// given
+		usersignup := userSignup(hostOperatorNamespace, "zippy-0001")
+		states.SetApprovedManually(usersignup, true)
+		cl := commontest.NewFakeClient(t, usersignup)
+
+		// when
+		err := Deactivate(context.TODO(), cl, "zippy-0001", hostOperatorNamespace)
+
+		// then
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: "zippy-0001"}, usersignup))
+		assert.True(t, states.Deactivated(usersignup))
+		assert.False(t, states.ApprovedManually(usersignup))
+	})
+
+	t.Run("retried on conflict", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t, userSignup(hostOperatorNamespace, "zippy-0001"))
+		conflicts := 0
+		cl.MockUpdate = func(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+			if conflicts < 2 {
+				conflicts++
+				return k8serrors.NewConflict(schema.GroupResource{Resource: "usersignups"}, obj.GetName(), nil)
+			}
+			return cl.Client.Update(ctx, obj, opts...)
+		}
+
+		// when
+		err := Deactivate(context.TODO(), cl, "zippy-0001", hostOperatorNamespace)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, conflicts)
+	})
+
+	t.Run("usersignup not found", func(t *testing.T) {
+		// given
+		cl := commontest.NewFakeClient(t)
+
+		// when
+		err := Deactivate(context.TODO(), cl, "zippy-0001", hostOperatorNamespace)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to deactivate usersignup 'zippy-0001'")
+	})
+}
+
+func TestReactivate(t *testing.T) {
+	// given
+	hostOperatorNamespace := "toolchain-host-operator"
+	usersignup := userSignup(hostOperatorNamespace, "zippy-0001")
+	states.SetDeactivated(usersignup, true)
+	cl := commontest.NewFakeClient(t, usersignup)
+
+	// when
+	err := Reactivate(context.TODO(), cl, "zippy-0001", hostOperatorNamespace)
+
+	// then
+	require.NoError(t, err)
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: "zippy-0001"}, usersignup))
+	assert.False(t, states.Deactivated(usersignup))
+	assert.True(t, states.ApprovedManually(usersignup))
+}
+
+func TestBan(t *testing.T) {
+	// given
+	hostOperatorNamespace := "toolchain-host-operator"
+	cl := commontest.NewFakeClient(t)
+
+	// when
+	err := Ban(context.TODO(), cl, "zippy-0001", hostOperatorNamespace)
+
+	// then
+	require.NoError(t, err)
+	banned := &toolchainv1alpha1.BannedUser{}
+	require.NoError(t, cl.Get(context.TODO(), types.NamespacedName{Namespace: hostOperatorNamespace, Name: "zippy-0001"}, banned))
+	assert.Equal(t, "zippy-0001@test.com", banned.Spec.Email)
+	// the host operator matches the BannedUser with the UserSignup by the hash of the email
+	assert.Equal(t, hash.EncodeString("zippy-0001@test.com"), banned.Labels[toolchainv1alpha1.BannedUserEmailHashLabelKey])
+
+	t.Run("already banned", func(t *testing.T) {
+		// when
+		err := Ban(context.TODO(), cl, "zippy-0001", hostOperatorNamespace)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to ban user 'zippy-0001'")
+	})
+}
@@ -2,8 +2,11 @@ package wait
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/codeready-toolchain/toolchain-common/pkg/condition"
 	"github.com/codeready-toolchain/toolchain-common/pkg/test"
 	"github.com/codeready-toolchain/toolchain-e2e/setup/configuration"
 
@@ -17,7 +20,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func ForSpace(cl client.Client, space string) error {
+// ForSpace waits until the Space with the given name is provisioned, or until the context is done
+func ForSpace(ctx context.Context, cl client.Client, space string) error {
+	if err := k8swait.PollWithContext(ctx, configuration.DefaultRetryInterval, configuration.DefaultTimeout, func(context.Context) (bool, error) {
+		return HasSpaceReady(cl, space)
+	}); err != nil {
+		return errors.Wrapf(err, "space '%s' is not ready yet", space)
+	}
+	return nil
+}
+
+// HasSpaceReady returns true if the Space with the given name exists and is provisioned
+func HasSpaceReady(cl client.Client, space string) (bool, error) {
 	sp := &toolchainv1alpha1.Space{}
 	expectedConditions := []toolchainv1alpha1.Condition{
 		{
@@ -26,22 +40,74 @@ func ForSpace(cl client.Client, space string) error {
 			Reason: "Provisioned",
 		},
 	}
+	err := cl.Get(context.TODO(), types.NamespacedName{
+		Name:      space,
+		Namespace: configuration.HostOperatorNamespace,
+	}, sp)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return test.ConditionsMatch(sp.Status.Conditions, expectedConditions...), nil
+}
+
+// ForUserSignupApproved waits until the host operator has approved the UserSignup with the given name, or until the context is done
+func ForUserSignupApproved(ctx context.Context, cl client.Client, name string) error {
+	if err := k8swait.PollWithContext(ctx, configuration.DefaultRetryInterval, configuration.DefaultTimeout, func(context.Context) (bool, error) {
+		return HasUserSignupApproved(cl, name)
+	}); err != nil {
+		return errors.Wrapf(err, "usersignup '%s' is not approved yet", name)
+	}
+	return nil
+}
+
+// HasUserSignupApproved returns true if the UserSignup with the given name exists and has the Approved condition set to true
+func HasUserSignupApproved(cl client.Client, name string) (bool, error) {
+	signup := &toolchainv1alpha1.UserSignup{}
+	err := cl.Get(context.TODO(), types.NamespacedName{
+		Name:      name,
+		Namespace: configuration.HostOperatorNamespace,
+	}, signup)
+	if k8serrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return condition.IsTrue(signup.Status.Conditions, toolchainv1alpha1.UserSignupApproved), nil
+}
+
+// ForSpaceDeleted waits until the Space with the given name no longer exists
+func ForSpaceDeleted(cl client.Client, space string) error {
+	return forDeletion(cl, &toolchainv1alpha1.Space{}, types.NamespacedName{Namespace: configuration.HostOperatorNamespace, Name: space})
+}
+
+// ForNSTemplateSetDeleted waits until the NSTemplateSet with the given name no longer exists in any of the given namespaces of
+// the member operators
+func ForNSTemplateSetDeleted(cl client.Client, memberOperatorNamespaces []string, name string) error {
+	for _, ns := range memberOperatorNamespaces {
+		if err := forDeletion(cl, &toolchainv1alpha1.NSTemplateSet{}, types.NamespacedName{Namespace: ns, Name: name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// ForNamespaceDeleted waits until the namespace with the given name no longer exists
+func ForNamespaceDeleted(cl client.Client, name string) error {
+	return forDeletion(cl, &corev1.Namespace{}, types.NamespacedName{Name: name})
+}
+
+func forDeletion(cl client.Client, obj client.Object, key types.NamespacedName) error {
+	kind := fmt.Sprintf("%T", obj)
 	if err := k8swait.Poll(configuration.DefaultRetryInterval, configuration.DefaultTimeout, func() (bool, error) {
-		err := cl.Get(context.TODO(), types.NamespacedName{
-			Name:      space,
-			Namespace: configuration.HostOperatorNamespace,
-		}, sp)
+		err := cl.Get(context.TODO(), key, obj)
 		if k8serrors.IsNotFound(err) {
-			return false, nil
-		} else if err != nil {
-			return false, err
-		} else if !test.ConditionsMatch(sp.Status.Conditions, expectedConditions...) {
-			return false, nil
+			return true, nil
 		}
-		return true, nil
+		return false, err
 	}); err != nil {
-		return errors.Wrapf(err, "space '%s' is not ready yet", space)
+		return errors.Wrapf(err, "%s '%s' was not deleted", kind[strings.LastIndex(kind, ".")+1:], key.Name)
 	}
 	return nil
 }
@@ -15,6 +15,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -31,7 +32,7 @@ func TestForNamespace(t *testing.T) {
 		cl := test.NewFakeClient(t, ns) // space exists
 
 		// when
-		err := wait.ForSpace(cl, "user0001")
+		err := wait.ForSpace(context.TODO(), cl, "user0001")
 
 		// then
 		require.NoError(t, err)
@@ -45,13 +46,189 @@ func TestForNamespace(t *testing.T) {
 			cl := test.NewFakeClient(t) // ns doesn't exist
 
 			// when
-			err := wait.ForSpace(cl, "user0001")
+			err := wait.ForSpace(context.TODO(), cl, "user0001")
 
 			// then
 			require.Error(t, err)
 			assert.EqualError(t, err, "space 'user0001' does not exist: timed out waiting for the condition")
 		})
 
+		t.Run("context done", func(t *testing.T) {
+			// given
+			timeout := configuration.DefaultTimeout
+			t.Cleanup(func() {
+				configuration.DefaultTimeout = timeout
+			})
+			configuration.DefaultTimeout = time.Minute
+			cl := test.NewFakeClient(t) // ns doesn't exist
+			ctx, cancel := context.WithCancel(context.TODO())
+			cancel()
+			start := time.Now()
+
+			// when
+			err := wait.ForSpace(ctx, cl, "user0001")
+
+			// then
+			require.Error(t, err)
+			assert.Less(t, time.Since(start), 10*time.Second)
+		})
+
+	})
+}
+
+func TestForNSTemplateSetDeleted(t *testing.T) {
+	// given
+	timeout := configuration.DefaultTimeout
+	t.Cleanup(func() {
+		configuration.DefaultTimeout = timeout
+	})
+	configuration.DefaultTimeout = 100 * time.Millisecond
+	memberOperatorNamespaces := []string{"toolchain-member-operator", "toolchain-member2-operator"}
+
+	t.Run("deleted", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+
+		// when
+		err := wait.ForNSTemplateSetDeleted(cl, memberOperatorNamespaces, "user0001")
+
+		// then
+		require.NoError(t, err)
+	})
+
+	t.Run("not deleted on another member cluster", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t, &toolchainv1alpha1.NSTemplateSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "toolchain-member2-operator", Name: "user0001"},
+		})
+
+		// when
+		err := wait.ForNSTemplateSetDeleted(cl, memberOperatorNamespaces, "user0001")
+
+		// then
+		require.EqualError(t, err, "NSTemplateSet 'user0001' was not deleted: timed out waiting for the condition")
+	})
+}
+
+func TestHasSpaceReady(t *testing.T) {
+	configuration.HostOperatorNamespace = "toolchain-host-operator"
+
+	t.Run("ready", func(t *testing.T) {
+		// given
+		sp := &toolchainv1alpha1.Space{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "user0001",
+				Namespace: configuration.HostOperatorNamespace,
+			},
+			Status: toolchainv1alpha1.SpaceStatus{
+				Conditions: []toolchainv1alpha1.Condition{
+					{
+						Type:   toolchainv1alpha1.ConditionReady,
+						Status: corev1.ConditionTrue,
+						Reason: "Provisioned",
+					},
+				},
+			},
+		}
+		cl := test.NewFakeClient(t, sp)
+
+		// when
+		ready, err := wait.HasSpaceReady(cl, "user0001")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, ready)
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		// given
+		sp := &toolchainv1alpha1.Space{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "user0001",
+				Namespace: configuration.HostOperatorNamespace,
+			},
+		}
+		cl := test.NewFakeClient(t, sp)
+
+		// when
+		ready, err := wait.HasSpaceReady(cl, "user0001")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, ready)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+
+		// when
+		ready, err := wait.HasSpaceReady(cl, "user0001")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, ready)
+	})
+}
+
+func TestHasUserSignupApproved(t *testing.T) {
+	configuration.HostOperatorNamespace = "toolchain-host-operator"
+
+	t.Run("approved", func(t *testing.T) {
+		// given
+		signup := &toolchainv1alpha1.UserSignup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "user0001",
+				Namespace: configuration.HostOperatorNamespace,
+			},
+			Status: toolchainv1alpha1.UserSignupStatus{
+				Conditions: []toolchainv1alpha1.Condition{
+					{
+						Type:   toolchainv1alpha1.UserSignupApproved,
+						Status: corev1.ConditionTrue,
+						Reason: "ApprovedByAdmin",
+					},
+				},
+			},
+		}
+		cl := test.NewFakeClient(t, signup)
+
+		// when
+		approved, err := wait.HasUserSignupApproved(cl, "user0001")
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, approved)
+	})
+
+	t.Run("not approved yet", func(t *testing.T) {
+		// given
+		signup := &toolchainv1alpha1.UserSignup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "user0001",
+				Namespace: configuration.HostOperatorNamespace,
+			},
+		}
+		cl := test.NewFakeClient(t, signup)
+
+		// when
+		approved, err := wait.HasUserSignupApproved(cl, "user0001")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, approved)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		// given
+		cl := test.NewFakeClient(t)
+
+		// when
+		approved, err := wait.HasUserSignupApproved(cl, "user0001")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, approved)
 	})
 }
 
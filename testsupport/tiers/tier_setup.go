@@ -130,21 +130,27 @@ func duplicateTierTemplate(t *testing.T, hostAwait *HostAwaitility, namespace, t
 	if err := hostAwait.Client.Get(context.TODO(), test.NamespacedName(hostAwait.Namespace, origTemplateRef), origTierTemplate); err != nil {
 		return "", err
 	}
+	newTierTemplate := CopyTierTemplate(origTierTemplate, namespace, fmt.Sprintf("%sfrom%s", tierName, origTierTemplate.Name), tierName)
+	if err := hostAwait.CreateWithCleanup(t, newTierTemplate); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return "", err
+		}
+	}
+	return newTierTemplate.Name, nil
+}
+
+// CopyTierTemplate returns a copy of the given TierTemplate with the given name, for the given tier
+func CopyTierTemplate(origTierTemplate *toolchainv1alpha1.TierTemplate, namespace, name, tierName string) *toolchainv1alpha1.TierTemplate {
 	newTierTemplate := &toolchainv1alpha1.TierTemplate{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: namespace,
-			Name:      fmt.Sprintf("%sfrom%s", tierName, origTierTemplate.Name),
+			Name:      name,
 			Labels:    map[string]string{"producer": "toolchain-e2e"},
 		},
 		Spec: origTierTemplate.Spec,
 	}
 	newTierTemplate.Spec.TierName = tierName
-	if err := hostAwait.CreateWithCleanup(t, newTierTemplate); err != nil {
-		if !errors.IsAlreadyExists(err) {
-			return "", err
-		}
-	}
-	return newTierTemplate.Name, nil
+	return newTierTemplate
 }
 
 func MoveSpaceToTier(t *testing.T, hostAwait *HostAwaitility, spacename, tierName string) {